@@ -0,0 +1,142 @@
+// This file provides a means of allocating a fixed budget of reads across a
+// batch of problems according to how quickly each problem converges.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"sort"
+)
+
+// An AdaptiveAllocator solves a batch of problems in rounds, drawing from a
+// fixed budget of reads.  After each round it computes each problem's
+// ground-state hit count, the number of reads that returned the lowest energy
+// found so far for that problem.  A problem whose hit count reaches
+// TargetHits is considered converged and receives no further reads; the
+// remaining budget is divided evenly among the problems that have not yet
+// converged.
+type AdaptiveAllocator struct {
+	Sampler    Sampler // Underlying sampler
+	TotalReads int     // Total number of reads to allocate across the batch
+	RoundReads int     // Number of reads to allocate to each unconverged problem per round
+	TargetHits int     // Number of ground-state hits at which a problem is considered converged
+	Tolerance  float64 // Maximum energy difference at which two energies are considered equal
+}
+
+// NewAdaptiveAllocator wraps a sampler with an AdaptiveAllocator.  By
+// default, each round allocates 100 reads to each unconverged problem, and a
+// problem is considered converged after 10 ground-state hits.
+func NewAdaptiveAllocator(s Sampler, totalReads int) *AdaptiveAllocator {
+	return &AdaptiveAllocator{
+		Sampler:    s,
+		TotalReads: totalReads,
+		RoundReads: 100,
+		TargetHits: 10,
+		Tolerance:  1e-6,
+	}
+}
+
+// An AdaptiveResult is the result of solving one problem of a batch with an
+// AdaptiveAllocator.  The embedded IsingResult merges the solutions from all
+// rounds in order of increasing energy; its Timing is that of the most recent
+// round.
+type AdaptiveResult struct {
+	IsingResult
+	Reads     int  // Number of reads allocated to the problem
+	Hits      int  // Number of reads that returned the lowest energy found
+	Converged bool // true if Hits reached the allocator's TargetHits
+}
+
+// addRound merges the result of one round of solving into an AdaptiveResult
+// and updates its hit count.
+func (ar *AdaptiveResult) addRound(res IsingResult, reads int, tol float64) {
+	ar.Reads += reads
+	ar.Timing = res.Timing
+	for i, soln := range res.Solutions {
+		// Determine how many reads this solution represents.
+		n := res.NumOccurrences(i)
+		e := res.Energies[i]
+
+		// Update the ground-state hit count, resetting it if we found
+		// a lower energy than any seen previously.
+		switch {
+		case len(ar.Energies) == 0 || e < ar.Energies[0]-tol:
+			ar.Hits = n
+		case e <= ar.Energies[0]+tol:
+			ar.Hits += n
+		}
+
+		// Insert the solution in order of increasing energy.
+		pos := sort.Search(len(ar.Energies), func(j int) bool { return ar.Energies[j] > e })
+		ar.Energies = append(ar.Energies, 0)
+		copy(ar.Energies[pos+1:], ar.Energies[pos:])
+		ar.Energies[pos] = e
+		ar.Solutions = append(ar.Solutions, nil)
+		copy(ar.Solutions[pos+1:], ar.Solutions[pos:])
+		ar.Solutions[pos] = soln
+		ar.Occurrences = append(ar.Occurrences, 0)
+		copy(ar.Occurrences[pos+1:], ar.Occurrences[pos:])
+		ar.Occurrences[pos] = n
+	}
+}
+
+// solve is the common code for SolveIsing and SolveQubo.
+func (aa *AdaptiveAllocator) solve(solve func(Problem, SolverParameters) (IsingResult, error),
+	probs []Problem, sp SolverParameters) ([]AdaptiveResult, error) {
+	if aa.RoundReads < 1 {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "RoundReads must be positive, not %d", aa.RoundReads)
+	}
+	results := make([]AdaptiveResult, len(probs))
+	remaining := aa.TotalReads
+	for remaining > 0 {
+		// Determine which problems have not yet converged.
+		active := make([]int, 0, len(probs))
+		for i := range results {
+			if !results[i].Converged {
+				active = append(active, i)
+			}
+		}
+		if len(active) == 0 {
+			break
+		}
+
+		// Divide this round's reads among the unconverged problems.
+		nr := aa.RoundReads
+		if nr*len(active) > remaining {
+			nr = remaining / len(active)
+			if nr == 0 {
+				nr = 1
+				active = active[:remaining]
+			}
+		}
+
+		// Solve each unconverged problem and merge in its solutions.
+		for _, i := range active {
+			res, err := solve(probs[i], withNumReads(sp, nr))
+			if err != nil {
+				return nil, err
+			}
+			remaining -= nr
+			ar := &results[i]
+			ar.addRound(res, nr, aa.Tolerance)
+			ar.Converged = ar.Hits >= aa.TargetHits
+		}
+	}
+	return results, nil
+}
+
+// SolveIsing solves a batch of Ising-model problems, allocating reads
+// adaptively.  Results are returned in the same order as the problems.
+func (aa *AdaptiveAllocator) SolveIsing(probs []Problem, sp SolverParameters) ([]AdaptiveResult, error) {
+	return aa.solve(aa.Sampler.SolveIsing, probs, sp)
+}
+
+// SolveQubo is the QUBO analogue of SolveIsing.
+func (aa *AdaptiveAllocator) SolveQubo(probs []Problem, sp SolverParameters) ([]AdaptiveResult, error) {
+	return aa.solve(aa.Sampler.SolveQubo, probs, sp)
+}
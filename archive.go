@@ -0,0 +1,126 @@
+// This file provides a means of bundling a problem, its solver
+// configuration, and its results into a single self-describing file.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ArchiveFormat identifies the version of the archive format written by
+// Archive.Write.
+const ArchiveFormat = "sapi-archive/1"
+
+// An Archive bundles everything needed to reproduce a solver run: the problem,
+// the solver parameters, the embedding (if any), a snapshot of the solver's
+// properties, and the result, including its timing breakdown.
+type Archive struct {
+	Created     time.Time         // Time at which the archive was created
+	SAPIVersion string            // Version of the SAPI library that produced the result
+	Solver      string            // Name of the solver that produced the result
+	ProblemType string            // "ising" or "qubo"
+	Problem     Problem           // Problem that was solved
+	Parameters  SolverParameters  // Parameters passed to the solver
+	Embedding   Embeddings        // Embedding of Problem in the hardware or nil if none was used
+	Properties  *SolverProperties // Solver's properties at the time of the run
+	Result      IsingResult       // Solver output
+}
+
+// NewArchive returns an Archive that records a run of a given solver.
+// Pass nil for emb if the problem was not embedded.
+func NewArchive(s *Solver, ptype string, p Problem, sp SolverParameters, emb Embeddings, ir IsingResult) *Archive {
+	return &Archive{
+		Created:     time.Now(),
+		SAPIVersion: Version(),
+		Solver:      s.Name,
+		ProblemType: ptype,
+		Problem:     p,
+		Parameters:  sp,
+		Embedding:   emb,
+		Properties:  s.Properties(),
+		Result:      ir,
+	}
+}
+
+// archiveJSON is the on-disk representation of an Archive.
+type archiveJSON struct {
+	Format      string
+	Created     time.Time
+	SAPIVersion string
+	Solver      string
+	ProblemType string
+	Problem     Problem
+	Parameters  json.RawMessage `json:",omitempty"`
+	Embedding   Embeddings      `json:",omitempty"`
+	Properties  *SolverProperties
+	Result      IsingResult
+}
+
+// Write writes an Archive to a stream in JSON format.
+func (a *Archive) Write(w io.Writer) error {
+	// Encode the solver parameters separately so we can record their type.
+	// This fails if the parameters' type is unrecognized.
+	aj := archiveJSON{
+		Format:      ArchiveFormat,
+		Created:     a.Created,
+		SAPIVersion: a.SAPIVersion,
+		Solver:      a.Solver,
+		ProblemType: a.ProblemType,
+		Problem:     a.Problem,
+		Embedding:   a.Embedding,
+		Properties:  a.Properties,
+		Result:      a.Result,
+	}
+	if a.Parameters != nil {
+		var err error
+		aj.Parameters, err = MarshalSolverParameters(a.Parameters)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Write the archive.
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(aj)
+}
+
+// ReadArchive reads an Archive, as produced by Archive.Write, from a stream.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	// Decode the archive as a whole.
+	var aj archiveJSON
+	if err := json.NewDecoder(r).Decode(&aj); err != nil {
+		return nil, err
+	}
+	if aj.Format != ArchiveFormat {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unsupported archive format %q", aj.Format)
+	}
+	a := &Archive{
+		Created:     aj.Created,
+		SAPIVersion: aj.SAPIVersion,
+		Solver:      aj.Solver,
+		ProblemType: aj.ProblemType,
+		Problem:     aj.Problem,
+		Embedding:   aj.Embedding,
+		Properties:  aj.Properties,
+		Result:      aj.Result,
+	}
+
+	// Decode the solver parameters into a value of the recorded type.
+	if len(aj.Parameters) > 0 {
+		sp, err := UnmarshalSolverParameters(aj.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		a.Parameters = sp
+	}
+	return a, nil
+}
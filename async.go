@@ -12,31 +12,54 @@ import "C"
 
 import (
 	"runtime"
+	"sync"
 	"time"
 )
 
 // A SubmittedProblem represents a problem submitted asynchronously to a solver.
 type SubmittedProblem struct {
-	cSp *C.sapi_SubmittedProblem
+	cSp      *C.sapi_SubmittedProblem
+	solver   *Solver        // Solver to which the problem was submitted
+	mu       sync.Mutex     // Protects the following fields
+	state    SubmittedState // Most recently observed state
+	done     bool           // true once completion has been reported
+	deadline time.Time      // Time by which the problem must complete (zero = no limit)
+}
+
+// newSubmittedProblem wraps a C sapi_SubmittedProblem, arranging for it to be
+// freed when garbage-collected and recording its deadline.
+func (s *Solver) newSubmittedProblem(cSub *C.sapi_SubmittedProblem) *SubmittedProblem {
+	sub := &SubmittedProblem{cSp: cSub, solver: s, state: StateSubmitting}
+	if s.Timeout > 0 {
+		sub.deadline = time.Now().Add(s.Timeout)
+	}
+
+	// Free the problem when it gets GC'd away.
+	runtime.SetFinalizer(sub, func(sub *SubmittedProblem) {
+		C.sapi_freeSubmittedProblem(sub.cSp)
+	})
+	return sub
+}
+
+// expired says whether a problem has passed its deadline.
+func (sp *SubmittedProblem) expired() bool {
+	return !sp.deadline.IsZero() && time.Now().After(sp.deadline)
 }
 
 // AsyncSolveIsing submits an Ising-model problem to a solver but does not wait
 // for it to complete.
 func (s *Solver) AsyncSolveIsing(p Problem, sp SolverParameters) (*SubmittedProblem, error) {
 	// Submit the problem.
-	prob := p.toC()
+	prob := s.Precision.Apply(p).toC()
 	params := sp.ToCSolverParameters()
+	defer freeCSolverParameters(sp, params)
 	var cSub *C.sapi_SubmittedProblem
 	cErr := make([]C.char, C.SAPI_ERROR_MESSAGE_MAX_SIZE)
 	if ret := C.sapi_asyncSolveIsing(s.solver, prob, params, &cSub, &cErr[0]); ret != C.SAPI_OK {
 		return nil, newErrorf(ret, "%s", C.GoString(&cErr[0]))
 	}
-	sub := &SubmittedProblem{cSp: cSub}
-
-	// Free the problem when it gets GC'd away.
-	runtime.SetFinalizer(sub, func(sub *SubmittedProblem) {
-		C.sapi_freeSubmittedProblem(sub.cSp)
-	})
+	sub := s.newSubmittedProblem(cSub)
+	s.emit(EventSubmitted, sub, nil)
 	return sub, nil
 }
 
@@ -44,19 +67,16 @@ func (s *Solver) AsyncSolveIsing(p Problem, sp SolverParameters) (*SubmittedProb
 // to complete.
 func (s *Solver) AsyncSolveQubo(p Problem, sp SolverParameters) (*SubmittedProblem, error) {
 	// Submit the problem.
-	prob := p.toC()
+	prob := s.Precision.Apply(p).toC()
 	params := sp.ToCSolverParameters()
+	defer freeCSolverParameters(sp, params)
 	var cSub *C.sapi_SubmittedProblem
 	cErr := make([]C.char, C.SAPI_ERROR_MESSAGE_MAX_SIZE)
 	if ret := C.sapi_asyncSolveQubo(s.solver, prob, params, &cSub, &cErr[0]); ret != C.SAPI_OK {
 		return nil, newErrorf(ret, "%s", C.GoString(&cErr[0]))
 	}
-	sub := &SubmittedProblem{cSp: cSub}
-
-	// Free the problem when it gets GC'd away.
-	runtime.SetFinalizer(sub, func(sub *SubmittedProblem) {
-		C.sapi_freeSubmittedProblem(sub.cSp)
-	})
+	sub := s.newSubmittedProblem(cSub)
+	s.emit(EventSubmitted, sub, nil)
 	return sub, nil
 }
 
@@ -124,17 +144,63 @@ func (sp *SubmittedProblem) Status() (*ProblemStatus, error) {
 	if cPs.error_code != C.SAPI_OK {
 		ps.Error = newErrorf(cPs.error_code, C.GoString(&cPs.error_message[0]))
 	}
+	sp.observe(&ps)
 	return &ps, nil
 }
 
+// observe reports an EventStateChanged if a problem's state differs from the
+// previously observed state.
+func (sp *SubmittedProblem) observe(ps *ProblemStatus) {
+	sp.mu.Lock()
+	changed := ps.State != sp.state
+	sp.state = ps.State
+	sp.mu.Unlock()
+	if changed && sp.solver != nil {
+		sp.solver.Conn.emit(Event{
+			Kind:    EventStateChanged,
+			Solver:  sp.solver,
+			Problem: sp,
+			Status:  ps,
+		})
+	}
+	if ps.State == StateDone {
+		sp.markDone()
+	}
+}
+
+// markDone reports an EventCompleted the first time it is invoked on a
+// completed problem.
+func (sp *SubmittedProblem) markDone() {
+	sp.mu.Lock()
+	already := sp.done
+	sp.done = true
+	sp.mu.Unlock()
+	if already || sp.solver == nil {
+		return
+	}
+	var err error
+	var cPs C.sapi_ProblemStatus
+	if C.sapi_asyncStatus(sp.cSp, &cPs) == C.SAPI_OK && cPs.error_code != C.SAPI_OK {
+		err = newErrorf(cPs.error_code, "%s", C.GoString(&cPs.error_message[0]))
+	}
+	sp.solver.emit(EventCompleted, sp, err)
+}
+
 // Done says whether an asynchronously submitted problem has completed.
 func (sp *SubmittedProblem) Done() bool {
-	return C.sapi_asyncDone(sp.cSp) != 0
+	done := C.sapi_asyncDone(sp.cSp) != 0
+	if done {
+		sp.markDone()
+	}
+	return done
 }
 
 // Cancel cancels an asynchronously submitted problem.
 func (sp *SubmittedProblem) Cancel() {
 	C.sapi_cancelSubmittedProblem(sp.cSp)
+	if sp.solver != nil {
+		sp.solver.emit(EventCanceled, sp, nil)
+	}
 }
 
 // Retry retries an asynchronously submitted problem that encountered a
@@ -149,6 +215,9 @@ func (sp *SubmittedProblem) Retry() {
 func (sp *SubmittedProblem) AwaitCompletion(timeout time.Duration) bool {
 	cTime := C.double(timeout.Seconds())
 	ret := C.sapi_awaitCompletion(&sp.cSp, 1, 1, cTime)
+	if ret != 0 {
+		sp.markDone()
+	}
 	return ret != 0
 }
 
@@ -166,15 +235,31 @@ func AwaitCompletion(sps []*SubmittedProblem, minDone int, timeout time.Duration
 	// Invoke the C function.
 	cTime := C.double(timeout.Seconds())
 	ret := C.sapi_awaitCompletion(&cSps[0], C.size_t(len(sps)), C.size_t(minDone), cTime)
+
+	// Report completion of any problems that are now done.
+	for _, s := range sps {
+		s.Done()
+	}
 	return ret != 0
 }
 
-// Result returns the result of asynchronously submitted problem.
+// Result returns the result of asynchronously submitted problem.  If the
+// solver has a client timeout (see Solver.SetClientTimeout), Result waits for
+// the problem to complete until the timeout expires, at which point it
+// cancels the problem and returns an error.
 func (sp *SubmittedProblem) Result() (IsingResult, error) {
+	if !sp.deadline.IsZero() && !sp.Done() {
+		if wait := time.Until(sp.deadline); wait <= 0 || !sp.AwaitCompletion(wait) {
+			sp.Cancel()
+			return IsingResult{}, sp.solver.timeoutError()
+		}
+	}
 	cErr := make([]C.char, C.SAPI_ERROR_MESSAGE_MAX_SIZE)
 	var result *C.sapi_IsingResult
 	if ret := C.sapi_asyncResult(sp.cSp, &result, &cErr[0]); ret != C.SAPI_OK {
+		sp.markDone()
 		return IsingResult{}, newErrorf(ret, "%s", C.GoString(&cErr[0]))
 	}
-	return convertIsingResultToGo(result)
+	sp.markDone()
+	return sp.solver.recordTiming(convertIsingResultToGo(result))
 }
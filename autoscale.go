@@ -0,0 +1,111 @@
+// This file provides a solver wrapper that scales problem coefficients on the
+// client side.
+
+package sapi
+
+import (
+	"math"
+)
+
+// An AutoScaleComposite wraps a sampler so that every problem's coefficients
+// are scaled to fill the sampler's Ising ranges before the problem is
+// submitted.  This provides the effect of QuantumSolverParameters.AutoScale
+// for software solvers, which do not honor that parameter.  Energies are
+// mapped back to the original problem's frame, and the factor by which
+// coefficients were multiplied is recorded in the result's ScaleFactor.
+// Results from samplers that do not scale report a ScaleFactor of 1, so
+// multiplying any reported energy by ScaleFactor always yields the energy
+// in the frame of the problem the sampler actually solved.
+type AutoScaleComposite struct {
+	Sampler Sampler              // Underlying sampler
+	Ranges  IsingRangeProperties // Ranges to which coefficients are scaled
+}
+
+// NewAutoScaleComposite wraps a sampler with an AutoScaleComposite.  The
+// ranges default to the sampler's Ising ranges or, if it advertises none, to
+// [-1, 1] for both h and J.
+func NewAutoScaleComposite(s Sampler) *AutoScaleComposite {
+	ranges := IsingRangeProperties{HMin: -1, HMax: 1, JMin: -1, JMax: 1}
+	if ir := s.Properties().IsingRanges; ir != nil {
+		ranges = *ir
+	}
+	return &AutoScaleComposite{Sampler: s, Ranges: ranges}
+}
+
+// scaled returns a copy of a problem with every coefficient multiplied by a
+// given factor.
+func (p Problem) scaled(factor float64) Problem {
+	sp := make(Problem, len(p))
+	for i, pe := range p {
+		sp[i] = pe
+		sp[i].Value *= factor
+	}
+	return sp
+}
+
+// ScaleToRanges returns a copy of an Ising-model problem with every
+// coefficient multiplied by the largest factor that keeps all coefficients
+// within a set of Ising ranges.  This is the scaling a quantum solver applies
+// when QuantumSolverParameters.AutoScale is true.  ScaleToRanges also returns
+// the factor; dividing an energy of the scaled problem by the factor yields
+// the corresponding energy of the original problem.
+func (p Problem) ScaleToRanges(r IsingRangeProperties) (Problem, float64) {
+	factor := autoScaleFactor(p.Canonicalize(), &r)
+	return p.scaled(factor), factor
+}
+
+// Normalize returns a copy of a problem with every coefficient divided by
+// the magnitude of the largest coefficient so that coefficients lie in
+// [-1, 1].  Normalize also returns the factor by which coefficients were
+// multiplied.  A problem whose coefficients are all zero is returned with a
+// factor of 1.
+func (p Problem) Normalize() (Problem, float64) {
+	biggest := 0.0
+	for _, pe := range p.Canonicalize() {
+		biggest = math.Max(biggest, math.Abs(pe.Value))
+	}
+	if biggest == 0 {
+		return p.scaled(1), 1
+	}
+	return p.scaled(1 / biggest), 1 / biggest
+}
+
+// solve is the common code for SolveIsing and SolveQubo.  ip is the
+// Ising-model form of p, which determines the scale factor.
+func (as *AutoScaleComposite) solve(solve func(Problem, SolverParameters) (IsingResult, error),
+	p, ip Problem, sp SolverParameters) (IsingResult, error) {
+	factor := autoScaleFactor(ip, &as.Ranges)
+	res, err := solve(p.scaled(factor), sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	for i := range res.Energies {
+		res.Energies[i] /= factor
+	}
+	res.ScaleFactor = factor
+	return res, nil
+}
+
+// SolveIsing scales an Ising-model problem to fill the Ising ranges and
+// solves it.
+func (as *AutoScaleComposite) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	return as.solve(as.Sampler.SolveIsing, p, p, sp)
+}
+
+// SolveQubo scales a QUBO problem so that its Ising-model equivalent fills
+// the Ising ranges and solves it.
+func (as *AutoScaleComposite) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	ip, _ := p.ToIsing()
+	return as.solve(as.Sampler.SolveQubo, p, ip, sp)
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying sampler.
+func (as *AutoScaleComposite) NewSolverParameters() SolverParameters {
+	return as.Sampler.NewSolverParameters()
+}
+
+// Properties returns the underlying sampler's properties.
+func (as *AutoScaleComposite) Properties() *SolverProperties {
+	return as.Sampler.Properties()
+}
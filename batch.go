@@ -0,0 +1,82 @@
+// This file provides a means of solving many problems concurrently.
+
+package sapi
+
+import (
+	"time"
+)
+
+// batchPollInterval is the maximum time to block in SAPI while waiting for
+// one of a batch of problems to complete.
+const batchPollInterval = time.Second
+
+// solveMany is the common code for SolveMany and SolveManyQubo.
+func (s *Solver) solveMany(submit func(Problem, SolverParameters) (*SubmittedProblem, error),
+	probs []Problem, sp SolverParameters, maxInFlight int) ([]IsingResult, error) {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	results := make([]IsingResult, len(probs))
+	inFlight := make([]*SubmittedProblem, 0, maxInFlight)
+	which := make([]int, 0, maxInFlight) // Index into probs of each in-flight problem
+	cancelAll := func() {
+		for _, sub := range inFlight {
+			sub.Cancel()
+		}
+	}
+	next := 0
+	for next < len(probs) || len(inFlight) > 0 {
+		// Submit problems until we reach the concurrency limit.
+		for next < len(probs) && len(inFlight) < maxInFlight {
+			sub, err := submit(probs[next], sp)
+			if err != nil {
+				cancelAll()
+				return nil, err
+			}
+			inFlight = append(inFlight, sub)
+			which = append(which, next)
+			next++
+		}
+
+		// Wait for at least one problem to complete, and collect the
+		// results of all completed problems.
+		AwaitCompletion(inFlight, 1, batchPollInterval)
+		keep := 0
+		for i, sub := range inFlight {
+			if !sub.Done() {
+				if sub.expired() {
+					cancelAll()
+					return nil, s.timeoutError()
+				}
+				inFlight[keep] = sub
+				which[keep] = which[i]
+				keep++
+				continue
+			}
+			res, err := sub.Result()
+			if err != nil {
+				inFlight = append(inFlight[:keep], inFlight[i+1:]...)
+				cancelAll()
+				return nil, err
+			}
+			results[which[i]] = res
+		}
+		inFlight = inFlight[:keep]
+		which = which[:keep]
+	}
+	return results, nil
+}
+
+// SolveMany solves a list of Ising-model problems, submitting them
+// asynchronously with at most maxInFlight problems outstanding at a time.
+// Results are returned in the same order as the problems.  If any problem
+// fails or exceeds the solver's client timeout (see SetClientTimeout), all
+// outstanding problems are canceled and the error is returned.
+func (s *Solver) SolveMany(probs []Problem, sp SolverParameters, maxInFlight int) ([]IsingResult, error) {
+	return s.solveMany(s.AsyncSolveIsing, probs, sp, maxInFlight)
+}
+
+// SolveManyQubo is the QUBO analogue of SolveMany.
+func (s *Solver) SolveManyQubo(probs []Problem, sp SolverParameters, maxInFlight int) ([]IsingResult, error) {
+	return s.solveMany(s.AsyncSolveQubo, probs, sp, maxInFlight)
+}
@@ -0,0 +1,92 @@
+// This file provides renderer-independent geometric data describing how an
+// embedding's chains are laid out on a chip.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"sort"
+)
+
+// A ChainPath describes the geometry of one chain in an embedding.
+type ChainPath struct {
+	Variable int            // Logical variable the chain represents
+	Qubits   []int          // Physical qubits in the chain, in increasing order
+	Coords   []ChimeraCoord // Chimera coordinates of each qubit in Qubits
+	Points   []Point        // Chip location of each qubit in Qubits
+	Edges    [][2]int       // Couplers joining two qubits in the chain, as indexes into Qubits
+}
+
+// ChainPaths returns geometric data for each chain in an embedding in a given
+// topology, ordered by logical variable.  The hardware adjacency determines
+// which couplers join the qubits in each chain.  The result is independent of
+// any particular renderer.  ChainPaths fails if the embedding uses a qubit
+// that does not exist in the topology.
+func (emb Embeddings) ChainPaths(adj Problem, ts TopologySpec) ([]ChainPath, error) {
+	// Ensure that the embedding fits within the topology.
+	if err := ts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(emb) > ts.NumQubits() {
+		for q := ts.NumQubits(); q < len(emb); q++ {
+			if emb[q] >= 0 {
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Qubit %d does not exist in a {%d, %d, %d} %s graph", q, ts.M, ts.N, ts.L, ts.Family)
+			}
+		}
+	}
+
+	// Construct a path for each chain.
+	chains := emb.Chains()
+	vars := make([]int, 0, len(chains))
+	for v := range chains {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	paths := make([]ChainPath, len(vars))
+	where := make(map[int]int) // Map from a qubit to its index within its chain
+	for i, v := range vars {
+		qs := chains[v]
+		sort.Ints(qs)
+		cp := ChainPath{
+			Variable: v,
+			Qubits:   qs,
+			Coords:   make([]ChimeraCoord, len(qs)),
+			Points:   make([]Point, len(qs)),
+		}
+		for j, q := range qs {
+			cp.Coords[j], _ = ts.Coord(q) // Range checked above
+			cp.Points[j] = ts.Point(cp.Coords[j])
+			where[q] = j
+		}
+		paths[i] = cp
+	}
+
+	// Add each intra-chain coupler to the corresponding path.
+	pathOf := make(map[int]int, len(vars))
+	for i, v := range vars {
+		pathOf[v] = i
+	}
+	seen := make(map[[2]int]bool)
+	for _, pe := range adj {
+		q0, q1 := pe.I, pe.J
+		if q0 > q1 {
+			q0, q1 = q1, q0
+		}
+		if q0 == q1 || q1 >= len(emb) || seen[[2]int{q0, q1}] {
+			continue
+		}
+		v := emb[q0]
+		if v < 0 || v != emb[q1] {
+			continue
+		}
+		seen[[2]int{q0, q1}] = true
+		cp := &paths[pathOf[v]]
+		cp.Edges = append(cp.Edges, [2]int{where[q0], where[q1]})
+	}
+	return paths, nil
+}
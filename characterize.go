@@ -0,0 +1,148 @@
+// This file provides a means of characterizing per-qubit biases and effective
+// temperatures by solving simple probe problems.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"math"
+	"sort"
+)
+
+// A CorrectionProfile records the estimated bias and effective inverse
+// temperature of each qubit.  A qubit with bias b behaves as though its h
+// value were increased by b, and its spin is distributed as
+// P(s) ∝ exp(-β·(h+b)·s).
+type CorrectionProfile struct {
+	Bias map[int]float64 // Estimated bias of each qubit, in units of h
+	Beta map[int]float64 // Estimated effective inverse temperature of each qubit
+}
+
+// maxMagnetization bounds estimated magnetizations away from ±1 so that
+// their inverse hyperbolic tangents remain finite.
+const maxMagnetization = 0.999
+
+// magnetizations returns the mean value of each of a set of qubits across all
+// solutions in an IsingResult, weighting each solution by its occurrences.
+func magnetizations(res IsingResult, qubits []int) map[int]float64 {
+	sum := make(map[int]float64, len(qubits))
+	total := 0.0
+	for i, soln := range res.Solutions {
+		w := 1.0
+		if res.Occurrences != nil {
+			w = float64(res.Occurrences[i])
+		}
+		total += w
+		for _, q := range qubits {
+			if q < len(soln) && (soln[q] == 1 || soln[q] == -1) {
+				sum[q] += w * float64(soln[q])
+			}
+		}
+	}
+	mag := make(map[int]float64, len(qubits))
+	for _, q := range qubits {
+		m := 0.0
+		if total > 0 {
+			m = sum[q] / total
+		}
+		mag[q] = math.Max(-maxMagnetization, math.Min(maxMagnetization, m))
+	}
+	return mag
+}
+
+// Characterize estimates the bias and effective temperature of each of a set
+// of qubits.  It solves a zero-field problem, in which any nonzero mean spin
+// indicates a bias, and a probe problem that applies h = probeH to every
+// qubit, in which the response to the field indicates the effective
+// temperature.  All couplers are left at zero, so every qubit is probed
+// independently in a single submission.  The sampler's parameters should
+// request raw answers and many reads.
+func Characterize(s Sampler, qubits []int, probeH float64, sp SolverParameters) (*CorrectionProfile, error) {
+	if probeH == 0 {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The probe field must be nonzero")
+	}
+
+	// Solve the zero-field and probe problems.
+	zero := make(Problem, len(qubits))
+	probe := make(Problem, len(qubits))
+	for i, q := range qubits {
+		zero[i] = ProblemEntry{I: q, J: q, Value: 0}
+		probe[i] = ProblemEntry{I: q, J: q, Value: probeH}
+	}
+	zRes, err := s.SolveIsing(zero, sp)
+	if err != nil {
+		return nil, err
+	}
+	pRes, err := s.SolveIsing(probe, sp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Given <s> = -tanh(β·(h+b)), the zero-field magnetization m0 yields
+	// β·b = -atanh(m0), and the probe magnetization m1 yields
+	// β·(probeH+b) = -atanh(m1).
+	m0 := magnetizations(zRes, qubits)
+	m1 := magnetizations(pRes, qubits)
+	cp := &CorrectionProfile{
+		Bias: make(map[int]float64, len(qubits)),
+		Beta: make(map[int]float64, len(qubits)),
+	}
+	for _, q := range qubits {
+		beta := (math.Atanh(m0[q]) - math.Atanh(m1[q])) / probeH
+		cp.Beta[q] = beta
+		if beta != 0 {
+			cp.Bias[q] = -math.Atanh(m0[q]) / beta
+		}
+	}
+	return cp, nil
+}
+
+// Correct returns a copy of a problem with each characterized qubit's h value
+// adjusted to cancel its estimated bias.
+func (cp *CorrectionProfile) Correct(p Problem) Problem {
+	// Copy the problem, adjusting existing h values.
+	corr := make(Problem, 0, len(p)+len(cp.Bias))
+	seen := make(map[int]bool, len(cp.Bias))
+	for _, pe := range p {
+		if pe.I == pe.J {
+			pe.Value -= cp.Bias[pe.I]
+			seen[pe.I] = true
+		}
+		corr = append(corr, pe)
+	}
+
+	// Add h values for biased qubits that appear only in couplers.
+	used := make(map[int]bool, len(p))
+	for _, pe := range p {
+		used[pe.I] = true
+		used[pe.J] = true
+	}
+	qs := make([]int, 0, len(cp.Bias))
+	for q := range cp.Bias {
+		qs = append(qs, q)
+	}
+	sort.Ints(qs)
+	for _, q := range qs {
+		if b := cp.Bias[q]; used[q] && !seen[q] && b != 0 {
+			corr = append(corr, ProblemEntry{I: q, J: q, Value: -b})
+		}
+	}
+	return corr
+}
+
+// FluxBiases converts a CorrectionProfile's biases to per-qubit flux-bias
+// offsets suitable for a VirtualGraphSolver's FluxBiases field.  (See
+// VirtualGraphSolver.Calibrate for per-chain offsets.)  The scale factor
+// converts from units of h to units of Φ0 and depends on the hardware.
+func (cp *CorrectionProfile) FluxBiases(scale float64) map[int]float64 {
+	fb := make(map[int]float64, len(cp.Bias))
+	for q, b := range cp.Bias {
+		fb[q] = -b * scale
+	}
+	return fb
+}
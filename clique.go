@@ -0,0 +1,193 @@
+// This file provides native clique embeddings for Chimera-structured
+// solvers.  Pegasus and other topology families are not supported because
+// the package models only the Chimera qubit layout (see TopologyFamily).
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+// CliqueSize returns the number of variables in the largest complete graph
+// that FindCliqueEmbedding can embed in a topology with no faulty qubits or
+// couplers.  For an M×N×L Chimera graph, this is L·min(M, N).  CliqueSize
+// returns 0 for unsupported topology families.
+func (ts TopologySpec) CliqueSize() int {
+	if ts.Validate() != nil {
+		return 0
+	}
+	return ts.L * minInt(ts.M, ts.N)
+}
+
+// minInt returns the smaller of two ints.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// cliqueChains returns the L·s chains of a native clique embedding in an
+// s×s block of unit cells whose top-left cell is {r0, c0}.  Chain k·L+i is
+// L-shaped: it comprises the horizontal qubits with index i in row k of the
+// block, from column 0 through column k, and the vertical qubits with index i
+// in column k of the block, from row k through row s-1.  Every chain
+// therefore contains s+1 qubits, and each pair of chains meets in some unit
+// cell.  If flipRows or flipCols is true, the block is reflected vertically
+// or horizontally, respectively.
+func (ts TopologySpec) cliqueChains(s, r0, c0 int, flipRows, flipCols bool) [][]int {
+	cell := func(r, c int) (int, int) {
+		if flipRows {
+			r = s - 1 - r
+		}
+		if flipCols {
+			c = s - 1 - c
+		}
+		return r0 + r, c0 + c
+	}
+	chains := make([][]int, 0, s*ts.L)
+	for k := 0; k < s; k++ {
+		for i := 0; i < ts.L; i++ {
+			chain := make([]int, 0, s+1)
+			for c := 0; c <= k; c++ {
+				row, col := cell(k, c)
+				chain = append(chain, ts.Qubit(ChimeraCoord{Row: row, Col: col, Side: 1, Index: i}))
+			}
+			for r := k; r < s; r++ {
+				row, col := cell(r, k)
+				chain = append(chain, ts.Qubit(ChimeraCoord{Row: row, Col: col, Side: 0, Index: i}))
+			}
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// FindCliqueEmbedding embeds a complete graph of n variables, numbered 0 to
+// n-1, in a Chimera-structured solver.  Unlike FindEmbedding, it is
+// deterministic and fast, and, on a topology with no faults, the chains it
+// produces are as short as possible for a native clique embedding: each
+// contains ⌈n/L⌉+1 qubits.  adj is the solver's working graph, such as that
+// returned by HardwareAdjacency; chains that include a faulty qubit or
+// coupler are avoided.  A nil adj indicates a topology with no faults.
+// FindCliqueEmbedding tries every placement and reflection of progressively
+// larger blocks of unit cells and fails if none accommodates n intact,
+// mutually coupled chains.  Only the Chimera family is supported; other
+// families, such as Pegasus, are rejected with an error.
+func FindCliqueEmbedding(n int, ts TopologySpec, adj Problem) (Embeddings, error) {
+	// Validate the arguments.
+	if ts.Family != ChimeraFamily {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Clique embeddings are unsupported for the %q topology family; only %q is supported", ts.Family, ChimeraFamily)
+	}
+	if err := ts.Validate(); err != nil {
+		return nil, err
+	}
+	if n < 1 || n > ts.CliqueSize() {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "A {%d, %d, %d} %s graph cannot contain a clique of %d variables", ts.M, ts.N, ts.L, ts.Family, n)
+	}
+
+	// Index the working graph.
+	var working map[int]bool
+	var hw map[[2]int]bool
+	if adj != nil {
+		working = make(map[int]bool)
+		hw = make(map[[2]int]bool, 2*len(adj))
+		for _, pe := range adj {
+			working[pe.I] = true
+			working[pe.J] = true
+			hw[[2]int{pe.I, pe.J}] = true
+			hw[[2]int{pe.J, pe.I}] = true
+		}
+	}
+	coupled := func(a, b []int) bool {
+		if hw == nil {
+			return true
+		}
+		for _, qa := range a {
+			for _, qb := range b {
+				if hw[[2]int{qa, qb}] {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	intact := func(chain []int) bool {
+		if hw == nil {
+			return true
+		}
+		for j, q := range chain {
+			if !working[q] || (j > 0 && !hw[[2]int{chain[j-1], q}]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Try each block size, placement, and reflection in turn, greedily
+	// selecting intact chains that couple to all previously selected chains.
+	for s := (n + ts.L - 1) / ts.L; s <= minInt(ts.M, ts.N); s++ {
+		for r0 := 0; r0+s <= ts.M; r0++ {
+			for c0 := 0; c0+s <= ts.N; c0++ {
+				for flip := 0; flip < 4; flip++ {
+					chains := ts.cliqueChains(s, r0, c0, flip&1 != 0, flip&2 != 0)
+					var chosen [][]int
+					for _, ch := range chains {
+						if !intact(ch) {
+							continue
+						}
+						ok := true
+						for _, other := range chosen {
+							if !coupled(ch, other) {
+								ok = false
+								break
+							}
+						}
+						if ok {
+							chosen = append(chosen, ch)
+							if len(chosen) == n {
+								break
+							}
+						}
+					}
+					if len(chosen) < n {
+						continue
+					}
+
+					// Convert the chains to an Embeddings.
+					emb := make(Embeddings, ts.NumQubits())
+					for q := range emb {
+						emb[q] = -1
+					}
+					for v, ch := range chosen {
+						for _, q := range ch {
+							emb[q] = v
+						}
+					}
+					return emb, nil
+				}
+			}
+		}
+	}
+	return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Failed to find a clique embedding of %d variables in the working graph", n)
+}
+
+// cliqueSize returns the number of variables in a problem if its variables
+// are numbered 0 to N-1 and every pair of them is coupled.  Otherwise, it
+// returns 0.
+func (p Problem) cliqueSize() int {
+	g := p.Graph()
+	vs := g.Vertices()
+	n := len(vs)
+	if n == 0 || vs[0] != 0 || vs[n-1] != n-1 {
+		return 0
+	}
+	for _, v := range vs {
+		if g.Degree(v) != n-1 {
+			return 0
+		}
+	}
+	return n
+}
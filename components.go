@@ -0,0 +1,249 @@
+// This file provides support for splitting a problem into its connected
+// components and routing each component to a different sampler.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"sort"
+)
+
+// A Component is a connected component of a larger problem.  Its variables
+// are renumbered from 0 to N-1.
+type Component struct {
+	Vars    []int   // Original variable number of each renumbered variable
+	Problem Problem // Component problem in terms of renumbered variables
+}
+
+// Components partitions a problem into its connected components.  Components
+// are returned in order of their smallest original variable number.
+func (p Problem) Components() []Component {
+	// Union variables that share a nonzero coefficient.
+	parent := make(map[int]int)
+	var find func(v int) int
+	find = func(v int) int {
+		if _, ok := parent[v]; !ok {
+			parent[v] = v
+		}
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+	for _, pe := range p {
+		ri, rj := find(pe.I), find(pe.J)
+		if ri != rj && pe.Value != 0.0 {
+			if ri < rj {
+				parent[rj] = ri
+			} else {
+				parent[ri] = rj
+			}
+		}
+	}
+
+	// Group variables by their root.
+	groups := make(map[int][]int)
+	for v := range parent {
+		r := find(v)
+		groups[r] = append(groups[r], v)
+	}
+	roots := make([]int, 0, len(groups))
+	for r := range groups {
+		roots = append(roots, r)
+	}
+	sort.Ints(roots)
+
+	// Construct a renumbered problem for each group.
+	comps := make([]Component, len(roots))
+	which := make(map[int]int, len(parent))
+	local := make(map[int]int, len(parent))
+	for c, r := range roots {
+		vars := groups[r]
+		sort.Ints(vars)
+		for i, v := range vars {
+			which[v] = c
+			local[v] = i
+		}
+		comps[c].Vars = vars
+	}
+	for _, pe := range p {
+		c := which[pe.I]
+		if which[pe.J] != c {
+			continue // Zero-valued coefficient between components
+		}
+		comps[c].Problem = append(comps[c].Problem,
+			ProblemEntry{I: local[pe.I], J: local[pe.J], Value: pe.Value})
+	}
+	return comps
+}
+
+// A Route directs components of up to a given size to a particular sampler.
+type Route struct {
+	Name    string           // Name to record as the provenance of each routed component
+	MaxVars int              // Maximum number of variables in a routed component (0 = no limit)
+	Sampler Sampler          // Sampler to which components are routed
+	Params  SolverParameters // Parameters to pass to the sampler (nil = sampler defaults)
+}
+
+// A Router solves a problem component by component, sending each component to
+// the first Route that can accept it.  For example, tiny components can be
+// routed to an exact solver, medium-sized components to a quantum solver, and
+// huge components to a heuristic solver.
+type Router struct {
+	Routes []Route // Routes to consider, in order
+}
+
+// ComponentProvenance records how one component of a problem was solved.
+type ComponentProvenance struct {
+	Vars   []int   // Original variable numbers in the component
+	Route  string  // Name of the route that solved the component
+	Energy float64 // Lowest energy found for the component
+}
+
+// A RoutedResult is the result of solving a problem with a Router.
+type RoutedResult struct {
+	IsingResult                       // Recombined solutions and energies
+	Components  []ComponentProvenance // How each component was solved
+}
+
+// route returns the first route that accepts a component of a given size.
+func (r *Router) route(nv int) (*Route, error) {
+	for i := range r.Routes {
+		rt := &r.Routes[i]
+		if rt.MaxVars == 0 || nv <= rt.MaxVars {
+			return rt, nil
+		}
+	}
+	return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "No route accepts a component of %d variables", nv)
+}
+
+// solve is the common code for SolveIsing and SolveQubo.
+func (r *Router) solve(p Problem, qubo bool) (RoutedResult, error) {
+	// Solve each component on its designated route.
+	comps := p.Components()
+	results := make([]IsingResult, len(comps))
+	var rr RoutedResult
+	for c, comp := range comps {
+		rt, err := r.route(len(comp.Vars))
+		if err != nil {
+			return RoutedResult{}, err
+		}
+		sp := rt.Params
+		if sp == nil {
+			sp = rt.Sampler.NewSolverParameters()
+		}
+		if qubo {
+			results[c], err = rt.Sampler.SolveQubo(comp.Problem, sp)
+		} else {
+			results[c], err = rt.Sampler.SolveIsing(comp.Problem, sp)
+		}
+		if err != nil {
+			return RoutedResult{}, err
+		}
+		prov := ComponentProvenance{Vars: comp.Vars, Route: rt.Name}
+		if len(results[c].Energies) > 0 {
+			prov.Energy = results[c].Energies[0]
+		}
+		rr.Components = append(rr.Components, prov)
+	}
+
+	// Recombine the solutions.  Because each component's solutions are
+	// sorted by increasing energy, combining the ith solution of every
+	// component yields combined solutions that are sorted as well.
+	nv := 0
+	for _, pe := range p {
+		if pe.I+1 > nv {
+			nv = pe.I + 1
+		}
+		if pe.J+1 > nv {
+			nv = pe.J + 1
+		}
+	}
+	ns := -1
+	for _, res := range results {
+		if ns == -1 || len(res.Solutions) < ns {
+			ns = len(res.Solutions)
+		}
+	}
+	if ns < 0 {
+		ns = 0
+	}
+	rr.Solutions = make([][]int8, ns)
+	rr.Energies = make([]float64, ns)
+	rr.ScaleFactor = 1 // Components may have been scaled by different factors.
+	for i := range rr.Solutions {
+		soln := make([]int8, nv)
+		for v := range soln {
+			soln[v] = 3
+		}
+		for c, comp := range comps {
+			cs := results[c].Solutions[i]
+			for j, v := range comp.Vars {
+				if j < len(cs) {
+					soln[v] = cs[j]
+				}
+			}
+			rr.Energies[i] += results[c].Energies[i]
+		}
+		rr.Solutions[i] = soln
+	}
+	return rr, nil
+}
+
+// SolveIsing solves an Ising-model problem component by component.
+func (r *Router) SolveIsing(p Problem) (RoutedResult, error) {
+	return r.solve(p, false)
+}
+
+// SolveQubo solves a QUBO problem component by component.
+func (r *Router) SolveQubo(p Problem) (RoutedResult, error) {
+	return r.solve(p, true)
+}
+
+// A ComponentComposite wraps a sampler and solves each connected component of
+// a problem independently on that sampler.  This lets the underlying sampler
+// devote its full capacity (e.g., qubits or, for an ExactSolver, variables)
+// to one component at a time instead of wasting it on the problem as a
+// whole.  The ith recombined solution combines the ith solution of every
+// component, so the number of solutions returned is that of the component
+// with the fewest solutions.  Use a Router to send different components to
+// different samplers.
+type ComponentComposite struct {
+	Sampler Sampler // Underlying sampler
+}
+
+// solve is the common code for SolveIsing and SolveQubo.
+func (cc *ComponentComposite) solve(p Problem, sp SolverParameters, qubo bool) (IsingResult, error) {
+	r := Router{Routes: []Route{{Sampler: cc.Sampler, Params: sp}}}
+	rr, err := r.solve(p, qubo)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return rr.IsingResult, nil
+}
+
+// SolveIsing solves an Ising-model problem component by component.
+func (cc *ComponentComposite) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	return cc.solve(p, sp, false)
+}
+
+// SolveQubo solves a QUBO problem component by component.
+func (cc *ComponentComposite) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	return cc.solve(p, sp, true)
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying sampler.
+func (cc *ComponentComposite) NewSolverParameters() SolverParameters {
+	return cc.Sampler.NewSolverParameters()
+}
+
+// Properties returns the underlying sampler's properties.
+func (cc *ComponentComposite) Properties() *SolverProperties {
+	return cc.Sampler.Properties()
+}
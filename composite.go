@@ -0,0 +1,102 @@
+// This file provides helper functions shared by the various solver wrappers
+// that embed a logical problem in a physical topology.
+
+package sapi
+
+// isingEnergy computes the energy of a solution to an Ising-model problem.
+// The solution is indexed by variable number.  Given 0/1 values rather than
+// ±1, isingEnergy computes the energy of a solution to a QUBO problem.
+func isingEnergy(p Problem, soln []int8) float64 {
+	e := 0.0
+	for _, pe := range p {
+		if pe.I == pe.J {
+			e += pe.Value * float64(soln[pe.I])
+		} else {
+			e += pe.Value * float64(soln[pe.I]*soln[pe.J])
+		}
+	}
+	return e
+}
+
+// spinsToBits converts a list of Ising-model solutions (±1) to QUBO
+// solutions (0/1) in place.  Other values (e.g., 3 for "unused") are left
+// untouched.
+func spinsToBits(solns [][]int8) {
+	for _, soln := range solns {
+		for i, s := range soln {
+			switch s {
+			case -1:
+				soln[i] = 0
+			case 1:
+				soln[i] = 1
+			}
+		}
+	}
+}
+
+// embedWithChains embeds a logical Ising-model problem in a solver's topology
+// and returns the physical problem, in which couplers introduced to form
+// chains are assigned the value chainStrength.
+func embedWithChains(p Problem, emb Embeddings, adj Problem, ranges IsingRangeProperties,
+	chainStrength float64) (Problem, *EmbedProblemResult, error) {
+	epr, err := EmbedProblem(p, emb, adj, false, false, ranges)
+	if err != nil {
+		return nil, nil, err
+	}
+	eProb := make(Problem, len(epr.Prob), len(epr.Prob)+len(epr.JC))
+	copy(eProb, epr.Prob)
+	for _, pe := range epr.JC {
+		pe.Value = chainStrength
+		eProb = append(eProb, pe)
+	}
+	return eProb, epr, nil
+}
+
+// solveEmbeddedIsing embeds a logical Ising-model problem in a solver's
+// topology, solves it, and maps the solutions back to logical variables.
+// Couplers introduced to form chains are assigned the value chainStrength.
+// Energies are recomputed with respect to the logical problem.  If broken
+// chains cause solutions to be discarded, the result's Occurrences field is
+// set to nil, as the tallies can no longer be matched to solutions.
+func solveEmbeddedIsing(s *Solver, p Problem, emb Embeddings, adj Problem, ranges IsingRangeProperties,
+	chainStrength float64, broken BrokenChains, sp SolverParameters) (IsingResult, error) {
+	// Embed the problem.
+	eProb, epr, err := embedWithChains(p, emb, adj, ranges, chainStrength)
+	if err != nil {
+		return IsingResult{}, err
+	}
+
+	// Solve the embedded problem.
+	res, err := s.SolveIsing(eProb, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	if len(res.Solutions) == 0 {
+		return res, nil
+	}
+
+	// Unembed the solutions and recompute their energies.
+	solns, err := UnembedAnswer(res.Solutions, epr.Emb, broken, p)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	if len(solns) != len(res.Solutions) {
+		res.Occurrences = nil
+	}
+	res.Solutions = solns
+	res.Energies = p.Energies(solns)
+	return res, nil
+}
+
+// solveEmbeddedQubo is the QUBO analogue of solveEmbeddedIsing.
+func solveEmbeddedQubo(s *Solver, p Problem, emb Embeddings, adj Problem, ranges IsingRangeProperties,
+	chainStrength float64, broken BrokenChains, sp SolverParameters) (IsingResult, error) {
+	ip, ofs := p.ToIsing()
+	res, err := solveEmbeddedIsing(s, ip, emb, adj, ranges, chainStrength, broken, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	spinsToBits(res.Solutions)
+	res.AddOffset(ofs)
+	return res, nil
+}
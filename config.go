@@ -0,0 +1,188 @@
+// This file provides a typed alternative to configuring a solver via
+// environment variables.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// A Config specifies all of the information needed to connect to a solver.
+// An empty URL or Token implies a local connection.
+type Config struct {
+	URL           string                       // Remote solver URL or "" for a local connection
+	Token         string                       // Token to authenticate a user
+	Proxy         *string                      // Proxy URL, "" for no proxy, or nil for the system proxy
+	Solver        string                       // Name of the solver to use
+	Timeout       time.Duration                // Maximum time from submission to result or 0 for no limit (see Solver.SetClientTimeout)
+	Defaults      ParameterDefaults            // Default solver parameters for all classes of solver
+	ClassDefaults map[string]ParameterDefaults // Default solver parameters by class (see Connection.ClassDefaults)
+}
+
+// ConfigFromEnvironment returns a Config initialized from the environment
+// variables used by D-Wave's dw tool: DW_INTERNAL__HTTPLINK (solver URL),
+// DW_INTERNAL__TOKEN (API token), DW_INTERNAL__HTTPPROXY (proxy URL), and
+// DW_INTERNAL__SOLVER (solver name).
+func ConfigFromEnvironment() Config {
+	var cfg Config
+	cfg.URL = os.Getenv("DW_INTERNAL__HTTPLINK")
+	cfg.Token = os.Getenv("DW_INTERNAL__TOKEN")
+	if strp, found := os.LookupEnv("DW_INTERNAL__HTTPPROXY"); found {
+		cfg.Proxy = &strp
+	}
+	cfg.Solver = os.Getenv("DW_INTERNAL__SOLVER")
+	return cfg
+}
+
+// Connect establishes either a remote or a local connection, as specified by
+// the Config.
+func (cfg Config) Connect() (*Connection, error) {
+	var conn *Connection
+	if cfg.URL == "" || cfg.Token == "" {
+		conn = LocalConnection()
+	} else {
+		var err error
+		conn, err = RemoteConnection(cfg.URL, cfg.Token, cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	conn.Defaults = cfg.Defaults
+	conn.ClassDefaults = cfg.ClassDefaults
+	return conn, nil
+}
+
+// parameterDefaultsJSON is the configuration-file representation of a
+// ParameterDefaults.
+type parameterDefaultsJSON struct {
+	NumReads      int     `json:"num_reads,omitempty"`
+	MaxAnswers    int     `json:"max_answers,omitempty"`
+	AnswerMode    *string `json:"answer_mode,omitempty"` // "histogram" or "raw"
+	AnnealingTime int     `json:"annealing_time,omitempty"`
+	AutoScale     *bool   `json:"auto_scale,omitempty"`
+}
+
+// toGo converts a parameterDefaultsJSON to a ParameterDefaults.
+func (dj parameterDefaultsJSON) toGo() (ParameterDefaults, error) {
+	d := ParameterDefaults{
+		NumReads:      dj.NumReads,
+		MaxAnswers:    dj.MaxAnswers,
+		AnnealingTime: dj.AnnealingTime,
+		AutoScale:     dj.AutoScale,
+	}
+	if dj.AnswerMode != nil {
+		var am SolverParameterAnswerMode
+		switch *dj.AnswerMode {
+		case "histogram":
+			am = AnswerModeHistogram
+		case "raw":
+			am = AnswerModeRaw
+		default:
+			return d, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unrecognized answer mode %q", *dj.AnswerMode)
+		}
+		d.AnswerMode = &am
+	}
+	return d, nil
+}
+
+// configJSON is the configuration-file representation of a Config.
+type configJSON struct {
+	URL           string                           `json:"url"`
+	Token         string                           `json:"token"`
+	Proxy         *string                          `json:"proxy"`
+	Solver        string                           `json:"solver"`
+	Timeout       string                           `json:"timeout"` // Parsed by time.ParseDuration
+	Defaults      parameterDefaultsJSON            `json:"defaults"`
+	ClassDefaults map[string]parameterDefaultsJSON `json:"class_defaults"`
+}
+
+// ReadConfig reads a Config in JSON format from a stream.  The keys are
+// "url", "token", "proxy", "solver", "timeout" (e.g., "10m"), "defaults",
+// and "class_defaults".  "defaults" specifies default solver parameters for
+// all classes of solver, and "class_defaults" maps a class ("quantum",
+// "sw_optimize", "sw_sample", or "heuristic") to default solver parameters
+// for that class.  Each set of defaults may contain "num_reads",
+// "max_answers", "answer_mode" ("histogram" or "raw"), "annealing_time", and
+// "auto_scale".  This lets solver parameters be tuned without recompiling.
+func ReadConfig(r io.Reader) (Config, error) {
+	// Decode the configuration.
+	var cj configJSON
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cj); err != nil {
+		return Config{}, err
+	}
+
+	// Convert the configuration to a Config.
+	cfg := Config{
+		URL:    cj.URL,
+		Token:  cj.Token,
+		Proxy:  cj.Proxy,
+		Solver: cj.Solver,
+	}
+	var err error
+	if cj.Timeout != "" {
+		cfg.Timeout, err = time.ParseDuration(cj.Timeout)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+	cfg.Defaults, err = cj.Defaults.toGo()
+	if err != nil {
+		return Config{}, err
+	}
+	if len(cj.ClassDefaults) > 0 {
+		cfg.ClassDefaults = make(map[string]ParameterDefaults, len(cj.ClassDefaults))
+		for class, dj := range cj.ClassDefaults {
+			if _, err = newParametersByTypeName(class); err != nil {
+				return Config{}, err
+			}
+			cfg.ClassDefaults[class], err = dj.toGo()
+			if err != nil {
+				return Config{}, err
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// LoadConfig reads a Config from a file in the format accepted by
+// ReadConfig.
+func LoadConfig(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+	return ReadConfig(f)
+}
+
+// NewSolverFromConfig establishes a connection as specified by a Config and
+// returns the solver it names.
+func NewSolverFromConfig(cfg Config) (*Solver, error) {
+	// Establish a connection to either a remote or local solver.
+	if cfg.Solver == "" {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "A solver name must be specified")
+	}
+	conn, err := cfg.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the specified solver.
+	solver, err := conn.Solver(cfg.Solver)
+	if err != nil {
+		return nil, err
+	}
+	solver.Timeout = cfg.Timeout
+	return solver, nil
+}
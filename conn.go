@@ -16,10 +16,13 @@ import (
 
 // A Connection represents a connection to a remote solver.
 type Connection struct {
-	conn  *C.sapi_Connection // SAPI connection object
-	URL   string             // Connection name
-	Token string             // Token to authenticate a user
-	Proxy *string            // Proxy URL or nil for no proxy
+	conn          *C.sapi_Connection           // SAPI connection object
+	URL           string                       // Connection name
+	Token         string                       // Token to authenticate a user
+	Proxy         *string                      // Proxy URL or nil for no proxy
+	Defaults      ParameterDefaults            // Defaults applied by Solver.NewSolverParameters
+	ClassDefaults map[string]ParameterDefaults // Per-class defaults, keyed by "quantum", "sw_optimize", "sw_sample", or "heuristic", applied after Defaults
+	events        eventHandlers                // Functions to invoke on problem lifecycle events
 }
 
 // LocalConnection returns a connection to the set of local solvers (i.e.,
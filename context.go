@@ -0,0 +1,77 @@
+// This file provides variants of the synchronous solve functions that accept
+// a context.Context for cancellation.
+
+package sapi
+
+import (
+	"context"
+	"time"
+)
+
+// contextPollInterval is the maximum time to block in SAPI before checking
+// whether a context has been canceled.
+const contextPollInterval = 100 * time.Millisecond
+
+// solveContext is a helper function for SolveIsingContext and
+// SolveQuboContext that submits a problem asynchronously and cancels it if
+// the context is canceled before the problem completes.
+func (s *Solver) solveContext(ctx context.Context, submit func(Problem, SolverParameters) (*SubmittedProblem, error),
+	p Problem, sp SolverParameters) (IsingResult, error) {
+	// Honor the solver's timeout as well as the context's.  The caller's
+	// context is retained so that we can tell which of the two expired.
+	parent := ctx
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	// Submit the problem unless the context is already canceled.
+	if err := ctx.Err(); err != nil {
+		return IsingResult{}, err
+	}
+	sub, err := submit(p, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+
+	// Wait for the problem to complete or the context to be canceled,
+	// whichever comes first.
+	for {
+		wait := contextPollInterval
+		if dl, ok := ctx.Deadline(); ok {
+			if d := time.Until(dl); d < wait {
+				wait = d
+			}
+		}
+		if wait > 0 && sub.AwaitCompletion(wait) {
+			return sub.Result()
+		}
+		select {
+		case <-ctx.Done():
+			sub.Cancel()
+			if parent.Err() == nil {
+				return IsingResult{}, s.timeoutError()
+			}
+			return IsingResult{}, ctx.Err()
+		default:
+		}
+	}
+}
+
+// SolveIsingContext solves an Ising-model problem.  If the context is
+// canceled or its deadline passes before the problem completes, the remote
+// problem is canceled and the context's error is returned.  If instead the
+// solver's Timeout passes first, the error is the same one SolveIsing
+// reports.
+func (s *Solver) SolveIsingContext(ctx context.Context, p Problem, sp SolverParameters) (IsingResult, error) {
+	return s.solveContext(ctx, s.AsyncSolveIsing, p, sp)
+}
+
+// SolveQuboContext solves a QUBO problem.  If the context is canceled or its
+// deadline passes before the problem completes, the remote problem is
+// canceled and the context's error is returned.  If instead the solver's
+// Timeout passes first, the error is the same one SolveQubo reports.
+func (s *Solver) SolveQuboContext(ctx context.Context, p Problem, sp SolverParameters) (IsingResult, error) {
+	return s.solveContext(ctx, s.AsyncSolveQubo, p, sp)
+}
@@ -10,7 +10,6 @@ package sapi
 import "C"
 
 import (
-	"os"
 	"sort"
 )
 
@@ -20,70 +19,77 @@ import (
 // (DW_INTERNAL__TOKEN), proxy URL (DW_INTERNAL__HTTPPROXY), and solver name
 // (DW_INTERNAL__SOLVER) and invokes either RemoteConnection and
 // LocalConnection, as appropriate, followed by the Solver method on the
-// corresponding connection.
+// corresponding connection.  Programs that prefer to pass connection
+// parameters explicitly should use NewSolverFromConfig instead.
 func NewSolver() (*Solver, error) {
-	// Query the environment for the connection parameters.
-	url := os.Getenv("DW_INTERNAL__HTTPLINK")
-	token := os.Getenv("DW_INTERNAL__TOKEN")
-	var proxy *string
-	if strp, found := os.LookupEnv("DW_INTERNAL__HTTPPROXY"); found {
-		proxy = &strp
-	}
-
-	// Establish a connection to either a remote or local solver.
-	var conn *Connection
-	var err error
-	if url == "" || token == "" {
-		conn = LocalConnection()
-	} else {
-		conn, err = RemoteConnection(url, token, proxy)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// Return the specified solver.
-	sName := os.Getenv("DW_INTERNAL__SOLVER")
-	if sName == "" {
+	cfg := ConfigFromEnvironment()
+	if cfg.Solver == "" {
 		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "A solver must be named via the DW_INTERNAL__SOLVER environment variable")
 	}
-	return conn.Solver(sName)
+	return NewSolverFromConfig(cfg)
 }
 
 // Canonicalize ensures that each ProblemEntry in a given Problem has I ≤ J and
-// that all {I. J} pairs are unique.
+// that all {I. J} pairs are unique.  Entries are sorted by I then J.
+// Duplicate entries are merged by summing their Values in the order in which
+// they appear, so the result depends only on the input.
 func (p Problem) Canonicalize() Problem {
+	c := make(Problem, len(p))
+	copy(c, p)
+	c.CanonicalizeInPlace(false)
+	return c
+}
+
+// CanonicalizeInPlace is like Canonicalize but reuses the Problem's storage
+// rather than allocating a new Problem, which matters for very large
+// problems.  If dropZeros is true, entries whose merged Value is zero are
+// removed.
+func (p *Problem) CanonicalizeInPlace(dropZeros bool) {
 	// Ensure that I ≤ J in each ProblemEntry.
-	p1 := make(Problem, len(p))
-	for i, pe := range p {
+	q := *p
+	for i, pe := range q {
 		if pe.I > pe.J {
-			pe.I, pe.J = pe.J, pe.I
+			q[i].I, q[i].J = pe.J, pe.I
 		}
-		p1[i] = pe
 	}
 
-	// Sort the Problem by I then J.
-	sort.Slice(p1, func(i, j int) bool {
+	// Sort the Problem by I then J, keeping duplicates in their original
+	// order.
+	sort.SliceStable(q, func(i, j int) bool {
 		switch {
-		case p1[i].I < p1[j].I:
+		case q[i].I < q[j].I:
 			return true
-		case p1[i].I > p1[j].I:
+		case q[i].I > q[j].I:
 			return false
 		default:
-			return p1[i].J < p1[j].J
+			return q[i].J < q[j].J
 		}
 	})
 
 	// Merge duplicate {I, J} entries by summing their Values.
-	p2 := make(Problem, 0, len(p1))
-	for i, pe := range p1 {
-		if i > 0 && pe.I == p1[i-1].I && pe.J == p1[i-1].J {
-			p2[len(p2)-1].Value += pe.Value
+	n := 0
+	for _, pe := range q {
+		if n > 0 && pe.I == q[n-1].I && pe.J == q[n-1].J {
+			q[n-1].Value += pe.Value
 		} else {
-			p2 = append(p2, pe)
+			q[n] = pe
+			n++
+		}
+	}
+	q = q[:n]
+
+	// Optionally discard zero-valued entries.
+	if dropZeros {
+		n = 0
+		for _, pe := range q {
+			if pe.Value != 0.0 {
+				q[n] = pe
+				n++
+			}
 		}
+		q = q[:n]
 	}
-	return p2
+	*p = q
 }
 
 // couplerMap returns a map from a spin to a list of all ProblemEntry structs
@@ -115,6 +121,30 @@ func (p Problem) couplerMap() map[int][]ProblemEntry {
 	return cMap
 }
 
+// withAllLinear returns a copy of a problem with a zero-valued linear entry
+// appended for each variable that appears only in quadratic entries.  This
+// ensures that ToIsing and ToQubo compute a linear coefficient for every
+// variable.
+func (p Problem) withAllLinear() Problem {
+	hasLinear := make(map[int]bool, len(p))
+	for _, pe := range p {
+		if pe.I == pe.J {
+			hasLinear[pe.I] = true
+		}
+	}
+	ap := make(Problem, len(p), len(p)+len(p)/2)
+	copy(ap, p)
+	for _, pe := range p {
+		for _, v := range [2]int{pe.I, pe.J} {
+			if !hasLinear[v] {
+				ap = append(ap, ProblemEntry{I: v, J: v})
+				hasLinear[v] = true
+			}
+		}
+	}
+	return ap
+}
+
 // energyOffset returns the difference in energy between a QUBO and an
 // Ising-model problem.
 func (p Problem) energyOffset() float64 {
@@ -134,7 +164,7 @@ func (p Problem) energyOffset() float64 {
 // returns an energy offset to add to each solution's energy.
 func (p Problem) ToIsing() (Problem, float64) {
 	ip := make(Problem, 0, len(p))
-	cp := p.Canonicalize()
+	cp := p.withAllLinear().Canonicalize()
 	cMap := cp.couplerMap()
 	for _, pe := range cp {
 		if pe.I == pe.J {
@@ -157,7 +187,7 @@ func (p Problem) ToIsing() (Problem, float64) {
 // returns an energy offset to add to each solution's energy.
 func (p Problem) ToQubo() (Problem, float64) {
 	qp := make(Problem, 0, len(p))
-	cp := p.Canonicalize()
+	cp := p.withAllLinear().Canonicalize()
 	cMap := cp.couplerMap()
 	for _, pe := range cp {
 		if pe.I == pe.J {
@@ -175,3 +205,39 @@ func (p Problem) ToQubo() (Problem, float64) {
 	}
 	return qp, -qp.energyOffset()
 }
+
+// IsingEnergy returns the energy of a solution to an Ising-model problem.
+// The solution is indexed by variable number and contains ±1 values, as in
+// IsingResult.Solutions.  Entries for variables that do not appear in the
+// problem are ignored.
+func (p Problem) IsingEnergy(s []int8) float64 {
+	return isingEnergy(p, s)
+}
+
+// QuboEnergy returns the energy of a solution to a QUBO problem.  The
+// solution is indexed by variable number and contains 0/1 values, as in
+// IsingResult.Solutions.  Entries for variables that do not appear in the
+// problem are ignored.
+func (p Problem) QuboEnergy(s []int8) float64 {
+	return isingEnergy(p, s)
+}
+
+// AddOffset adds a constant energy offset to each solution's energy.  This is
+// typically used to return energies to the frame of the problem from which a
+// solved problem was derived, such as with ToIsing or ToQubo.  (See
+// OffsetProblem, which does this automatically.)
+func (ir *IsingResult) AddOffset(ofs float64) {
+	for i := range ir.Energies {
+		ir.Energies[i] += ofs
+	}
+}
+
+// NumOccurrences returns the number of reads that produced the i-th solution.
+// This is the i-th element of Occurrences or, in raw answer mode, where
+// Occurrences is nil, 1.
+func (ir IsingResult) NumOccurrences(i int) int {
+	if ir.Occurrences == nil {
+		return 1
+	}
+	return ir.Occurrences[i]
+}
@@ -0,0 +1,97 @@
+// This file provides a record of a conversion between QUBO and Ising-model
+// problems that can map solutions and energies in either direction.
+
+package sapi
+
+// A Conversion records the conversion of a problem between QUBO and
+// Ising-model form.  The converted problem is an OffsetProblem whose offset
+// is the energy of the original problem minus that of the converted problem,
+// so solving it with OffsetProblem.SolveIsing or OffsetProblem.SolveQubo
+// already reports energies in the frame of the original problem.  Conversion
+// additionally provides methods that map solutions, and energies obtained
+// without the offset, between the original and converted problems, making
+// round trips hard to get wrong.
+type Conversion struct {
+	OffsetProblem      // Converted problem and its energy offset
+	fromQubo      bool // true if the original problem is a QUBO
+}
+
+// ConvertToIsing converts a QUBO problem to an Ising-model problem and
+// returns a record of the conversion.
+func (p Problem) ConvertToIsing() Conversion {
+	return Conversion{OffsetProblem: OffsetProblem{Problem: p}.ToIsing(), fromQubo: true}
+}
+
+// ConvertToQubo converts an Ising-model problem to a QUBO problem and returns
+// a record of the conversion.
+func (p Problem) ConvertToQubo() Conversion {
+	return Conversion{OffsetProblem: OffsetProblem{Problem: p}.ToQubo()}
+}
+
+// bitsToSpins converts a QUBO solution (0/1) to an Ising-model solution (±1)
+// in place.  Other values (e.g., 3 for "unused") are left untouched.
+func bitsToSpins(soln []int8) {
+	for i, s := range soln {
+		switch s {
+		case 0:
+			soln[i] = -1
+		case 1:
+			soln[i] = 1
+		}
+	}
+}
+
+// convertSolution returns a copy of a solution converted to QUBO form if
+// toQubo is true or to Ising-model form otherwise.
+func convertSolution(s []int8, toQubo bool) []int8 {
+	c := make([]int8, len(s))
+	copy(c, s)
+	if toQubo {
+		spinsToBits([][]int8{c})
+	} else {
+		bitsToSpins(c)
+	}
+	return c
+}
+
+// SolutionToOriginal maps a solution of the converted problem to the
+// corresponding solution of the original problem.  The argument is not
+// modified.
+func (c Conversion) SolutionToOriginal(s []int8) []int8 {
+	return convertSolution(s, c.fromQubo)
+}
+
+// SolutionToConverted maps a solution of the original problem to the
+// corresponding solution of the converted problem.  The argument is not
+// modified.
+func (c Conversion) SolutionToConverted(s []int8) []int8 {
+	return convertSolution(s, !c.fromQubo)
+}
+
+// EnergyToOriginal maps an energy of the converted problem to the
+// corresponding energy of the original problem.
+func (c Conversion) EnergyToOriginal(e float64) float64 {
+	return e + c.Offset
+}
+
+// EnergyToConverted maps an energy of the original problem to the
+// corresponding energy of the converted problem.
+func (c Conversion) EnergyToConverted(e float64) float64 {
+	return e - c.Offset
+}
+
+// ResultToOriginal maps the solutions and energies of a result for the
+// converted problem to those of the original problem.  The argument is not
+// modified.
+func (c Conversion) ResultToOriginal(ir IsingResult) IsingResult {
+	res := ir
+	res.Solutions = make([][]int8, len(ir.Solutions))
+	for i, s := range ir.Solutions {
+		res.Solutions[i] = c.SolutionToOriginal(s)
+	}
+	res.Energies = make([]float64, len(ir.Energies))
+	for i, e := range ir.Energies {
+		res.Energies[i] = c.EnergyToOriginal(e)
+	}
+	return res
+}
@@ -0,0 +1,289 @@
+// Package csp compiles constraint-satisfaction problems over boolean and
+// discrete variables to QUBO problems.  A Model declares variables,
+// constraints, and an optional objective function; Compile produces a QUBO
+// in which every constraint is expressed as a penalty weighted heavily enough
+// to dominate the objective; and Decode and Verify interpret the solver's
+// solutions in terms of the model.
+//
+// A typical use is
+//
+//	m := csp.NewModel()
+//	a, b := m.NewBool("a"), m.NewBool("b")
+//	m.Implies(a, b)
+//	m.AddLinear(b, 1) // Prefer b to be false.
+//	q := m.Compile()
+//	ir, err := sampler.SolveQubo(q.Problem, sp)
+//	...
+//	if err := m.Verify(ir.Solutions[0]); err == nil {
+//		fmt.Println(m.Value(ir.Solutions[0], a))
+//	}
+package csp
+
+import (
+	"fmt"
+	"github.com/lanl/sapi"
+	"github.com/lanl/sapi/penalty"
+	"strings"
+)
+
+// A Bool is a boolean variable in a Model.  It is represented by a single
+// QUBO variable, with 1 representing true.
+type Bool struct {
+	v int // QUBO variable number
+}
+
+// Var returns the number of the QUBO variable that represents a Bool.
+func (b Bool) Var() int {
+	return b.v
+}
+
+// A Discrete is a variable in a Model that takes one of a fixed number of
+// values, 0 through N-1.  It is represented in one-hot form by N QUBO
+// variables.
+type Discrete struct {
+	dv penalty.DiscreteVar // Encoding of the variable
+}
+
+// Is returns a Bool that is true exactly when a Discrete takes a given
+// value.  The result can be used in any constraint that accepts a Bool.
+func (d Discrete) Is(val int) Bool {
+	return Bool{v: d.dv.Vars[val]}
+}
+
+// NumVals returns the number of values a Discrete can take.
+func (d Discrete) NumVals() int {
+	return d.dv.NumVals
+}
+
+// A constraint is a penalty function plus a means of checking it directly.
+type constraint struct {
+	name  string              // Description for error messages
+	g     penalty.Gadget      // Penalty with a gap of at least 1
+	check func(x []int8) bool // Test of whether a solution satisfies the constraint
+}
+
+// A Model is a constraint-satisfaction problem under construction.  The zero
+// value is an empty model ready for use.
+type Model struct {
+	names       map[int]string      // Name of each QUBO variable that represents a Bool
+	next        int                 // Next unused QUBO variable number
+	constraints []constraint        // All constraints in the model
+	objective   sapi.ProblemBuilder // Objective function to minimize
+}
+
+// NewModel returns an empty Model.
+func NewModel() *Model {
+	return &Model{}
+}
+
+// alloc allocates n consecutive QUBO variables and returns the first.
+func (m *Model) alloc(n int) int {
+	v := m.next
+	m.next += n
+	return v
+}
+
+// name returns the name of a Bool.
+func (m *Model) name(b Bool) string {
+	if nm, ok := m.names[b.v]; ok {
+		return nm
+	}
+	return fmt.Sprintf("x%d", b.v)
+}
+
+// NewBool declares a boolean variable with a given name, which is used only
+// in error messages.
+func (m *Model) NewBool(name string) Bool {
+	b := Bool{v: m.alloc(1)}
+	if m.names == nil {
+		m.names = make(map[int]string)
+	}
+	m.names[b.v] = name
+	return b
+}
+
+// NewDiscrete declares a discrete variable with n values and a given name,
+// which is used only in error messages.
+func (m *Model) NewDiscrete(name string, n int) Discrete {
+	vars := make([]int, n)
+	first := m.alloc(n)
+	if m.names == nil {
+		m.names = make(map[int]string)
+	}
+	for i := range vars {
+		vars[i] = first + i
+		m.names[vars[i]] = fmt.Sprintf("%s=%d", name, i)
+	}
+	d := Discrete{dv: penalty.OneHot(vars)}
+	m.constraints = append(m.constraints, constraint{
+		name: fmt.Sprintf("%s takes exactly one value", name),
+		g:    d.dv.Gadget,
+		check: func(x []int8) bool {
+			_, ok := d.dv.Decode(x)
+			return ok
+		},
+	})
+	return d
+}
+
+// Equal constrains two Bools to be equal.
+func (m *Model) Equal(a, b Bool) {
+	m.constraints = append(m.constraints, constraint{
+		name:  fmt.Sprintf("%s = %s", m.name(a), m.name(b)),
+		g:     penalty.ConstraintEq(map[int]float64{a.v: 1, b.v: -1}, 0, 1),
+		check: func(x []int8) bool { return x[a.v] == x[b.v] },
+	})
+}
+
+// Implies constrains a to imply b.  The penalty is a - ab.
+func (m *Model) Implies(a, b Bool) {
+	var pb sapi.ProblemBuilder
+	pb.AddLinear(a.v, 1)
+	pb.AddLinear(b.v, 0)
+	pb.AddQuadratic(a.v, b.v, -1)
+	m.constraints = append(m.constraints, constraint{
+		name:  fmt.Sprintf("%s → %s", m.name(a), m.name(b)),
+		g:     penalty.Gadget{Problem: pb.Build(), Gap: 1},
+		check: func(x []int8) bool { return x[a.v] == 0 || x[b.v] == 1 },
+	})
+}
+
+// Table constrains a list of Bools to take one of a list of allowed
+// combinations of values.  The constraint introduces one ancillary QUBO
+// variable per allowed combination, which selects that combination in
+// one-hot fashion.
+func (m *Model) Table(vars []Bool, allowed [][]bool) error {
+	// Validate the table.
+	for i, row := range allowed {
+		if len(row) != len(vars) {
+			return fmt.Errorf("Row %d of the table contains %d values for %d variables", i, len(row), len(vars))
+		}
+	}
+
+	// Select exactly one row.
+	first := m.alloc(len(allowed))
+	sel := make([]int, len(allowed))
+	for i := range sel {
+		sel[i] = first + i
+	}
+	oh := penalty.OneHot(sel)
+	prob, ofs := oh.Problem, oh.Offset
+
+	// Require each variable to equal its value in the selected row.
+	for j, b := range vars {
+		coeffs := map[int]float64{b.v: 1}
+		for i, row := range allowed {
+			if row[j] {
+				coeffs[sel[i]] = -1
+			}
+		}
+		eq := penalty.ConstraintEq(coeffs, 0, 1)
+		prob = prob.Add(eq.Problem)
+		ofs += eq.Offset
+	}
+
+	// Record the constraint.
+	names := make([]string, len(vars))
+	for j, b := range vars {
+		names[j] = m.name(b)
+	}
+	m.constraints = append(m.constraints, constraint{
+		name: fmt.Sprintf("table(%s)", strings.Join(names, ", ")),
+		g:    penalty.Gadget{Problem: prob, Offset: ofs, Gap: 1, Ancillas: sel},
+		check: func(x []int8) bool {
+			for _, row := range allowed {
+				match := true
+				for j, b := range vars {
+					if (x[b.v] == 1) != row[j] {
+						match = false
+						break
+					}
+				}
+				if match {
+					return true
+				}
+			}
+			return false
+		},
+	})
+	return nil
+}
+
+// AddLinear adds v·b to the objective function to be minimized.
+func (m *Model) AddLinear(b Bool, v float64) {
+	m.objective.AddLinear(b.v, v)
+}
+
+// AddQuadratic adds v·a·b to the objective function to be minimized.
+func (m *Model) AddQuadratic(a, b Bool, v float64) {
+	m.objective.AddQuadratic(a.v, b.v, v)
+}
+
+// A Compiled is the QUBO form of a Model.
+type Compiled struct {
+	Problem sapi.Problem // QUBO problem to solve
+	Offset  float64      // Constant to add to a solution's energy to obtain the objective value plus penalties
+	Weight  float64      // Weight by which each constraint's penalty was multiplied
+}
+
+// Compile converts a model to a QUBO problem.  Each constraint's penalty,
+// which has a gap of at least 1, is multiplied by a weight chosen with
+// penalty.SuggestWeight so that violating any constraint costs more than the
+// objective function can gain.  Consequently, if the model is satisfiable,
+// its ground states satisfy every constraint.
+func (m *Model) Compile() *Compiled {
+	obj := m.objective.Build()
+	c := &Compiled{Weight: penalty.SuggestWeight(obj)}
+	var pb sapi.ProblemBuilder
+	for v := 0; v < m.next; v++ {
+		pb.AddLinear(v, 0)
+	}
+	for _, pe := range obj {
+		pb.AddQuadratic(pe.I, pe.J, pe.Value)
+	}
+	for _, con := range m.constraints {
+		for _, pe := range con.g.Problem {
+			pb.AddQuadratic(pe.I, pe.J, c.Weight*pe.Value)
+		}
+		c.Offset += c.Weight * con.g.Offset
+	}
+	c.Problem = pb.Build()
+	return c
+}
+
+// NumVars returns the number of QUBO variables the model uses, including
+// ancillary variables.
+func (m *Model) NumVars() int {
+	return m.next
+}
+
+// Value returns the value of a Bool in a solution to the compiled model.
+func (m *Model) Value(soln []int8, b Bool) bool {
+	return soln[b.v] == 1
+}
+
+// DiscreteValue returns the value of a Discrete in a solution to the
+// compiled model.  It returns false if the solution does not assign the
+// variable exactly one value.
+func (m *Model) DiscreteValue(soln []int8, d Discrete) (int, bool) {
+	return d.dv.Decode(soln)
+}
+
+// Verify checks a solution to the compiled model against every constraint.
+// It returns an error describing all violated constraints or nil if the
+// solution satisfies them all.
+func (m *Model) Verify(soln []int8) error {
+	if len(soln) < m.next {
+		return fmt.Errorf("The solution contains %d variables, but the model requires %d", len(soln), m.next)
+	}
+	var bad []string
+	for _, con := range m.constraints {
+		if !con.check(soln) {
+			bad = append(bad, con.name)
+		}
+	}
+	if len(bad) > 0 {
+		return fmt.Errorf("Violated constraints: %s", strings.Join(bad, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// This file tests the compilation of constraint-satisfaction problems.
+
+package csp_test
+
+import (
+	"github.com/lanl/sapi"
+	"github.com/lanl/sapi/csp"
+	"testing"
+)
+
+// TestModel ensures that the ground state of a compiled model satisfies its
+// constraints and optimizes its objective.
+func TestModel(t *testing.T) {
+	// Define a model in which a → b, b = c, (c, d) is drawn from a table,
+	// and color takes one of three values, which must not be 0 if d holds.
+	m := csp.NewModel()
+	a, b, c, d := m.NewBool("a"), m.NewBool("b"), m.NewBool("c"), m.NewBool("d")
+	color := m.NewDiscrete("color", 3)
+	m.Implies(a, b)
+	m.Equal(b, c)
+	if err := m.Table([]csp.Bool{c, d}, [][]bool{{true, false}, {false, true}}); err != nil {
+		t.Fatal(err)
+	}
+	m.Implies(d, color.Is(2))
+
+	// Prefer a to be true and color to be 1.
+	m.AddLinear(a, -1)
+	m.AddLinear(color.Is(1), -1)
+
+	// Solve the model exactly.
+	q := m.Compile()
+	var es sapi.ExactSolver
+	res, err := es.SolveQubo(q.Problem, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	soln := res.Solutions[0]
+	if err := m.Verify(soln); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Value(soln, a) || !m.Value(soln, c) || m.Value(soln, d) {
+		t.Fatalf("Unexpected ground state %v", soln)
+	}
+	if v, ok := m.DiscreteValue(soln, color); !ok || v != 1 {
+		t.Fatalf("Expected color 1 but saw %d", v)
+	}
+	if e := res.Energies[0] + q.Offset; e != -2 {
+		t.Fatalf("Expected an objective value of -2 but saw %v", e)
+	}
+
+	// Ensure that Verify reports violations.
+	bad := make([]int8, m.NumVars())
+	bad[a.Var()] = 1
+	if err := m.Verify(bad); err == nil {
+		t.Fatal("Verify failed to report violated constraints")
+	}
+}
@@ -0,0 +1,134 @@
+// This file provides a means of selecting a solver based on its capabilities
+// rather than its name.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// softwareSolverSuffixes lists the name suffixes that SAPI uses for software
+// solvers.
+var softwareSolverSuffixes = []string{"-sw_optimize", "-sw_sample", "-heuristic"}
+
+// IsQuantum says whether a solver represents quantum hardware rather than a
+// software solver.
+func (s *Solver) IsQuantum() bool {
+	for _, sfx := range softwareSolverSuffixes {
+		if strings.HasSuffix(s.Name, sfx) {
+			return false
+		}
+	}
+	return true
+}
+
+// Criteria specify the capabilities a solver must have to be returned by
+// FindSolver.  Zero-valued fields impose no constraint.
+type Criteria struct {
+	NameGlob      string   // Shell pattern, as accepted by path.Match, the solver name must match
+	MinQubits     int      // Minimum number of working qubits
+	SupportsIsing bool     // Solver must accept Ising-model problems
+	SupportsQubo  bool     // Solver must accept QUBO problems
+	RequiresQPU   bool     // Solver must be quantum hardware, not a software solver
+	Parameters    []string // Parameter names the solver must accept
+}
+
+// matches says whether a solver with the given properties meets a set of
+// Criteria.
+func (cr *Criteria) matches(s *Solver, props *SolverProperties) bool {
+	// Check the solver's name and type.
+	if cr.NameGlob != "" {
+		if ok, err := path.Match(cr.NameGlob, s.Name); err != nil || !ok {
+			return false
+		}
+	}
+	if cr.RequiresQPU && !s.IsQuantum() {
+		return false
+	}
+
+	// Check the solver's properties.
+	if cr.MinQubits > 0 && (props.QuantumProps == nil || len(props.QuantumProps.Qubits) < cr.MinQubits) {
+		return false
+	}
+	has := func(list []string, str string) bool {
+		for _, l := range list {
+			if l == str {
+				return true
+			}
+		}
+		return false
+	}
+	if cr.SupportsIsing && !has(props.SupportedProblemTypes, "ising") {
+		return false
+	}
+	if cr.SupportsQubo && !has(props.SupportedProblemTypes, "qubo") {
+		return false
+	}
+	for _, p := range cr.Parameters {
+		if !has(props.Parameters, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindSolver inspects every solver available on a connection and returns the
+// one that best meets a set of Criteria.  Among matching solvers, FindSolver
+// prefers quantum hardware to software solvers, then solvers with more
+// working qubits, then solvers whose names sort first.
+func (c *Connection) FindSolver(cr Criteria) (*Solver, error) {
+	// Acquire a list of all matching solvers.
+	names, err := c.Solvers()
+	if err != nil {
+		return nil, err
+	}
+	type candidate struct {
+		solver *Solver
+		qubits int
+	}
+	cands := make([]candidate, 0, len(names))
+	for _, nm := range names {
+		s, err := c.Solver(nm)
+		if err != nil {
+			return nil, err
+		}
+		props := s.Properties()
+		if !cr.matches(s, props) {
+			s.Close()
+			continue
+		}
+		nq := 0
+		if props.QuantumProps != nil {
+			nq = len(props.QuantumProps.Qubits)
+		}
+		cands = append(cands, candidate{solver: s, qubits: nq})
+	}
+	if len(cands) == 0 {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "No solver on connection %s meets the given criteria", c.URL)
+	}
+
+	// Return the best candidate, and release all the others.
+	sort.Slice(cands, func(i, j int) bool {
+		si, sj := cands[i].solver, cands[j].solver
+		switch {
+		case si.IsQuantum() != sj.IsQuantum():
+			return si.IsQuantum()
+		case cands[i].qubits != cands[j].qubits:
+			return cands[i].qubits > cands[j].qubits
+		default:
+			return si.Name < sj.Name
+		}
+	})
+	for _, cand := range cands[1:] {
+		cand.solver.Close()
+	}
+	return cands[0].solver, nil
+}
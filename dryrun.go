@@ -0,0 +1,99 @@
+// This file provides a means of performing every client-side step of a
+// submission without contacting the solver.
+
+package sapi
+
+import (
+	"math"
+	"time"
+)
+
+// A Payload summarizes exactly what a solve call would submit to a solver.
+type Payload struct {
+	Solver              string           // Name of the solver that would receive the problem
+	ProblemType         string           // Either "ising" or "qubo"
+	Problem             Problem          // Canonicalized problem as it would be submitted
+	NumVariables        int              // Number of distinct variables in Problem
+	NumCouplers         int              // Number of couplers in Problem
+	ScaleFactor         float64          // Factor by which the solver would multiply coefficients (1 if not scaled)
+	Parameters          SolverParameters // Solver parameters as they would be submitted
+	ParameterNames      []string         // Names of the parameters that differ from SAPI's defaults
+	EstimatedAccessTime time.Duration    // Predicted QPU access time (0 for software solvers)
+	Embedding           Embeddings       // Embedding applied to the problem, if any
+}
+
+// autoScaleFactor returns the factor by which a solver would scale an
+// Ising-model problem's coefficients to fill its coefficient ranges.
+func autoScaleFactor(p Problem, r *IsingRangeProperties) float64 {
+	worst := 0.0
+	ratio := func(v, lo, hi float64) float64 {
+		switch {
+		case v > 0 && hi > 0:
+			return v / hi
+		case v < 0 && lo < 0:
+			return v / lo
+		default:
+			return 0
+		}
+	}
+	for _, pe := range p {
+		if pe.I == pe.J {
+			worst = math.Max(worst, ratio(pe.Value, r.HMin, r.HMax))
+		} else {
+			worst = math.Max(worst, ratio(pe.Value, r.JMin, r.JMax))
+		}
+	}
+	if worst == 0 {
+		return 1
+	}
+	return 1 / worst
+}
+
+// dryRun is the common code for DryRunIsing and DryRunQubo.
+func (s *Solver) dryRun(ptype string, p Problem, sp SolverParameters) (*Payload, error) {
+	// Canonicalize and round the problem, then validate the result.
+	sub := s.Precision.Apply(p.Canonicalize())
+	if err := s.ValidateProblem(sub, sp); err != nil {
+		return nil, err
+	}
+
+	// Summarize the payload.
+	pl := &Payload{
+		Solver:         s.Name,
+		ProblemType:    ptype,
+		Problem:        sub,
+		NumVariables:   sub.countQubits(),
+		ScaleFactor:    1,
+		Parameters:     sp,
+		ParameterNames: setParameterNames(sp),
+	}
+	for _, pe := range sub {
+		if pe.I != pe.J {
+			pl.NumCouplers++
+		}
+	}
+	if qsp, ok := sp.(*QuantumSolverParameters); ok {
+		pl.EstimatedAccessTime = s.TimingModel().Estimate(qsp)
+		if ir := s.Properties().IsingRanges; qsp.AutoScale && ir != nil {
+			ip := sub
+			if ptype == "qubo" {
+				ip, _ = sub.ToIsing()
+			}
+			pl.ScaleFactor = autoScaleFactor(ip, ir)
+		}
+	}
+	return pl, nil
+}
+
+// DryRunIsing performs every client-side step of SolveIsing—canonicalizing,
+// rounding per the solver's PrecisionPolicy, validating, and converting—and
+// returns a summary of what would be submitted without contacting the
+// solver.
+func (s *Solver) DryRunIsing(p Problem, sp SolverParameters) (*Payload, error) {
+	return s.dryRun("ising", p, sp)
+}
+
+// DryRunQubo is the QUBO analogue of DryRunIsing.
+func (s *Solver) DryRunQubo(p Problem, sp SolverParameters) (*Payload, error) {
+	return s.dryRun("qubo", p, sp)
+}
@@ -0,0 +1,117 @@
+// This file provides an on-disk cache of embeddings.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// An EmbeddingCache stores embeddings in a directory so that they can be
+// reused across program runs.  Embeddings are keyed by the graph hash (see
+// Problem.GraphHash) of both the logical problem and the solver's working
+// graph, so a cached embedding is reused for any problem with the same
+// structure on a solver with the same working graph.  Each embedding is
+// stored in its own file as an EmbeddingDocument (see NewEmbeddingDocument)
+// in the binary format written by WriteEmbedding.  An EmbeddingCache can be
+// shared safely among concurrent goroutines and processes.
+type EmbeddingCache struct {
+	Dir string // Directory in which embeddings are stored
+}
+
+// NewEmbeddingCache returns an EmbeddingCache that stores embeddings in a
+// given directory, creating the directory if necessary.
+func NewEmbeddingCache(dir string) (*EmbeddingCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &EmbeddingCache{Dir: dir}, nil
+}
+
+// keys returns the graph hashes of a problem and a working graph.
+func (c *EmbeddingCache) keys(p, adj Problem) (string, string) {
+	return p.GraphHash(), adj.GraphHash()
+}
+
+// path returns the name of the file that holds the embedding for a given
+// pair of graph hashes.
+func (c *EmbeddingCache) path(pKey, aKey string) string {
+	return filepath.Join(c.Dir, pKey+"-"+aKey+".emb")
+}
+
+// Lookup returns the cached embedding of a problem in a working graph.  It
+// additionally reports whether the embedding was found.  A missing cache
+// entry is not an error.
+func (c *EmbeddingCache) Lookup(p, adj Problem) (Embeddings, bool, error) {
+	pKey, aKey := c.keys(p, adj)
+	f, err := os.Open(c.path(pKey, aKey))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	doc, err := ReadEmbedding(f)
+	if err != nil {
+		return nil, false, err
+	}
+	if doc.ProblemHash != pKey || doc.Topology != aKey {
+		return nil, false, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Cache file %s does not match its name", f.Name())
+	}
+	return doc.Embedding, true, nil
+}
+
+// Store caches an embedding of a problem in a working graph, replacing any
+// existing entry.  The entry is written to a temporary file and renamed
+// into place so that concurrent readers never observe a partial entry.
+func (c *EmbeddingCache) Store(p, adj Problem, emb Embeddings) error {
+	// Write the embedding to a temporary file.
+	pKey, aKey := c.keys(p, adj)
+	f, err := ioutil.TempFile(c.Dir, "tmp-*.emb")
+	if err != nil {
+		return err
+	}
+	doc := NewEmbeddingDocument(emb, p, adj)
+	err = WriteEmbedding(f, doc)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	// Move the file into place.
+	if err = os.Rename(f.Name(), c.path(pKey, aKey)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// FindEmbedding returns the cached embedding of a problem in a working graph
+// or, if there is none, finds one with FindEmbedding and caches it.
+func (c *EmbeddingCache) FindEmbedding(p, adj Problem, fep *FindEmbeddingParameters) (Embeddings, error) {
+	emb, ok, err := c.Lookup(p, adj)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return emb, nil
+	}
+	if emb, err = FindEmbedding(p, adj, fep); err != nil {
+		return nil, err
+	}
+	if err = c.Store(p, adj, emb); err != nil {
+		return nil, err
+	}
+	return emb, nil
+}
@@ -0,0 +1,142 @@
+// This file provides a solver wrapper that transparently embeds logical
+// problems in a solver's topology.
+
+package sapi
+
+// An EmbeddingComposite wraps a solver so that callers can submit problems
+// expressed in terms of arbitrary logical variables.  Each problem is
+// embedded in the solver's topology with FindEmbedding, solved, and mapped
+// back to logical variables.  Variables must be numbered from 0 to N-1.
+type EmbeddingComposite struct {
+	Solver        *Solver                  // Underlying solver
+	FindParams    *FindEmbeddingParameters // Parameters for FindEmbedding
+	ChainStrength float64                  // J value applied to couplers within a chain
+	BrokenChains  BrokenChains             // How to resolve chains whose qubits disagree
+	Topology      *TopologySpec            // Topology for clique embeddings (nil = always use FindEmbedding)
+	Cache         *EmbeddingCache          // On-disk cache of embeddings (nil = no caching)
+	adj           Problem                  // Hardware adjacency
+	ranges        IsingRangeProperties     // Coefficient ranges for the embedded problem
+}
+
+// NewEmbeddingComposite wraps a solver with an EmbeddingComposite.  The
+// chain strength defaults to the most negative J value the solver accepts,
+// and broken chains default to BrokenChainsMinimizeEnergy.
+func NewEmbeddingComposite(s *Solver) (*EmbeddingComposite, error) {
+	adj, err := s.HardwareAdjacency()
+	if err != nil {
+		return nil, err
+	}
+	ranges := IsingRangeProperties{HMin: -1, HMax: 1, JMin: -1, JMax: 1}
+	if ir := s.Properties().IsingRanges; ir != nil {
+		ranges = *ir
+	}
+	fep := NewFindEmbeddingParameters()
+	fep.Verbose = false
+	return &EmbeddingComposite{
+		Solver:        s,
+		FindParams:    fep,
+		ChainStrength: ranges.JMin,
+		BrokenChains:  BrokenChainsMinimizeEnergy,
+		adj:           adj,
+		ranges:        ranges,
+	}, nil
+}
+
+// Embed finds an embedding of a logical problem in the underlying solver's
+// topology.  If Cache is set and holds an embedding of a problem with the
+// same structure in the solver's working graph, Embed returns that.
+// Otherwise, if Topology is set and every pair of variables in the problem
+// is coupled, Embed tries FindCliqueEmbedding, falling back to FindEmbedding
+// if no clique embedding fits or the topology family is not Chimera.  If
+// Cache is set, the new embedding is then stored in it.  Unreadable cache
+// entries are treated as misses, and failures to store an entry are ignored.
+func (ec *EmbeddingComposite) Embed(p Problem) (Embeddings, error) {
+	if ec.Cache == nil {
+		return ec.embed(p)
+	}
+	if emb, ok, err := ec.Cache.Lookup(p, ec.adj); err == nil && ok {
+		return emb, nil
+	}
+	emb, err := ec.embed(p)
+	if err != nil {
+		return nil, err
+	}
+	ec.Cache.Store(p, ec.adj, emb)
+	return emb, nil
+}
+
+// embed finds an embedding of a logical problem without consulting the
+// cache.
+func (ec *EmbeddingComposite) embed(p Problem) (Embeddings, error) {
+	if ec.Topology != nil {
+		if n := p.cliqueSize(); n > 0 {
+			emb, err := FindCliqueEmbedding(n, *ec.Topology, ec.adj)
+			if err == nil {
+				return emb, nil
+			}
+		}
+	}
+	return FindEmbedding(p, ec.adj, ec.FindParams)
+}
+
+// SolveIsing embeds, solves, and unembeds an Ising-model problem.
+func (ec *EmbeddingComposite) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	emb, err := ec.Embed(p)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return solveEmbeddedIsing(ec.Solver, p, emb, ec.adj, ec.ranges,
+		ec.ChainStrength, ec.BrokenChains, sp)
+}
+
+// SolveQubo embeds, solves, and unembeds a QUBO problem.
+func (ec *EmbeddingComposite) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	emb, err := ec.Embed(p)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return solveEmbeddedQubo(ec.Solver, p, emb, ec.adj, ec.ranges,
+		ec.ChainStrength, ec.BrokenChains, sp)
+}
+
+// DryRunIsing embeds an Ising-model problem and performs every client-side
+// step of submitting it without contacting the solver.  See
+// Solver.DryRunIsing.
+func (ec *EmbeddingComposite) DryRunIsing(p Problem, sp SolverParameters) (*Payload, error) {
+	emb, err := ec.Embed(p)
+	if err != nil {
+		return nil, err
+	}
+	eProb, epr, err := embedWithChains(p, emb, ec.adj, ec.ranges, ec.ChainStrength)
+	if err != nil {
+		return nil, err
+	}
+	pl, err := ec.Solver.DryRunIsing(eProb, sp)
+	if err != nil {
+		return nil, err
+	}
+	pl.Embedding = epr.Emb
+	return pl, nil
+}
+
+// DryRunQubo is the QUBO analogue of DryRunIsing.  The payload describes the
+// embedded Ising-model problem that would actually be submitted.
+func (ec *EmbeddingComposite) DryRunQubo(p Problem, sp SolverParameters) (*Payload, error) {
+	ip, _ := p.ToIsing()
+	return ec.DryRunIsing(ip, sp)
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying solver.
+func (ec *EmbeddingComposite) NewSolverParameters() SolverParameters {
+	return ec.Solver.NewSolverParameters()
+}
+
+// Properties returns the underlying solver's properties but with no
+// QuantumProps, as an EmbeddingComposite accepts problems of any structure
+// that can be embedded.
+func (ec *EmbeddingComposite) Properties() *SolverProperties {
+	props := *ec.Solver.Properties()
+	props.QuantumProps = nil
+	return &props
+}
@@ -0,0 +1,84 @@
+// This file provides an alternative to FindEmbedding that is implemented in
+// Go rather than by SAPI.
+
+package sapi
+
+import (
+	"context"
+	"github.com/lanl/sapi/embedder"
+	"time"
+)
+
+// embedderGraph converts a problem to the graph representation used by the
+// embedder package.  Variables that appear only in linear terms become
+// isolated nodes.
+func (p Problem) embedderGraph() embedder.Graph {
+	g := make(embedder.Graph)
+	for _, pe := range p {
+		if pe.I == pe.J {
+			if _, ok := g[pe.I]; !ok {
+				g[pe.I] = nil
+			}
+			continue
+		}
+		g[pe.I] = append(g[pe.I], pe.J)
+		if _, ok := g[pe.J]; !ok {
+			g[pe.J] = nil
+		}
+	}
+	return g
+}
+
+// FindEmbeddingNative is a drop-in alternative to FindEmbedding that uses
+// the pure-Go heuristic in the embedder package instead of SAPI's.  Its
+// independent attempts run in parallel, and it returns early if ctx is
+// canceled.  It honors the Tries, MaxNoImprovement, UseRandomSeed,
+// RandomSeed, and Timeout fields of fep and ignores the others.  If fep is
+// nil, the embedder's defaults are used.  If no random seed is specified,
+// one is drawn from the package-wide source of randomness (see
+// SetRandSource).
+func FindEmbeddingNative(ctx context.Context, pr, adj Problem, fep *FindEmbeddingParameters) (Embeddings, error) {
+	// Translate the parameters.
+	var prm embedder.Params
+	if fep != nil && fep.UseRandomSeed {
+		prm.Seed = int64(fep.RandomSeed)
+	} else {
+		prm.Seed = newRand().Int63()
+	}
+	if fep != nil {
+		prm.Tries = fep.Tries
+		prm.MaxNoImprovement = fep.MaxNoImprovement
+		if fep.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(fep.Timeout*float64(time.Second)))
+			defer cancel()
+		}
+	}
+
+	// Find an embedding.
+	chains, err := embedder.Find(ctx, pr.embedderGraph(), adj.embedderGraph(), &prm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert the chains to an Embeddings.
+	nq := 0
+	for _, pe := range adj {
+		if pe.I >= nq {
+			nq = pe.I + 1
+		}
+		if pe.J >= nq {
+			nq = pe.J + 1
+		}
+	}
+	emb := make(Embeddings, nq)
+	for q := range emb {
+		emb[q] = -1
+	}
+	for v, ch := range chains {
+		for _, q := range ch {
+			emb[q] = v
+		}
+	}
+	return emb, nil
+}
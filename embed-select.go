@@ -0,0 +1,167 @@
+// This file provides functions for evaluating embeddings and for selecting
+// the best of several candidate embeddings.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"math/rand"
+)
+
+// Chains returns, for each logical variable in an embedding, the list of
+// physical qubits that represent it.
+func (emb Embeddings) Chains() map[int][]int {
+	chains := make(map[int][]int)
+	for q, v := range emb {
+		if v >= 0 {
+			chains[v] = append(chains[v], q)
+		}
+	}
+	return chains
+}
+
+// EmbeddingMetrics summarize the quality of an embedding.  Shorter chains
+// generally lead to better solutions.
+type EmbeddingMetrics struct {
+	NumVariables    int     // Number of logical variables embedded
+	NumQubits       int     // Number of physical qubits used
+	MaxChainLength  int     // Number of qubits in the longest chain
+	MeanChainLength float64 // Average number of qubits per chain
+}
+
+// Metrics computes an embedding's quality metrics.
+func (emb Embeddings) Metrics() EmbeddingMetrics {
+	var m EmbeddingMetrics
+	for _, ch := range emb.Chains() {
+		m.NumVariables++
+		m.NumQubits += len(ch)
+		if len(ch) > m.MaxChainLength {
+			m.MaxChainLength = len(ch)
+		}
+	}
+	if m.NumVariables > 0 {
+		m.MeanChainLength = float64(m.NumQubits) / float64(m.NumVariables)
+	}
+	return m
+}
+
+// Better says whether one set of embedding metrics is preferable to another.
+// It favors a shorter maximum chain length and, as a tie breaker, fewer
+// qubits.
+func (m EmbeddingMetrics) Better(other EmbeddingMetrics) bool {
+	if m.MaxChainLength != other.MaxChainLength {
+		return m.MaxChainLength < other.MaxChainLength
+	}
+	return m.NumQubits < other.NumQubits
+}
+
+// FindBestEmbedding runs FindEmbedding on several reformulations of a problem
+// and returns the embedding with the best EmbeddingMetrics.  Because
+// FindEmbedding is heuristic, its result depends on the order in which
+// variables and coefficients are presented, so each reformulation randomly
+// renumbers the problem's variables and reorders its coefficients.  (Negating
+// variables does not change a problem's graph and hence is not tried.)  The
+// first attempt always uses the problem as given.  FindBestEmbedding fails
+// only if every attempt fails.  An attempt whose embedding lacks a chain for
+// any of the problem's variables counts as a failure.  If fep is nil,
+// NewFindEmbeddingParameters's defaults are used.
+func FindBestEmbedding(pr, adj Problem, fep *FindEmbeddingParameters, attempts int) (Embeddings, EmbeddingMetrics, error) {
+	// Use default parameters if none were provided.
+	if fep == nil {
+		fep = NewFindEmbeddingParameters()
+	}
+
+	// Prepare a random-number generator.
+	rng := newRand()
+	if fep.UseRandomSeed {
+		rng = rand.New(rand.NewSource(int64(fep.RandomSeed)))
+	}
+
+	// Find the set of variables in the problem.
+	vars := make([]int, 0, len(pr))
+	seen := make(map[int]bool, len(pr))
+	for _, pe := range pr {
+		for _, v := range [2]int{pe.I, pe.J} {
+			if !seen[v] {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+
+	// Try each reformulation in turn.
+	var best Embeddings
+	var bestM EmbeddingMetrics
+	var lastErr error
+	if attempts < 1 {
+		attempts = 1
+	}
+	for a := 0; a < attempts; a++ {
+		// Renumber the problem's variables and shuffle its
+		// coefficients.  toOrig maps new variable numbers back to
+		// original variable numbers.
+		p2 := pr
+		var toOrig map[int]int
+		if a > 0 {
+			perm := rng.Perm(len(vars))
+			toNew := make(map[int]int, len(vars))
+			toOrig = make(map[int]int, len(vars))
+			for i, v := range vars {
+				toNew[v] = vars[perm[i]]
+				toOrig[vars[perm[i]]] = v
+			}
+			p2 = make(Problem, len(pr))
+			for i, j := range rng.Perm(len(pr)) {
+				pe := pr[j]
+				p2[i] = ProblemEntry{I: toNew[pe.I], J: toNew[pe.J], Value: pe.Value}
+			}
+		}
+
+		// Embed the reformulated problem and map the result back to the
+		// original variable numbers.
+		emb, err := FindEmbedding(p2, adj, fep)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if toOrig != nil {
+			for q, v := range emb {
+				if v >= 0 {
+					emb[q] = toOrig[v]
+				}
+			}
+		}
+
+		// Reject embeddings that leave any variable without a chain.
+		chains := emb.Chains()
+		complete := true
+		for _, v := range vars {
+			if len(chains[v]) == 0 {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			lastErr = newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Embedding lacks a chain for some problem variable")
+			continue
+		}
+
+		// Retain the best embedding seen so far.
+		m := emb.Metrics()
+		if best == nil || m.Better(bestM) {
+			best, bestM = emb, m
+		}
+	}
+	if best == nil {
+		if lastErr == nil {
+			lastErr = newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Failed to find an embedding")
+		}
+		return nil, EmbeddingMetrics{}, lastErr
+	}
+	return best, bestM, nil
+}
@@ -76,6 +76,15 @@ func (emb Embeddings) toC() *C.sapi_Embeddings {
 // graph. This function is entirely heuristic: failure to return an embedding
 // does not prove that no embedding exists.
 func FindEmbedding(pr, adj Problem, fep *FindEmbeddingParameters) (Embeddings, error) {
+	// Seed the search from the package-wide source of randomness if the
+	// caller supplied one (see SetRandSource).
+	if seed, ok := randomSeed(); ok && !fep.UseRandomSeed {
+		f := *fep
+		f.UseRandomSeed = true
+		f.RandomSeed = seed
+		fep = &f
+	}
+
 	// Find an embedding.
 	cPr := pr.toC()
 	cAdj := adj.toC()
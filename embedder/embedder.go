@@ -0,0 +1,574 @@
+// Package embedder provides a heuristic minor embedder written entirely in
+// Go.  It follows the approach of Cai, Macready, and Roy ("A practical
+// heuristic for finding graph minors", 2014), on which SAPI's own
+// FindEmbedding is based: each source variable is placed as a chain of
+// target nodes routed along weighted shortest paths to its neighbors'
+// chains, and chains are repeatedly torn up and rerouted, with a growing
+// penalty for target nodes shared by several chains, until no two chains
+// overlap.
+//
+// Because the package does not depend on the SAPI library, it can embed
+// problems on machines where that library is unavailable.  Graphs are
+// represented as adjacency lists keyed by node number.  (Programs that use
+// the sapi package can instead call sapi.FindEmbeddingNative, which accepts
+// Problems.)  Independent attempts run concurrently, and every search honors
+// a context.Context for cancellation.
+package embedder
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// ErrNoEmbedding is returned when no attempt produced a valid embedding.
+var ErrNoEmbedding = errors.New("Failed to find an embedding")
+
+// A Graph is an adjacency list: each node maps to its neighbors.  Edges need
+// be listed in only one direction, and self-loops are ignored.  Nodes with
+// no neighbors must still appear as keys.
+type Graph map[int][]int
+
+// Params control the embedding search.  The zero value selects reasonable
+// defaults.
+type Params struct {
+	Tries            int   // Number of independent attempts (0 = 10)
+	MaxNoImprovement int   // Rounds without improvement before an attempt ends (0 = 10)
+	Workers          int   // Number of attempts to run concurrently (0 = GOMAXPROCS)
+	Seed             int64 // Seed for the random-number generator
+}
+
+// An indexedGraph is a Graph whose nodes are renumbered from 0 to N-1.
+type indexedGraph struct {
+	nodes []int   // Original number of each node
+	adj   [][]int // Neighbors of each node, by index
+}
+
+// index renumbers a Graph's nodes densely, preserving their order, and
+// symmetrizes its edges.
+func (g Graph) index() *indexedGraph {
+	// Collect and number the nodes.
+	seen := make(map[int]bool, len(g))
+	for u, ns := range g {
+		seen[u] = true
+		for _, v := range ns {
+			seen[v] = true
+		}
+	}
+	ig := &indexedGraph{nodes: make([]int, 0, len(seen))}
+	for u := range seen {
+		ig.nodes = append(ig.nodes, u)
+	}
+	sort.Ints(ig.nodes)
+	num := make(map[int]int, len(ig.nodes))
+	for i, u := range ig.nodes {
+		num[u] = i
+	}
+
+	// Construct a symmetric, duplicate-free adjacency list.
+	sets := make([]map[int]bool, len(ig.nodes))
+	for i := range sets {
+		sets[i] = make(map[int]bool)
+	}
+	for u, ns := range g {
+		for _, v := range ns {
+			if u != v {
+				sets[num[u]][num[v]] = true
+				sets[num[v]][num[u]] = true
+			}
+		}
+	}
+	ig.adj = make([][]int, len(ig.nodes))
+	for i, s := range sets {
+		for j := range s {
+			ig.adj[i] = append(ig.adj[i], j)
+		}
+		sort.Ints(ig.adj[i])
+	}
+	return ig
+}
+
+// maxExponent bounds the exponent used when weighting shared target nodes.
+const maxExponent = 16
+
+// A search holds the state of a single embedding attempt.
+type search struct {
+	src      *indexedGraph // Source graph
+	tgt      *indexedGraph // Target graph
+	chains   [][]int       // Target nodes representing each source node
+	usage    []int         // Number of chains containing each target node
+	weight   []float64     // Scratch space for node weights
+	hist     []float64     // Accumulated congestion of each target node
+	alpha    float64       // Base of the exponential penalty for shared nodes
+	maxAlpha float64       // Limit on alpha
+	rng      *rand.Rand    // Source of randomness
+}
+
+// A distItem is an entry in the priority queue used by shortestPaths.
+type distItem struct {
+	node int
+	dist float64
+}
+
+// distHeap is a min-heap of distItems.
+type distHeap []distItem
+
+func (h distHeap) Len() int            { return len(h) }
+func (h distHeap) Less(i, j int) bool  { return h[i].dist < h[j].dist }
+func (h distHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *distHeap) Push(x interface{}) { *h = append(*h, x.(distItem)) }
+func (h *distHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// shortestPaths computes, for every target node, the node-weighted distance
+// to a node adjacent to a given chain, counting the weights of every node on
+// the path, and the next node along that path.
+func (s *search) shortestPaths(chain []int) ([]float64, []int) {
+	nt := len(s.tgt.nodes)
+	dist := make([]float64, nt)
+	next := make([]int, nt)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		next[i] = -1
+	}
+	var h distHeap
+	for _, q := range chain {
+		for _, x := range s.tgt.adj[q] {
+			if w := s.weight[x]; w < dist[x] {
+				dist[x] = w
+				heap.Push(&h, distItem{node: x, dist: w})
+			}
+		}
+	}
+	for h.Len() > 0 {
+		it := heap.Pop(&h).(distItem)
+		if it.dist > dist[it.node] {
+			continue
+		}
+		for _, y := range s.tgt.adj[it.node] {
+			if d := it.dist + s.weight[y]; d < dist[y] {
+				dist[y] = d
+				next[y] = it.node
+				heap.Push(&h, distItem{node: y, dist: d})
+			}
+		}
+	}
+	return dist, next
+}
+
+// place tears up source node v's chain, if any, and reroutes it to every
+// embedded neighbor.  It returns false if some neighbor cannot be reached.
+func (s *search) place(v int) bool {
+	// Remove v's current chain and weight each target node exponentially in
+	// the number of other chains that use it.  The exponent is capped to
+	// keep weights finite.
+	for _, q := range s.chains[v] {
+		s.usage[q]--
+	}
+	s.chains[v] = nil
+	for q, u := range s.usage {
+		if u > maxExponent {
+			u = maxExponent
+		}
+		s.weight[q] = (1 + s.hist[q]) * math.Pow(s.alpha, float64(u))
+	}
+
+	// Compute distances from each embedded neighbor's chain.
+	var dists [][]float64
+	var nexts [][]int
+	for _, u := range s.src.adj[v] {
+		if len(s.chains[u]) == 0 {
+			continue
+		}
+		d, n := s.shortestPaths(s.chains[u])
+		dists = append(dists, d)
+		nexts = append(nexts, n)
+	}
+
+	// Choose the root that minimizes the total distance, breaking ties
+	// randomly.
+	nt := len(s.tgt.nodes)
+	root := -1
+	best := math.Inf(1)
+	start := s.rng.Intn(nt)
+	for i := 0; i < nt; i++ {
+		q := (start + i) % nt
+		cost := s.weight[q]
+		for _, d := range dists {
+			cost += d[q] - s.weight[q]
+		}
+		if cost < best {
+			best, root = cost, q
+		}
+	}
+	if root == -1 {
+		return false
+	}
+
+	// Form the chain from the root and the paths to each neighbor.
+	in := map[int]bool{root: true}
+	chain := []int{root}
+	for _, next := range nexts {
+		for q := next[root]; q != -1; q = next[q] {
+			if !in[q] {
+				in[q] = true
+				chain = append(chain, q)
+			}
+		}
+	}
+	chain = s.trim(v, chain)
+	for _, q := range chain {
+		s.usage[q]++
+	}
+	s.chains[v] = chain
+	return true
+}
+
+// trim repeatedly removes from source node v's chain any leaf that is not
+// needed to keep the chain adjacent to every embedded neighbor's chain.
+func (s *search) trim(v int, chain []int) []int {
+	// Record which chain each target node belongs to, for v's neighbors.
+	owners := make(map[int][]int)
+	for _, u := range s.src.adj[v] {
+		for _, q := range s.chains[u] {
+			owners[q] = append(owners[q], u)
+		}
+	}
+	in := make(map[int]bool, len(chain))
+	for _, q := range chain {
+		in[q] = true
+	}
+
+	// touches returns the number of chain nodes adjacent to neighbor u.
+	touches := func(u int) int {
+		n := 0
+		for _, q := range chain {
+			if !in[q] {
+				continue
+			}
+			for _, x := range s.tgt.adj[q] {
+				if containsInt(owners[x], u) {
+					n++
+					break
+				}
+			}
+		}
+		return n
+	}
+
+	// Remove unneeded leaves until none remain.
+	for removed := true; removed && len(in) > 1; {
+		removed = false
+		for _, q := range chain {
+			if !in[q] {
+				continue
+			}
+			deg := 0
+			for _, x := range s.tgt.adj[q] {
+				if in[x] {
+					deg++
+				}
+			}
+			if deg > 1 {
+				continue
+			}
+			needed := false
+			for _, x := range s.tgt.adj[q] {
+				for _, u := range owners[x] {
+					if touches(u) == 1 {
+						needed = true
+					}
+				}
+			}
+			if !needed {
+				delete(in, q)
+				removed = true
+				if len(in) == 1 {
+					break
+				}
+			}
+		}
+	}
+	trimmed := chain[:0]
+	for _, q := range chain {
+		if in[q] {
+			trimmed = append(trimmed, q)
+		}
+	}
+	return trimmed
+}
+
+// containsInt reports whether a slice contains a given value.
+func containsInt(xs []int, x int) bool {
+	for _, y := range xs {
+		if y == x {
+			return true
+		}
+	}
+	return false
+}
+
+// overlap returns the total number of excess chain memberships across all
+// target nodes.
+func (s *search) overlap() int {
+	n := 0
+	for _, u := range s.usage {
+		if u > 1 {
+			n += u - 1
+		}
+	}
+	return n
+}
+
+// quality summarizes a valid embedding for comparison.  Embeddings with a
+// shorter longest chain and, as a tie breaker, fewer target nodes are
+// better.
+type quality struct {
+	maxLen int
+	total  int
+}
+
+// better says whether one quality is preferable to another.
+func (a quality) better(b quality) bool {
+	if a.maxLen != b.maxLen {
+		return a.maxLen < b.maxLen
+	}
+	return a.total < b.total
+}
+
+// measure computes the quality of the current chains.
+func (s *search) measure() quality {
+	var qu quality
+	for _, ch := range s.chains {
+		qu.total += len(ch)
+		if len(ch) > qu.maxLen {
+			qu.maxLen = len(ch)
+		}
+	}
+	return qu
+}
+
+// run performs a single embedding attempt.  It returns the best valid set of
+// chains it found, or nil.
+func (s *search) run(ctx context.Context, maxNoImprovement int) ([][]int, quality) {
+	// Place every source node in a random order.
+	n := len(s.src.nodes)
+	for _, v := range s.rng.Perm(n) {
+		if !s.place(v) {
+			return nil, quality{}
+		}
+	}
+
+	// Repeatedly reroute every chain until we stop making progress.
+	var best [][]int
+	var bestQ quality
+	lastOverlap := math.MaxInt32
+	for noImprove := 0; noImprove < maxNoImprovement; {
+		if ol := s.overlap(); ol == 0 {
+			if qu := s.measure(); best == nil || qu.better(bestQ) {
+				best = make([][]int, n)
+				for v, ch := range s.chains {
+					best[v] = append([]int(nil), ch...)
+				}
+				bestQ = qu
+				noImprove = 0
+			} else {
+				noImprove++
+			}
+		} else if ol < lastOverlap {
+			lastOverlap = ol
+			noImprove = 0
+		} else {
+			noImprove++
+		}
+
+		// Make shared nodes more expensive, both now and, for nodes that
+		// remain contested, in every later round.
+		s.alpha = math.Min(2*s.alpha, s.maxAlpha)
+		for q, u := range s.usage {
+			if u > 1 {
+				s.hist[q]++
+			}
+		}
+
+		// Reroute every chain in a random order.
+		for _, v := range s.rng.Perm(n) {
+			if ctx.Err() != nil {
+				return best, bestQ
+			}
+			if !s.place(v) {
+				return best, bestQ
+			}
+		}
+	}
+	return best, bestQ
+}
+
+// Find embeds a source graph in a target graph.  It returns a map from each
+// source node to the target nodes that represent it (its chain).  Chains are
+// disjoint and connected, and every source edge is represented by at least
+// one target edge between the corresponding chains.  Find returns the best
+// embedding found by any attempt, as measured by the length of the longest
+// chain and then by the total number of target nodes used.  If ctx is
+// canceled, Find returns the best embedding found so far or, if none, the
+// context's error.  If no attempt succeeds, Find returns ErrNoEmbedding.
+func Find(ctx context.Context, source, target Graph, p *Params) (map[int][]int, error) {
+	// Fill in default parameters.
+	var prm Params
+	if p != nil {
+		prm = *p
+	}
+	if prm.Tries <= 0 {
+		prm.Tries = 10
+	}
+	if prm.MaxNoImprovement <= 0 {
+		prm.MaxNoImprovement = 10
+	}
+	if prm.Workers <= 0 {
+		prm.Workers = runtime.GOMAXPROCS(0)
+	}
+	src := source.index()
+	tgt := target.index()
+	if len(src.nodes) == 0 {
+		return map[int][]int{}, nil
+	}
+	if len(src.nodes) > len(tgt.nodes) {
+		return nil, fmt.Errorf("The source graph has %d nodes, which exceeds the target graph's %d", len(src.nodes), len(tgt.nodes))
+	}
+
+	// Run the attempts on a pool of workers.  Each attempt is seeded
+	// independently, so the result does not depend on scheduling.
+	type result struct {
+		chains [][]int
+		qu     quality
+	}
+	results := make([]result, prm.Tries)
+	tries := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < prm.Workers && w < prm.Tries; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tries {
+				s := &search{
+					src:      src,
+					tgt:      tgt,
+					chains:   make([][]int, len(src.nodes)),
+					usage:    make([]int, len(tgt.nodes)),
+					weight:   make([]float64, len(tgt.nodes)),
+					hist:     make([]float64, len(tgt.nodes)),
+					alpha:    2,
+					maxAlpha: float64(len(tgt.nodes)),
+					rng:      rand.New(rand.NewSource(prm.Seed + int64(t))),
+				}
+				results[t].chains, results[t].qu = s.run(ctx, prm.MaxNoImprovement)
+			}
+		}()
+	}
+	for t := 0; t < prm.Tries && ctx.Err() == nil; t++ {
+		tries <- t
+	}
+	close(tries)
+	wg.Wait()
+
+	// Return the best embedding in terms of the original node numbers.
+	var best *result
+	for t := range results {
+		r := &results[t]
+		if r.chains != nil && (best == nil || r.qu.better(best.qu)) {
+			best = r
+		}
+	}
+	if best == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNoEmbedding
+	}
+	emb := make(map[int][]int, len(best.chains))
+	for v, ch := range best.chains {
+		qs := make([]int, len(ch))
+		for i, q := range ch {
+			qs[i] = tgt.nodes[q]
+		}
+		sort.Ints(qs)
+		emb[src.nodes[v]] = qs
+	}
+	return emb, nil
+}
+
+// Verify checks that an embedding of a source graph in a target graph is
+// valid: every source node has a nonempty, connected chain; no target node
+// belongs to more than one chain; and every source edge is represented by
+// at least one target edge between the corresponding chains.
+func Verify(source, target Graph, emb map[int][]int) error {
+	// Index the target edges.
+	edges := make(map[[2]int]bool)
+	for u, ns := range target {
+		for _, v := range ns {
+			edges[[2]int{u, v}] = true
+			edges[[2]int{v, u}] = true
+		}
+	}
+
+	// Check each chain.
+	owner := make(map[int]int)
+	for _, v := range source.index().nodes {
+		ch := emb[v]
+		if len(ch) == 0 {
+			return fmt.Errorf("Source node %d has an empty chain", v)
+		}
+		for _, q := range ch {
+			if u, ok := owner[q]; ok {
+				return fmt.Errorf("Target node %d belongs to the chains of both %d and %d", q, u, v)
+			}
+			owner[q] = v
+		}
+
+		// Flood-fill the chain from its first node.
+		reached := map[int]bool{ch[0]: true}
+		stack := []int{ch[0]}
+		for len(stack) > 0 {
+			q := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, r := range ch {
+				if !reached[r] && edges[[2]int{q, r}] {
+					reached[r] = true
+					stack = append(stack, r)
+				}
+			}
+		}
+		if len(reached) != len(ch) {
+			return fmt.Errorf("The chain for source node %d is not connected", v)
+		}
+	}
+
+	// Check each source edge.
+	for u, ns := range source {
+		for _, v := range ns {
+			if u == v {
+				continue
+			}
+			found := false
+			for _, q := range emb[u] {
+				for _, r := range emb[v] {
+					found = found || edges[[2]int{q, r}]
+				}
+			}
+			if !found {
+				return fmt.Errorf("Source edge {%d, %d} is not represented in the target graph", u, v)
+			}
+		}
+	}
+	return nil
+}
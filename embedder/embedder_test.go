@@ -0,0 +1,91 @@
+// This file tests the heuristic embedder.
+
+package embedder_test
+
+import (
+	"context"
+	"github.com/lanl/sapi/embedder"
+	"testing"
+)
+
+// grid returns an n×n grid graph with diagonals (a "king's graph").
+func grid(n int) embedder.Graph {
+	g := make(embedder.Graph)
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			v := r*n + c
+			g[v] = nil
+			for _, d := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+				rr, cc := r+d[0], c+d[1]
+				if rr < n && cc >= 0 && cc < n {
+					g[v] = append(g[v], rr*n+cc)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// complete returns a complete graph on n nodes.
+func complete(n int) embedder.Graph {
+	g := make(embedder.Graph)
+	for u := 0; u < n; u++ {
+		g[u] = nil
+		for v := u + 1; v < n; v++ {
+			g[u] = append(g[u], v)
+		}
+	}
+	return g
+}
+
+// TestFind ensures that complete graphs can be embedded in a king's graph
+// and that the results are valid.
+func TestFind(t *testing.T) {
+	target := grid(6)
+	for n := 2; n <= 6; n++ {
+		source := complete(n)
+		emb, err := embedder.Find(context.Background(), source, target, &embedder.Params{Seed: int64(n)})
+		if err != nil {
+			t.Fatalf("K%d: %v", n, err)
+		}
+		if err := embedder.Verify(source, target, emb); err != nil {
+			t.Fatalf("K%d: %v", n, err)
+		}
+	}
+}
+
+// TestFindErrors ensures that impossible and canceled searches fail.
+func TestFindErrors(t *testing.T) {
+	// A triangle cannot be embedded in a path.
+	path := embedder.Graph{0: {1}, 1: {2}, 2: {3}}
+	if _, err := embedder.Find(context.Background(), complete(3), path, nil); err != embedder.ErrNoEmbedding {
+		t.Fatalf("Expected ErrNoEmbedding but saw %v", err)
+	}
+
+	// A canceled search returns the context's error.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := embedder.Find(ctx, complete(4), grid(4), nil); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled but saw %v", err)
+	}
+}
+
+// TestVerify ensures that Verify rejects invalid embeddings.
+func TestVerify(t *testing.T) {
+	source := embedder.Graph{0: {1}}
+	target := embedder.Graph{0: {1}, 1: {2}}
+	bad := []map[int][]int{
+		{0: {0}},            // Missing chain
+		{0: {0}, 1: {0}},    // Overlapping chains
+		{0: {0, 2}, 1: {1}}, // Disconnected chain
+		{0: {0}, 1: {2}},    // Unrepresented edge
+	}
+	for i, emb := range bad {
+		if embedder.Verify(source, target, emb) == nil {
+			t.Fatalf("Invalid embedding %d (%v) was accepted", i, emb)
+		}
+	}
+	if err := embedder.Verify(source, target, map[int][]int{0: {0}, 1: {1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+}
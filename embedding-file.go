@@ -0,0 +1,318 @@
+// This file provides JSON and binary serializations of embeddings so that
+// embeddings can be computed once and reused across program runs.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// EmbeddingFormat identifies the version of the JSON embedding format
+// written by MarshalEmbedding.
+const EmbeddingFormat = "sapi-embedding/1"
+
+// embeddingMagic begins every binary-encoded embedding.
+const embeddingMagic = "SAPIEMB1"
+
+// maxEmbeddingQubits bounds the number of qubits a decoded embedding may
+// claim.  It is far larger than any solver but keeps corrupt input from
+// demanding an enormous allocation.
+const maxEmbeddingQubits = 1 << 24
+
+// An EmbeddingDocument is an embedding plus the information needed to decide
+// whether it can be reused.
+//
+// MarshalEmbedding encodes an EmbeddingDocument as a JSON object of the
+// following form:
+//
+//	{
+//	  "format": "sapi-embedding/1",
+//	  "problem_hash": "9f86d0…",
+//	  "topology": "3c2a7e…",
+//	  "num_qubits": 2048,
+//	  "chains": [[0, 4], [1], [5, 12]],
+//	  "metadata": {"topology": "chimera(16,16,4)"}
+//	}
+//
+// "format" is always EmbeddingFormat.  "problem_hash" and "topology" are the
+// graph hashes (see Problem.GraphHash) of the logical problem and of the
+// solver's working graph, respectively, so an embedding is reused for any
+// problem with the same structure on any solver with the same working
+// graph.  "chains" lists, for each logical variable in turn, the sorted
+// qubits that represent it; a variable with no qubits has an empty list.
+// "metadata" is omitted if empty.
+type EmbeddingDocument struct {
+	Embedding   Embeddings        // The embedding itself
+	ProblemHash string            // Graph hash of the embedded problem (see Problem.GraphHash)
+	Topology    string            // Graph hash of the solver's working graph
+	Metadata    map[string]string // Arbitrary, user-defined information
+}
+
+// NewEmbeddingDocument returns an EmbeddingDocument for an embedding of a
+// given problem in a given working graph (as returned by
+// HardwareAdjacency).
+func NewEmbeddingDocument(emb Embeddings, p, adj Problem) *EmbeddingDocument {
+	return &EmbeddingDocument{
+		Embedding:   emb,
+		ProblemHash: p.GraphHash(),
+		Topology:    adj.GraphHash(),
+	}
+}
+
+// Matches reports whether an EmbeddingDocument was produced for a problem
+// with the same structure as a given problem and for a given working graph
+// and can therefore be reused for them.
+func (doc *EmbeddingDocument) Matches(p, adj Problem) bool {
+	return doc.Topology == adj.GraphHash() && doc.ProblemHash == p.GraphHash()
+}
+
+// String returns a human-readable identifier for a topology, such as
+// "chimera(16,16,4)", suitable for recording in an EmbeddingDocument's
+// Metadata.
+func (ts TopologySpec) String() string {
+	return fmt.Sprintf("%s(%d,%d,%d)", ts.Family, ts.M, ts.N, ts.L)
+}
+
+// embeddingDocumentJSON is the JSON representation of an EmbeddingDocument.
+type embeddingDocumentJSON struct {
+	Format      string            `json:"format"`
+	ProblemHash string            `json:"problem_hash"`
+	Topology    string            `json:"topology"`
+	NumQubits   int               `json:"num_qubits"`
+	Chains      [][]int           `json:"chains"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// chainList converts an Embeddings to a list of chains indexed by logical
+// variable.
+func (emb Embeddings) chainList() [][]int {
+	nv := 0
+	for _, v := range emb {
+		if v+1 > nv {
+			nv = v + 1
+		}
+	}
+	chains := make([][]int, nv)
+	for v := range chains {
+		chains[v] = []int{}
+	}
+	for q, v := range emb {
+		if v >= 0 {
+			chains[v] = append(chains[v], q)
+		}
+	}
+	return chains
+}
+
+// embeddingFromChains converts a list of chains indexed by logical variable
+// to an Embeddings over a given number of qubits.
+func embeddingFromChains(chains [][]int, nq int) (Embeddings, error) {
+	if nq < 0 || nq > maxEmbeddingQubits {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An embedding cannot contain %d qubits", nq)
+	}
+	emb := make(Embeddings, nq)
+	for q := range emb {
+		emb[q] = -1
+	}
+	for v, ch := range chains {
+		for _, q := range ch {
+			if q < 0 || q >= nq {
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Qubit %d in the chain for variable %d is out of range", q, v)
+			}
+			if emb[q] != -1 {
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Qubit %d appears in the chains for both variable %d and variable %d", q, emb[q], v)
+			}
+			emb[q] = v
+		}
+	}
+	return emb, nil
+}
+
+// MarshalEmbedding encodes an EmbeddingDocument as JSON.
+func MarshalEmbedding(doc *EmbeddingDocument) ([]byte, error) {
+	return json.Marshal(embeddingDocumentJSON{
+		Format:      EmbeddingFormat,
+		ProblemHash: doc.ProblemHash,
+		Topology:    doc.Topology,
+		NumQubits:   len(doc.Embedding),
+		Chains:      doc.Embedding.chainList(),
+		Metadata:    doc.Metadata,
+	})
+}
+
+// UnmarshalEmbedding decodes an EmbeddingDocument produced by
+// MarshalEmbedding.
+func UnmarshalEmbedding(data []byte) (*EmbeddingDocument, error) {
+	var ej embeddingDocumentJSON
+	if err := json.Unmarshal(data, &ej); err != nil {
+		return nil, err
+	}
+	if ej.Format != EmbeddingFormat {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unsupported embedding format %q", ej.Format)
+	}
+	emb, err := embeddingFromChains(ej.Chains, ej.NumQubits)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddingDocument{
+		Embedding:   emb,
+		ProblemHash: ej.ProblemHash,
+		Topology:    ej.Topology,
+		Metadata:    ej.Metadata,
+	}, nil
+}
+
+// WriteEmbedding writes an EmbeddingDocument in a compact binary format.
+// The format consists of the string "SAPIEMB1" followed by a sequence of
+// unsigned varints (see encoding/binary): the problem hash, topology, and
+// each metadata key and value, in sorted order of key, as length-prefixed
+// strings preceded by the number of metadata entries; the number of qubits;
+// the number of chains; and, for each chain, its length followed by its
+// sorted qubits, each encoded as the difference from its predecessor.
+func WriteEmbedding(w io.Writer, doc *EmbeddingDocument) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	putUint := func(x int) {
+		n := binary.PutUvarint(buf, uint64(x))
+		bw.Write(buf[:n])
+	}
+	putString := func(s string) {
+		putUint(len(s))
+		bw.WriteString(s)
+	}
+
+	// Write the header.
+	bw.WriteString(embeddingMagic)
+	putString(doc.ProblemHash)
+	putString(doc.Topology)
+	keys := make([]string, 0, len(doc.Metadata))
+	for k := range doc.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	putUint(len(keys))
+	for _, k := range keys {
+		putString(k)
+		putString(doc.Metadata[k])
+	}
+
+	// Write the chains.
+	chains := doc.Embedding.chainList()
+	putUint(len(doc.Embedding))
+	putUint(len(chains))
+	for _, ch := range chains {
+		putUint(len(ch))
+		prev := 0
+		for _, q := range ch {
+			putUint(q - prev)
+			prev = q
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadEmbedding reads an EmbeddingDocument written by WriteEmbedding.
+func ReadEmbedding(r io.Reader) (*EmbeddingDocument, error) {
+	br := bufio.NewReader(r)
+	getUint := func() (int, error) {
+		x, err := binary.ReadUvarint(br)
+		if err == nil && x > 1<<31 {
+			err = newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Embedding value %d is out of range", x)
+		}
+		return int(x), err
+	}
+	getString := func() (string, error) {
+		// Copy rather than preallocate so that a corrupt length cannot
+		// demand more memory than the input actually contains.
+		n, err := getUint()
+		if err != nil {
+			return "", err
+		}
+		var b bytes.Buffer
+		if _, err = io.CopyN(&b, br, int64(n)); err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return b.String(), err
+	}
+
+	// Read the header.
+	magic := make([]byte, len(embeddingMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != embeddingMagic {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Input is not a binary-encoded embedding")
+	}
+	doc := &EmbeddingDocument{}
+	var err error
+	if doc.ProblemHash, err = getString(); err != nil {
+		return nil, err
+	}
+	if doc.Topology, err = getString(); err != nil {
+		return nil, err
+	}
+	nm, err := getUint()
+	if err != nil {
+		return nil, err
+	}
+	if nm > 0 {
+		doc.Metadata = make(map[string]string)
+	}
+	for i := 0; i < nm; i++ {
+		k, err := getString()
+		if err != nil {
+			return nil, err
+		}
+		if doc.Metadata[k], err = getString(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the chains.  Counts are not trusted to size allocations; the
+	// chains grow only as their qubits are actually read.
+	nq, err := getUint()
+	if err != nil {
+		return nil, err
+	}
+	if nq > maxEmbeddingQubits {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An embedding cannot contain %d qubits", nq)
+	}
+	nc, err := getUint()
+	if err != nil {
+		return nil, err
+	}
+	var chains [][]int
+	for v := 0; v < nc; v++ {
+		n, err := getUint()
+		if err != nil {
+			return nil, err
+		}
+		var ch []int
+		q := 0
+		for i := 0; i < n; i++ {
+			d, err := getUint()
+			if err != nil {
+				return nil, err
+			}
+			q += d
+			ch = append(ch, q)
+		}
+		chains = append(chains, ch)
+	}
+	if doc.Embedding, err = embeddingFromChains(chains, nq); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,89 @@
+// This file provides efficient computation of the energies of many
+// solutions to the same problem.
+
+package sapi
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minParallelEnergies is the minimum number of solutions for which Energies
+// evaluates solutions in parallel.
+const minParallelEnergies = 256
+
+// An energyIndex is a compact representation of a problem, with duplicate
+// entries merged, that is suitable for evaluating many solutions.
+type energyIndex struct {
+	lin  []int     // Variable number of each linear term
+	h    []float64 // Coefficient of each linear term
+	quad [][2]int  // Variable numbers of each quadratic term
+	J    []float64 // Coefficient of each quadratic term
+}
+
+// newEnergyIndex constructs an energyIndex from a problem.
+func newEnergyIndex(p Problem) *energyIndex {
+	cp := p.Canonicalize()
+	ei := &energyIndex{}
+	for _, pe := range cp {
+		if pe.I == pe.J {
+			ei.lin = append(ei.lin, pe.I)
+			ei.h = append(ei.h, pe.Value)
+		} else {
+			ei.quad = append(ei.quad, [2]int{pe.I, pe.J})
+			ei.J = append(ei.J, pe.Value)
+		}
+	}
+	return ei
+}
+
+// energy computes the energy of a single solution.
+func (ei *energyIndex) energy(soln []int8) float64 {
+	e := 0.0
+	for k, v := range ei.lin {
+		e += ei.h[k] * float64(soln[v])
+	}
+	for k, ij := range ei.quad {
+		e += ei.J[k] * float64(soln[ij[0]]*soln[ij[1]])
+	}
+	return e
+}
+
+// Energies returns the energy of each of a list of solutions to a problem.
+// As with IsingEnergy and QuboEnergy, solutions containing ±1 values are
+// evaluated as Ising-model solutions and solutions containing 0/1 values are
+// evaluated as QUBO solutions.  Energies is faster than repeated calls to
+// IsingEnergy or QuboEnergy when the number of solutions is large.
+func (p Problem) Energies(solns [][]int8) []float64 {
+	ei := newEnergyIndex(p)
+	es := make([]float64, len(solns))
+
+	// Evaluate small lists of solutions serially.
+	nw := runtime.GOMAXPROCS(0)
+	if len(solns) < minParallelEnergies || nw < 2 {
+		for i, soln := range solns {
+			es[i] = ei.energy(soln)
+		}
+		return es
+	}
+
+	// Evaluate large lists of solutions in parallel, assigning each worker
+	// a contiguous range of solutions.
+	var wg sync.WaitGroup
+	chunk := (len(solns) + nw - 1) / nw
+	for lo := 0; lo < len(solns); lo += chunk {
+		hi := lo + chunk
+		if hi > len(solns) {
+			hi = len(solns)
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				es[i] = ei.energy(solns[i])
+			}
+		}(lo, hi)
+	}
+	wg.Wait()
+	return es
+}
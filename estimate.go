@@ -0,0 +1,119 @@
+// This file provides a means of estimating how much QPU time a problem will
+// consume before it is submitted.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"time"
+)
+
+// A TimingModel describes the fixed costs of using a QPU.  Costs that depend
+// on the solver parameters (annealing time and thermalization times) are
+// taken from the parameters themselves.
+type TimingModel struct {
+	Programming      time.Duration // Time to program the QPU once
+	ReadoutPerSample time.Duration // Time to read out one sample
+	DelayPerSample   time.Duration // Additional per-sample delay
+}
+
+// DefaultTimingModel provides typical timings for D-Wave 2X-class hardware.
+// libdwave_sapi does not report these as solver properties, so they are only
+// rough guides.  Use Solver.TimingModel or TimingModelFromResult for a model
+// calibrated against a particular solver.
+var DefaultTimingModel = TimingModel{
+	Programming:      9 * time.Millisecond,
+	ReadoutPerSample: 123 * time.Microsecond,
+	DelayPerSample:   21 * time.Microsecond,
+}
+
+// TimingModelFromResult derives a TimingModel from the timing breakdown of a
+// previously solved problem.
+func TimingModelFromResult(t Timing) TimingModel {
+	return TimingModel{
+		Programming:      t.QpuProgrammingTime,
+		ReadoutPerSample: t.QpuReadoutTimePerSample,
+		DelayPerSample:   t.QpuDelayTimePerSample,
+	}
+}
+
+// timingModelFromProperties derives a TimingModel from a solver's advertised
+// timing properties.
+func timingModelFromProperties(pt *ProblemTimingProperties) TimingModel {
+	us := func(t float64) time.Duration {
+		return time.Duration(t * float64(time.Microsecond))
+	}
+	return TimingModel{
+		Programming:      us(pt.ProgrammingTime),
+		ReadoutPerSample: us(pt.ReadoutTimePerSample),
+		DelayPerSample:   us(pt.DelayTimePerSample),
+	}
+}
+
+// TimingModel returns the best available TimingModel for a solver.  This is
+// derived from the timing breakdown of the solver's most recent QPU result
+// if there is one, otherwise from the solver's ProblemTiming property if the
+// solver advertises one, and otherwise is DefaultTimingModel.
+func (s *Solver) TimingModel() TimingModel {
+	s.timingMu.Lock()
+	t := s.timing
+	s.timingMu.Unlock()
+	if t != nil {
+		return TimingModelFromResult(*t)
+	}
+	if pt := s.Properties().ProblemTiming; pt != nil {
+		return timingModelFromProperties(pt)
+	}
+	return DefaultTimingModel
+}
+
+// recordTiming remembers the timing breakdown of a successful result that
+// consumed QPU time for use by TimingModel.  It returns its arguments
+// unmodified.
+func (s *Solver) recordTiming(res IsingResult, err error) (IsingResult, error) {
+	if err == nil && res.Timing.QpuProgrammingTime > 0 {
+		t := res.Timing
+		s.timingMu.Lock()
+		s.timing = &t
+		s.timingMu.Unlock()
+	}
+	return res, err
+}
+
+// Estimate returns the QPU access time a TimingModel predicts for a set of
+// quantum solver parameters.  The QPU is programmed once per spin-reversal
+// transformation (or once if there are none) and then annealed and read out
+// NumReads times.  Each anneal lasts AnnealingTime or, if an anneal schedule
+// is set, until the schedule's final point.
+func (tm TimingModel) Estimate(qsp *QuantumSolverParameters) time.Duration {
+	nProg := qsp.NumSpinReversals
+	if nProg < 1 {
+		nProg = 1
+	}
+	anneal := time.Duration(qsp.AnnealingTime) * time.Microsecond
+	if n := len(qsp.AnnealSchedule); n > 0 {
+		anneal = time.Duration(qsp.AnnealSchedule[n-1].Time * float64(time.Microsecond))
+	}
+	perSample := anneal +
+		time.Duration(qsp.ReadoutTherm)*time.Microsecond +
+		tm.ReadoutPerSample + tm.DelayPerSample
+	perProg := tm.Programming + time.Duration(qsp.ProgTherm)*time.Microsecond
+	return time.Duration(nProg)*perProg + time.Duration(qsp.NumReads)*perSample
+}
+
+// EstimateAccessTime predicts, using the solver's TimingModel, how much QPU
+// access time solving a problem with a given set of parameters will consume.  This
+// lets users budget their QPU allocation before submitting.  It fails if the
+// parameters are not quantum solver parameters.
+func (s *Solver) EstimateAccessTime(sp SolverParameters) (time.Duration, error) {
+	qsp, ok := sp.(*QuantumSolverParameters)
+	if !ok {
+		return 0, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Solver %s does not consume QPU time", s.Name)
+	}
+	return s.TimingModel().Estimate(qsp), nil
+}
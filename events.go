@@ -0,0 +1,94 @@
+// This file lets callers observe the lifecycle of the problems submitted via a
+// Connection.
+
+package sapi
+
+import (
+	"sync"
+	"time"
+)
+
+// An EventKind indicates what happened to a problem.
+type EventKind int
+
+// These are the kinds of events a Connection can report.
+const (
+	EventSubmitted    EventKind = iota // Problem was submitted to a solver
+	EventStateChanged                  // Problem's SubmittedState changed
+	EventCompleted                     // Problem completed (successfully or not)
+	EventCanceled                      // Problem was canceled by the client
+	EventGraphChanged                  // Solver's working graph changed (see RefreshProperties)
+)
+
+// String returns a textual representation of an EventKind.
+func (k EventKind) String() string {
+	switch k {
+	case EventSubmitted:
+		return "submitted"
+	case EventStateChanged:
+		return "state changed"
+	case EventCompleted:
+		return "completed"
+	case EventCanceled:
+		return "canceled"
+	case EventGraphChanged:
+		return "graph changed"
+	default:
+		return "unknown"
+	}
+}
+
+// An Event describes a change in the lifecycle of a problem or, for
+// EventGraphChanged, a change to a solver.
+type Event struct {
+	Kind    EventKind         // What happened
+	Time    time.Time         // When it happened (as observed by the client)
+	Solver  *Solver           // Solver to which the problem was submitted or whose graph changed
+	Problem *SubmittedProblem // Asynchronously submitted problem or nil for a synchronous solve
+	Status  *ProblemStatus    // Most recently observed status (EventStateChanged only)
+	Err     error             // Error encountered by a completed problem, if any
+}
+
+// An eventHandlers is a thread-safe list of callbacks.
+type eventHandlers struct {
+	sync.RWMutex
+	fs []func(Event)
+}
+
+// OnEvent registers a function to call on every lifecycle event of every
+// problem submitted via a Connection.  Multiple functions can be registered;
+// they are called in order of registration.  Because the underlying SAPI
+// library does not push updates, state changes are detected only when a
+// SubmittedProblem is queried (by Status, Done, AwaitCompletion, or Result).
+func (c *Connection) OnEvent(f func(Event)) {
+	c.events.Lock()
+	c.events.fs = append(c.events.fs, f)
+	c.events.Unlock()
+}
+
+// emit invokes all of a Connection's event handlers on a given Event.
+func (c *Connection) emit(ev Event) {
+	if c == nil {
+		return
+	}
+	c.events.RLock()
+	fs := c.events.fs
+	c.events.RUnlock()
+	if len(fs) == 0 {
+		return
+	}
+	ev.Time = time.Now()
+	for _, f := range fs {
+		f(ev)
+	}
+}
+
+// emit reports an Event about a problem submitted to a given solver.
+func (s *Solver) emit(kind EventKind, sp *SubmittedProblem, err error) {
+	s.Conn.emit(Event{
+		Kind:    kind,
+		Solver:  s,
+		Problem: sp,
+		Err:     err,
+	})
+}
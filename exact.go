@@ -0,0 +1,135 @@
+// This file provides a pure-Go solver that solves small problems exactly.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"sort"
+)
+
+// MaxExactVariables is the largest number of variables an ExactSolver will
+// accept.
+const MaxExactVariables = 24
+
+// An ExactSolver is a deterministic, pure-Go Sampler that solves a problem by
+// enumerating every assignment of its variables.  It is intended for small
+// problems, examples, and tests, as it requires no SAPI solver.  Solutions
+// are returned in order of increasing energy, ties being broken
+// lexicographically, and the number returned is limited by the parameters'
+// MaxAnswers field.
+type ExactSolver struct{}
+
+// maxAnswers returns the maximum number of answers specified by a set of
+// solver parameters or 0 if the parameters do not specify a maximum.
+func maxAnswers(sp SolverParameters) int {
+	switch sp := sp.(type) {
+	case *SwOptimizeSolverParameters:
+		return sp.MaxAnswers
+	case *SwSampleSolverParameters:
+		return sp.MaxAnswers
+	case *QuantumSolverParameters:
+		return sp.MaxAnswers
+	default:
+		return 0
+	}
+}
+
+// solve is the common code for SolveIsing and SolveQubo.
+func (ExactSolver) solve(p Problem, sp SolverParameters, lo, hi int8) (IsingResult, error) {
+	// Determine the set of variables in the problem.
+	seen := make(map[int]bool, len(p))
+	nv := 0
+	for _, pe := range p {
+		for _, v := range [2]int{pe.I, pe.J} {
+			seen[v] = true
+			if v+1 > nv {
+				nv = v + 1
+			}
+		}
+	}
+	vars := make([]int, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	if len(vars) > MaxExactVariables {
+		return IsingResult{}, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An exact solver accepts at most %d variables, not %d", MaxExactVariables, len(vars))
+	}
+
+	// Compute the energy of every assignment of values to variables.  Only
+	// the energies are retained; solutions are reconstructed below for just
+	// the answers that are returned.
+	n := 1 << uint(len(vars))
+	assign := func(soln []int8, k int) {
+		for b, v := range vars {
+			soln[v] = lo
+			if k&(1<<uint(len(vars)-1-b)) != 0 {
+				soln[v] = hi
+			}
+		}
+	}
+	newSoln := func() []int8 {
+		soln := make([]int8, nv)
+		for v := range soln {
+			soln[v] = 3
+		}
+		return soln
+	}
+	energies := make([]float64, n)
+	scratch := newSoln()
+	for k := range energies {
+		assign(scratch, k)
+		energies[k] = isingEnergy(p, scratch)
+	}
+
+	// Sort the solutions by energy.  Because assignments were enumerated in
+	// lexicographic order, a stable sort breaks ties lexicographically.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return energies[order[i]] < energies[order[j]] })
+	if ma := maxAnswers(sp); ma > 0 && ma < n {
+		order = order[:ma]
+	}
+	res := IsingResult{
+		Solutions:   make([][]int8, len(order)),
+		Energies:    make([]float64, len(order)),
+		Occurrences: make([]int, len(order)),
+		ScaleFactor: 1,
+	}
+	for i, o := range order {
+		res.Solutions[i] = newSoln()
+		assign(res.Solutions[i], o)
+		res.Energies[i] = energies[o]
+		res.Occurrences[i] = 1
+	}
+	return res, nil
+}
+
+// SolveIsing solves an Ising-model problem exactly.
+func (es ExactSolver) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	return es.solve(p, sp, -1, 1)
+}
+
+// SolveQubo solves a QUBO problem exactly.
+func (es ExactSolver) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	return es.solve(p, sp, 0, 1)
+}
+
+// NewSolverParameters returns a set of sw_optimize parameters, of which only
+// MaxAnswers is honored.
+func (ExactSolver) NewSolverParameters() SolverParameters {
+	return newSwOptimizeSolverParameters()
+}
+
+// Properties returns properties indicating support for Ising-model and QUBO
+// problems.
+func (ExactSolver) Properties() *SolverProperties {
+	return &SolverProperties{SupportedProblemTypes: []string{"ising", "qubo"}}
+}
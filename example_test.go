@@ -47,13 +47,32 @@ func ExampleRemoteConnection() {
 	_ = conn
 }
 
-// Specify solver-specific parameters.
+// Connect to a remote solver using explicitly provided connection parameters
+// instead of environment variables.
+func ExampleNewSolverFromConfig() {
+	solver, err := sapi.NewSolverFromConfig(sapi.Config{
+		URL:     "https://cloud.dwavesys.com/sapi",
+		Token:   "my-secret-token",
+		Solver:  "DW_2000Q_2",
+		Timeout: 10 * time.Minute,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Code to solve a problem with solver would normally appear here.
+	_ = solver
+}
+
+// Specify solver-specific parameters.  The example uses an ExactSolver, but
+// the same code works with any Sampler, including a Solver.
 func ExampleSolverParameters() {
 	// Set the number of reads to 1000.  In the case of
 	// sapi.QuantumSolverParameters, also enable autoscaling.  Note that
 	// sapi.SwHeuristicSolverParameters doesn't accept either of those
 	// parameters so a case for that type is not included in the following.
-	sp := solver.NewSolverParameters()
+	var sampler sapi.Sampler = sapi.ExactSolver{}
+	sp := sampler.NewSolverParameters()
 	switch sp := sp.(type) {
 	case *sapi.SwOptimizeSolverParameters:
 		sp.NumReads = 1000
@@ -65,11 +84,38 @@ func ExampleSolverParameters() {
 	}
 
 	// Code to pass sp to one of the Solve* calls would normally appear
-	// here.
-	_ = sp
+	// here.  Instead, report the parameters' type and number of reads.
+	fmt.Printf("%T with %d reads\n", sp, sp.(*sapi.SwOptimizeSolverParameters).NumReads)
+	// Output: *sapi.SwOptimizeSolverParameters with 1000 reads
+}
+
+// Specify solver parameters using functional options.  Unlike the
+// SolverParameters example, this requires no type switch, but it fails if an
+// option does not apply to the solver's type of parameters.
+func ExampleNewParameters() {
+	// Set the number of reads and the maximum number of answers.
+	var es sapi.ExactSolver
+	sp, err := sapi.NewParameters(es,
+		sapi.WithNumReads(1000),
+		sapi.WithMaxAnswers(10))
+	if err != nil {
+		panic(err)
+	}
+	osp := sp.(*sapi.SwOptimizeSolverParameters)
+	fmt.Printf("%d reads, %d answers\n", osp.NumReads, osp.MaxAnswers)
+
+	// Autoscaling applies only to quantum solvers, so requesting it from
+	// an ExactSolver fails.
+	_, err = sapi.NewParameters(es, sapi.WithAutoScale(true))
+	fmt.Println(err != nil)
+	// Output:
+	// 1000 reads, 10 answers
+	// true
 }
 
-// Submit a problem asynchronously and wait for it to complete.
+// Submit a problem asynchronously and wait for it to complete.  Only a Solver
+// backed by a SAPI connection supports asynchronous submission, so this
+// example is compiled but not run.
 func ExampleSolver_AsyncSolveIsing() {
 	// Asynchronously solve problem prob with solver parameters sp.
 	sub, err := solver.AsyncSolveIsing(prob, sp)
@@ -87,34 +133,27 @@ func ExampleSolver_AsyncSolveIsing() {
 	_ = ir
 }
 
-// Solve a maximally frustrated problem on a local solver.
+// Solve a maximally frustrated problem.  The example uses an ExactSolver so
+// that it runs without a SAPI solver.  See the RemoteConnection and
+// NewSolverFromConfig examples for code that acquires a Solver, which can be
+// used in the same way.
 func Example_frustration() {
-	// Connect to the c4-sw_optimize local solver.  See the
-	// RemoteConnection example for code that connects to either a local or
-	// remote solver based on a set of environment variables.
-	conn := sapi.LocalConnection()
-	solver, err := conn.Solver("c4-sw_optimize")
-	if err != nil {
-		panic(err)
+	// Construct an Ising-model problem in which all edges of a triangle
+	// are antiferromagnetically coupled.  No assignment of spins can
+	// satisfy all three couplers.
+	var solver sapi.ExactSolver
+	prob := sapi.Problem{
+		{I: 0, J: 1, Value: 1.0},
+		{I: 0, J: 2, Value: 1.0},
+		{I: 1, J: 2, Value: 1.0},
 	}
 
-	// Construct an Ising-model problem in which all edges in the entire
-	// graph are antiferromagnetically coupled.
-	adj, err := solver.HardwareAdjacency()
+	// Request every solution.  See the SolverParameters example for code
+	// that sets solver-specific parameters without functional options.
+	sp, err := sapi.NewParameters(solver, sapi.WithMaxAnswers(8))
 	if err != nil {
 		panic(err)
 	}
-	prob := make(sapi.Problem, len(adj))
-	for i, cp := range adj {
-		prob[i].I = cp.I
-		prob[i].J = cp.J
-		prob[i].Value = 1.0
-	}
-
-	// Solve the problem using the solver's default parameters.  See the
-	// SolverParameters example for code that sets solver-specific
-	// parameters.
-	sp := solver.NewSolverParameters()
 	ir, err := solver.SolveIsing(prob, sp)
 	if err != nil {
 		panic(err)
@@ -122,7 +161,43 @@ func Example_frustration() {
 
 	// Output all of the solutions found.
 	for i, soln := range ir.Solutions {
-		fmt.Printf("%5d) energy = %f, tally = %d, solution = %v\n",
+		fmt.Printf("%d) energy = %f, tally = %d, solution = %v\n",
 			i+1, ir.Energies[i], ir.Occurrences[i], soln)
 	}
+	// Output:
+	// 1) energy = -1.000000, tally = 1, solution = [-1 -1 1]
+	// 2) energy = -1.000000, tally = 1, solution = [-1 1 -1]
+	// 3) energy = -1.000000, tally = 1, solution = [-1 1 1]
+	// 4) energy = -1.000000, tally = 1, solution = [1 -1 -1]
+	// 5) energy = -1.000000, tally = 1, solution = [1 -1 1]
+	// 6) energy = -1.000000, tally = 1, solution = [1 1 -1]
+	// 7) energy = 3.000000, tally = 1, solution = [-1 -1 -1]
+	// 8) energy = 3.000000, tally = 1, solution = [1 1 1]
+}
+
+// Solve a small problem exactly without using a SAPI solver.  This is
+// convenient for smoke tests and for experimenting with the package where no
+// SAPI solver is installed.
+func ExampleExactSolver() {
+	// Define an antiferromagnetic coupling between two spins, one of
+	// which is biased towards +1.
+	prob := sapi.Problem{
+		{I: 0, J: 0, Value: -0.5},
+		{I: 0, J: 1, Value: 1.0},
+	}
+
+	// Find the two lowest-energy solutions.
+	var es sapi.ExactSolver
+	sp := es.NewSolverParameters().(*sapi.SwOptimizeSolverParameters)
+	sp.MaxAnswers = 2
+	ir, err := es.SolveIsing(prob, sp)
+	if err != nil {
+		panic(err)
+	}
+	for i, soln := range ir.Solutions {
+		fmt.Printf("energy = %4.1f, solution = %v\n", ir.Energies[i], soln)
+	}
+	// Output:
+	// energy = -1.5, solution = [1 -1]
+	// energy = -0.5, solution = [-1 1]
 }
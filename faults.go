@@ -0,0 +1,184 @@
+// This file provides a sampler that injects faults so that applications can
+// exercise their error-handling paths without access to real hardware.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A FaultInjector is a Sampler that delays, fails, or corrupts some fraction
+// of the calls made to it.  If Sampler is non-nil, calls that are not
+// faulted are passed through to it.  Otherwise, the FaultInjector acts as a
+// trivial local solver that returns uniformly random solutions.
+type FaultInjector struct {
+	Sampler       Sampler       // Underlying sampler (nil = return random solutions)
+	Delay         time.Duration // Time to wait before each call completes
+	NetworkRate   float64       // Probability that a call fails with a NetworkError
+	CancelRate    float64       // Probability that a call fails with ProblemCanceled
+	MalformedRate float64       // Probability that a call returns a malformed result
+	rng           *rand.Rand    // Random-number generator
+	mu            sync.Mutex    // Protects rng
+}
+
+// NewFaultInjector wraps a sampler, which may be nil, with a FaultInjector
+// that initially injects no faults.  The seed determines the sequence of
+// faults.
+func NewFaultInjector(s Sampler, seed int64) *FaultInjector {
+	return &FaultInjector{
+		Sampler: s,
+		rng:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// float64 returns a random number in [0, 1).
+func (fi *FaultInjector) float64() float64 {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.rng.Float64()
+}
+
+// intn returns a random number in [0, n).
+func (fi *FaultInjector) intn(n int) int {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.rng.Intn(n)
+}
+
+// randomResult returns a set of uniformly random Ising-model or QUBO
+// solutions to a problem.
+func (fi *FaultInjector) randomResult(p Problem, sp SolverParameters, qubo bool) IsingResult {
+	nv := 0
+	for _, pe := range p {
+		if pe.I+1 > nv {
+			nv = pe.I + 1
+		}
+		if pe.J+1 > nv {
+			nv = pe.J + 1
+		}
+	}
+	ns := numReads(sp)
+	if ns < 1 {
+		ns = 1
+	}
+	res := IsingResult{
+		Solutions:   make([][]int8, ns),
+		ScaleFactor: 1,
+	}
+	for i := range res.Solutions {
+		soln := make([]int8, nv)
+		for v := range soln {
+			soln[v] = int8(2*fi.intn(2) - 1)
+			if qubo {
+				soln[v] = (soln[v] + 1) / 2
+			}
+		}
+		res.Solutions[i] = soln
+	}
+	sort.Slice(res.Solutions, func(i, j int) bool {
+		return isingEnergy(p, res.Solutions[i]) < isingEnergy(p, res.Solutions[j])
+	})
+	res.Energies = p.Energies(res.Solutions)
+	return res
+}
+
+// malform corrupts an IsingResult in one of several randomly chosen ways.
+func (fi *FaultInjector) malform(res IsingResult) IsingResult {
+	switch fi.intn(3) {
+	case 0:
+		// Drop an energy so the number of energies and solutions
+		// disagree.
+		if len(res.Energies) > 0 {
+			res.Energies = res.Energies[:len(res.Energies)-1]
+		}
+	case 1:
+		// Replace every energy with NaN.
+		for i := range res.Energies {
+			res.Energies[i] = math.NaN()
+		}
+	default:
+		// Replace a spin with an invalid value.
+		for _, soln := range res.Solutions {
+			if len(soln) > 0 {
+				soln[0] = 7
+				break
+			}
+		}
+	}
+	return res
+}
+
+// solve is the common code for SolveIsing and SolveQubo.
+func (fi *FaultInjector) solve(p Problem, sp SolverParameters, qubo bool) (IsingResult, error) {
+	// Inject delays and failures.
+	time.Sleep(fi.Delay)
+	if fi.float64() < fi.NetworkRate {
+		return IsingResult{}, newErrorf(C.SAPI_ERR_NETWORK, "Injected network failure")
+	}
+	if fi.float64() < fi.CancelRate {
+		return IsingResult{}, newErrorf(C.SAPI_ERR_PROBLEM_CANCELLED, "Injected cancellation")
+	}
+
+	// Solve the problem.
+	var res IsingResult
+	switch {
+	case fi.Sampler == nil:
+		res = fi.randomResult(p, sp, qubo)
+	case qubo:
+		var err error
+		res, err = fi.Sampler.SolveQubo(p, sp)
+		if err != nil {
+			return IsingResult{}, err
+		}
+	default:
+		var err error
+		res, err = fi.Sampler.SolveIsing(p, sp)
+		if err != nil {
+			return IsingResult{}, err
+		}
+	}
+
+	// Inject malformed results.
+	if fi.float64() < fi.MalformedRate {
+		res = fi.malform(res)
+	}
+	return res, nil
+}
+
+// SolveIsing solves an Ising-model problem, possibly injecting a fault.
+func (fi *FaultInjector) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	return fi.solve(p, sp, false)
+}
+
+// SolveQubo solves a QUBO problem, possibly injecting a fault.
+func (fi *FaultInjector) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	return fi.solve(p, sp, true)
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying sampler or, if there is none, a set of sw_optimize parameters.
+func (fi *FaultInjector) NewSolverParameters() SolverParameters {
+	if fi.Sampler == nil {
+		return newSwOptimizeSolverParameters()
+	}
+	return fi.Sampler.NewSolverParameters()
+}
+
+// Properties returns the underlying sampler's properties or, if there is
+// none, properties indicating support for Ising and QUBO problems.
+func (fi *FaultInjector) Properties() *SolverProperties {
+	if fi.Sampler == nil {
+		return &SolverProperties{SupportedProblemTypes: []string{"ising", "qubo"}}
+	}
+	return fi.Sampler.Properties()
+}
@@ -0,0 +1,125 @@
+// This file provides a solver wrapper that filters the results returned by
+// another sampler.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"sort"
+)
+
+// A FilterComposite wraps a sampler and discards some of the solutions it
+// returns.  Because a FilterComposite is itself a Sampler, it can be composed
+// with any of the other solver wrappers.
+type FilterComposite struct {
+	Sampler             Sampler  // Underlying sampler
+	KeepLowest          int      // Maximum number of lowest-energy solutions to keep (0 = all)
+	MaxEnergy           *float64 // Energy above which solutions are discarded (nil = no cutoff)
+	DiscardBrokenChains bool     // Discard solutions with broken chains (embedding samplers only; others fail)
+}
+
+// withDiscard returns a copy of an embedding sampler that discards solutions
+// with broken chains.  It returns an error if s does not itself handle
+// chains, as broken chains can then not be identified.
+func withDiscard(s Sampler) (Sampler, error) {
+	switch s := s.(type) {
+	case *EmbeddingComposite:
+		c := *s
+		c.BrokenChains = BrokenChainsDiscard
+		return &c, nil
+	case *FixedEmbeddingComposite:
+		c := *s
+		c.BrokenChains = BrokenChainsDiscard
+		return &c, nil
+	case *VirtualGraphSolver:
+		c := *s
+		c.BrokenChains = BrokenChainsDiscard
+		return &c, nil
+	case *TilingComposite:
+		c := *s
+		c.BrokenChains = BrokenChainsDiscard
+		return &c, nil
+	default:
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "DiscardBrokenChains requires an embedding sampler, not a %T", s)
+	}
+}
+
+// Filter applies the FilterComposite's energy cutoff and solution limit to
+// an IsingResult.  The result's solutions are sorted by increasing energy.
+func (fc *FilterComposite) Filter(res IsingResult) IsingResult {
+	// Sort the solutions by increasing energy.
+	order := make([]int, len(res.Solutions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return res.Energies[order[i]] < res.Energies[order[j]]
+	})
+
+	// Retain only those solutions that pass the filters.
+	filt := IsingResult{Timing: res.Timing, ScaleFactor: res.ScaleFactor}
+	for _, o := range order {
+		if fc.KeepLowest > 0 && len(filt.Solutions) >= fc.KeepLowest {
+			break
+		}
+		if fc.MaxEnergy != nil && res.Energies[o] > *fc.MaxEnergy {
+			break
+		}
+		filt.Solutions = append(filt.Solutions, res.Solutions[o])
+		filt.Energies = append(filt.Energies, res.Energies[o])
+		if res.Occurrences != nil {
+			filt.Occurrences = append(filt.Occurrences, res.Occurrences[o])
+		}
+	}
+	return filt
+}
+
+// SolveIsing solves an Ising-model problem and filters the result.
+func (fc *FilterComposite) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	s := fc.Sampler
+	if fc.DiscardBrokenChains {
+		var err error
+		s, err = withDiscard(s)
+		if err != nil {
+			return IsingResult{}, err
+		}
+	}
+	res, err := s.SolveIsing(p, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return fc.Filter(res), nil
+}
+
+// SolveQubo solves a QUBO problem and filters the result.
+func (fc *FilterComposite) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	s := fc.Sampler
+	if fc.DiscardBrokenChains {
+		var err error
+		s, err = withDiscard(s)
+		if err != nil {
+			return IsingResult{}, err
+		}
+	}
+	res, err := s.SolveQubo(p, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return fc.Filter(res), nil
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying sampler.
+func (fc *FilterComposite) NewSolverParameters() SolverParameters {
+	return fc.Sampler.NewSolverParameters()
+}
+
+// Properties returns the underlying sampler's properties.
+func (fc *FilterComposite) Properties() *SolverProperties {
+	return fc.Sampler.Properties()
+}
@@ -0,0 +1,149 @@
+// This file provides a solver wrapper that applies a precomputed embedding to
+// every problem it solves.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+// A FixedEmbeddingComposite wraps a solver with a precomputed embedding,
+// which it applies to every problem it solves.  This is useful when one good
+// embedding has been found offline and is to be reused for many problems with
+// the same logical graph.
+type FixedEmbeddingComposite struct {
+	Solver        *Solver              // Underlying solver
+	Embedding     Embeddings           // Mapping from physical qubits to logical variables
+	ChainStrength float64              // J value applied to couplers within a chain
+	BrokenChains  BrokenChains         // How to resolve chains whose qubits disagree
+	adj           Problem              // Hardware adjacency
+	ranges        IsingRangeProperties // Coefficient ranges for the embedded problem
+}
+
+// NewFixedEmbeddingComposite wraps a solver with a fixed embedding.  It fails
+// if any chain in the embedding is not connected in the solver's hardware
+// graph.  The chain strength defaults to the most negative J value the solver
+// accepts, and broken chains default to BrokenChainsMinimizeEnergy.
+func NewFixedEmbeddingComposite(s *Solver, emb Embeddings) (*FixedEmbeddingComposite, error) {
+	adj, err := s.HardwareAdjacency()
+	if err != nil {
+		return nil, err
+	}
+	if err = checkChains(emb, adj); err != nil {
+		return nil, err
+	}
+	ranges := IsingRangeProperties{HMin: -1, HMax: 1, JMin: -1, JMax: 1}
+	if ir := s.Properties().IsingRanges; ir != nil {
+		ranges = *ir
+	}
+	return &FixedEmbeddingComposite{
+		Solver:        s,
+		Embedding:     emb,
+		ChainStrength: ranges.JMin,
+		BrokenChains:  BrokenChainsMinimizeEnergy,
+		adj:           adj,
+		ranges:        ranges,
+	}, nil
+}
+
+// checkChains ensures that each chain in an embedding forms a connected
+// subgraph of a hardware graph.
+func checkChains(emb Embeddings, adj Problem) error {
+	hw := make(map[[2]int]bool, len(adj))
+	for _, pe := range adj {
+		hw[[2]int{pe.I, pe.J}] = true
+		hw[[2]int{pe.J, pe.I}] = true
+	}
+	for v, ch := range emb.Chains() {
+		// Perform a flood fill from the first qubit in the chain.
+		inChain := make(map[int]bool, len(ch))
+		for _, q := range ch {
+			inChain[q] = true
+		}
+		seen := map[int]bool{ch[0]: true}
+		stack := []int{ch[0]}
+		for len(stack) > 0 {
+			q := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for q2 := range inChain {
+				if !seen[q2] && hw[[2]int{q, q2}] {
+					seen[q2] = true
+					stack = append(stack, q2)
+				}
+			}
+		}
+		if len(seen) != len(ch) {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The chain for variable %d is not connected in the hardware graph", v)
+		}
+	}
+	return nil
+}
+
+// Fits ensures that a logical problem can be expressed using the
+// FixedEmbeddingComposite's embedding: every variable in the problem must
+// have a chain, and every pair of coupled variables must have at least one
+// hardware coupler between their chains.
+func (fe *FixedEmbeddingComposite) Fits(p Problem) error {
+	// Determine which pairs of logical variables are connected.
+	emb := fe.Embedding
+	conn := make(map[[2]int]bool, len(fe.adj))
+	for _, pe := range fe.adj {
+		if pe.I >= len(emb) || pe.J >= len(emb) {
+			continue
+		}
+		u, v := emb[pe.I], emb[pe.J]
+		if u >= 0 && v >= 0 {
+			conn[[2]int{u, v}] = true
+			conn[[2]int{v, u}] = true
+		}
+	}
+
+	// Check each of the problem's coefficients.
+	chains := emb.Chains()
+	for _, pe := range p {
+		for _, v := range [2]int{pe.I, pe.J} {
+			if _, ok := chains[v]; !ok {
+				return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Variable %d is not embedded", v)
+			}
+		}
+		if pe.I != pe.J && pe.Value != 0.0 && !conn[[2]int{pe.I, pe.J}] {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "No hardware coupler connects the chains for variables %d and %d", pe.I, pe.J)
+		}
+	}
+	return nil
+}
+
+// SolveIsing embeds, solves, and unembeds an Ising-model problem.
+func (fe *FixedEmbeddingComposite) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	if err := fe.Fits(p); err != nil {
+		return IsingResult{}, err
+	}
+	return solveEmbeddedIsing(fe.Solver, p, fe.Embedding, fe.adj, fe.ranges,
+		fe.ChainStrength, fe.BrokenChains, sp)
+}
+
+// SolveQubo embeds, solves, and unembeds a QUBO problem.
+func (fe *FixedEmbeddingComposite) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	if err := fe.Fits(p); err != nil {
+		return IsingResult{}, err
+	}
+	return solveEmbeddedQubo(fe.Solver, p, fe.Embedding, fe.adj, fe.ranges,
+		fe.ChainStrength, fe.BrokenChains, sp)
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying solver.
+func (fe *FixedEmbeddingComposite) NewSolverParameters() SolverParameters {
+	return fe.Solver.NewSolverParameters()
+}
+
+// Properties returns the underlying solver's properties but with no
+// QuantumProps, as a FixedEmbeddingComposite accepts any problem whose graph
+// fits its embedding.
+func (fe *FixedEmbeddingComposite) Properties() *SolverProperties {
+	props := *fe.Solver.Properties()
+	props.QuantumProps = nil
+	return &props
+}
@@ -0,0 +1,257 @@
+// This file provides generators of random benchmark problems.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"math"
+	"math/rand"
+)
+
+// couplers returns the distinct couplers of an adjacency, each with I < J, in
+// canonical order.  Linear entries are ignored.
+func (p Problem) couplers() Problem {
+	var cs Problem
+	for _, pe := range p.Canonicalize() {
+		if pe.I != pe.J {
+			cs = append(cs, ProblemEntry{I: pe.I, J: pe.J})
+		}
+	}
+	return cs
+}
+
+// GenerateRANk generates a RAN-k Ising-model problem on a given adjacency,
+// such as that returned by HardwareAdjacency.  Each coupler in the adjacency
+// is assigned a value drawn uniformly from {-k, ..., -1, 1, ..., k}; the
+// problem has no linear terms.  k must be positive.  If rng is nil, the
+// package-wide source of randomness (see SetRandSource) is used.
+func GenerateRANk(adj Problem, k int, rng *rand.Rand) Problem {
+	if rng == nil {
+		rng = newRand()
+	}
+	p := adj.couplers()
+	for i := range p {
+		v := float64(rng.Intn(k) + 1)
+		if rng.Intn(2) == 0 {
+			v = -v
+		}
+		p[i].Value = v
+	}
+	return p
+}
+
+// findLoop performs a non-backtracking random walk on a graph, starting from
+// a random vertex, until the walk revisits a vertex.  It returns the
+// resulting cycle or nil if the walk reached a dead end.
+func findLoop(g *ProblemGraph, verts []int, rng *rand.Rand) []int {
+	v := verts[rng.Intn(len(verts))]
+	path := []int{v}
+	where := map[int]int{v: 0}
+	prev := -1
+	for {
+		// Choose a random neighbor other than the one we just left.
+		var cands []int
+		for _, n := range g.Neighbors(v) {
+			if len(path) < 2 || n != prev {
+				cands = append(cands, n)
+			}
+		}
+		if len(cands) == 0 {
+			return nil
+		}
+		next := cands[rng.Intn(len(cands))]
+
+		// Return the cycle if we revisited a vertex.
+		if pos, ok := where[next]; ok {
+			return path[pos:]
+		}
+		where[next] = len(path)
+		path = append(path, next)
+		prev, v = v, next
+	}
+}
+
+// GenerateFrustratedLoops generates an Ising-model problem with a planted
+// ground state on a given adjacency, such as that returned by
+// HardwareAdjacency.  The problem is a sum of numLoops frustrated loops, each
+// a cycle in the adjacency found by a random walk.  Each loop's couplers are
+// chosen to be satisfied by the planted solution except for one, which is
+// frustrated, so the planted solution minimizes every loop's energy and
+// therefore the total energy.  If maxCoupling is positive, loops that would
+// make the magnitude of any coupler exceed maxCoupling are rejected.  If rng
+// is nil, the package-wide source of randomness (see SetRandSource) is used.
+//
+// GenerateFrustratedLoops returns the problem and the planted solution,
+// which is indexed by variable number and contains 3 for variables that do
+// not appear in the adjacency.  The planted solution is a ground state but
+// is not necessarily the only one.  GenerateFrustratedLoops fails if it
+// cannot find numLoops acceptable loops within a reasonable number of
+// attempts.
+func GenerateFrustratedLoops(adj Problem, numLoops int, maxCoupling float64, rng *rand.Rand) (Problem, []int8, error) {
+	if rng == nil {
+		rng = newRand()
+	}
+
+	// Plant a random solution on the adjacency's vertices.
+	g := adj.couplers().Graph()
+	verts := g.Vertices()
+	if len(verts) == 0 {
+		return nil, nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The adjacency contains no couplers")
+	}
+	planted := make([]int8, verts[len(verts)-1]+1)
+	for i := range planted {
+		planted[i] = 3
+	}
+	for _, v := range verts {
+		planted[v] = int8(2*rng.Intn(2) - 1)
+	}
+
+	// Accumulate loops until we have enough.
+	J := make(map[[2]int]float64)
+	for n, tries := 0, 0; n < numLoops; tries++ {
+		if tries >= 100*(numLoops+1) {
+			return nil, nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Found only %d of %d frustrated loops", n, numLoops)
+		}
+		loop := findLoop(g, verts, rng)
+		if loop == nil {
+			continue
+		}
+
+		// Satisfy all of the loop's couplers but one.
+		bad := rng.Intn(len(loop))
+		delta := make(map[[2]int]float64, len(loop))
+		ok := true
+		for k, i := range loop {
+			j := loop[(k+1)%len(loop)]
+			if i > j {
+				i, j = j, i
+			}
+			v := -float64(planted[i] * planted[j])
+			if k == bad {
+				v = -v
+			}
+			key := [2]int{i, j}
+			delta[key] = v
+			if maxCoupling > 0 && math.Abs(J[key]+v) > maxCoupling {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		for key, v := range delta {
+			J[key] += v
+		}
+		n++
+	}
+	return NewProblemFromMaps(nil, J), planted, nil
+}
+
+// GenerateSAT generates a random k-SAT instance with a given number of
+// variables and clauses.  Each clause contains k literals on distinct
+// variables, each negated with probability 1/2.  Literals are written in
+// DIMACS style: literal +v denotes variable v and literal -v denotes its
+// negation, with variables numbered from 1.  k must not exceed numVars.  If
+// rng is nil, the package-wide source of randomness (see SetRandSource) is
+// used.
+func GenerateSAT(numVars, numClauses, k int, rng *rand.Rand) [][]int {
+	if rng == nil {
+		rng = newRand()
+	}
+	clauses := make([][]int, numClauses)
+	for c := range clauses {
+		vars := rng.Perm(numVars)[:k]
+		clause := make([]int, k)
+		for i, v := range vars {
+			clause[i] = v + 1
+			if rng.Intn(2) == 0 {
+				clause[i] = -clause[i]
+			}
+		}
+		clauses[c] = clause
+	}
+	return clauses
+}
+
+// literalSpin maps a DIMACS-style literal to an Ising-model variable number
+// and a sign that is +1 if the literal is true when the variable is +1 and
+// -1 if the literal is true when the variable is -1.
+func literalSpin(lit int) (int, float64, error) {
+	switch {
+	case lit > 0:
+		return lit - 1, 1, nil
+	case lit < 0:
+		return -lit - 1, -1, nil
+	default:
+		return 0, 0, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "0 is not a valid literal")
+	}
+}
+
+// NAE3SATToIsing converts a not-all-equal 3-SAT instance, as produced by
+// GenerateSAT with k = 3, to an Ising-model problem.  Variable v of the
+// instance corresponds to Ising-model variable v-1, with +1 representing
+// true.  A clause's energy is -1 if its literals are not all equal and 3
+// otherwise, so the instance is satisfiable if and only if the problem's
+// ground-state energy is the negative of the number of clauses.
+func NAE3SATToIsing(clauses [][]int) (Problem, error) {
+	var pb ProblemBuilder
+	for c, clause := range clauses {
+		if len(clause) != 3 {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Clause %d contains %d literals instead of 3", c, len(clause))
+		}
+		var vs [3]int
+		var ss [3]float64
+		for a, lit := range clause {
+			var err error
+			if vs[a], ss[a], err = literalSpin(lit); err != nil {
+				return nil, err
+			}
+		}
+		for a := 0; a < 3; a++ {
+			for b := a + 1; b < 3; b++ {
+				if vs[a] == vs[b] {
+					return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Clause %d mentions variable %d more than once", c, vs[a]+1)
+				}
+				pb.AddQuadratic(vs[a], vs[b], ss[a]*ss[b])
+			}
+		}
+	}
+	return pb.Build(), nil
+}
+
+// TwoSATToIsing converts a 2-SAT instance, as produced by GenerateSAT with
+// k = 2, to an Ising-model problem.  Variable v of the instance corresponds
+// to Ising-model variable v-1, with +1 representing true.  TwoSATToIsing
+// also returns an energy offset such that a solution's energy plus the
+// offset equals the number of clauses it leaves unsatisfied.
+func TwoSATToIsing(clauses [][]int) (Problem, float64, error) {
+	// A clause (a ∨ b) is unsatisfied with penalty (1 - a)(1 - b)/4, where
+	// a and b are the literals' ±1 values.
+	var pb ProblemBuilder
+	for c, clause := range clauses {
+		if len(clause) != 2 {
+			return nil, 0, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Clause %d contains %d literals instead of 2", c, len(clause))
+		}
+		va, sa, err := literalSpin(clause[0])
+		if err != nil {
+			return nil, 0, err
+		}
+		vb, sb, err := literalSpin(clause[1])
+		if err != nil {
+			return nil, 0, err
+		}
+		if va == vb {
+			return nil, 0, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Clause %d mentions variable %d more than once", c, va+1)
+		}
+		pb.AddLinear(va, -sa/4)
+		pb.AddLinear(vb, -sb/4)
+		pb.AddQuadratic(va, vb, sa*sb/4)
+	}
+	return pb.Build(), float64(len(clauses)) / 4, nil
+}
@@ -0,0 +1,80 @@
+// This file converts problems to and from gonum matrices so that
+// linear-algebra preprocessing (spectral bounds, scaling, etc.) can be
+// performed with gonum.org/v1/gonum/mat.  Because gonum is not otherwise
+// required, this file is compiled only when the "gonum" build tag is
+// specified.
+
+//go:build gonum
+// +build gonum
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// ToSymDense returns a problem as an n×n symmetric gonum matrix M in which
+// M[i][i] is variable i's linear coefficient and M[i][j] = M[j][i] is the
+// coupling between variables i and j.  Duplicate entries are summed.
+// ToSymDense fails if any variable's index is not less than n.
+func (p Problem) ToSymDense(n int) (*mat.SymDense, error) {
+	m := mat.NewSymDense(n, nil)
+	for _, pe := range p {
+		i, j := pe.I, pe.J
+		if i > j {
+			i, j = j, i
+		}
+		if i < 0 || j >= n {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "%s does not fit in a %d×%d matrix", pe, n, n)
+		}
+		m.SetSym(i, j, m.At(i, j)+pe.Value)
+	}
+	return m, nil
+}
+
+// NewProblemFromSym is the inverse of ToSymDense.  It reads linear
+// coefficients from the diagonal of a symmetric matrix and couplings from
+// the upper triangle.  Any mat.Symmetric can be passed, including sparse
+// implementations.  Zero-valued elements are omitted from the result, which
+// is in canonical form (see Canonicalize).
+func NewProblemFromSym(m mat.Symmetric) Problem {
+	n := m.Symmetric()
+	var p Problem
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			if v := m.At(i, j); v != 0.0 {
+				p = append(p, ProblemEntry{I: i, J: j, Value: v})
+			}
+		}
+	}
+	return p
+}
+
+// NewProblemFromMatrix is the gonum analogue of NewProblemFromDense: it
+// treats a square matrix Q as a QUBO matrix, adding each below-diagonal
+// element Q[j][i] to Q[i][j].
+func NewProblemFromMatrix(Q mat.Matrix) (Problem, error) {
+	r, c := Q.Dims()
+	if r != c {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "A QUBO matrix must be square, not %d×%d", r, c)
+	}
+	var p Problem
+	for i := 0; i < r; i++ {
+		for j := i; j < r; j++ {
+			v := Q.At(i, j)
+			if j != i {
+				v += Q.At(j, i)
+			}
+			if v != 0.0 {
+				p = append(p, ProblemEntry{I: i, J: j, Value: v})
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,58 @@
+// This file tests the gonum conversions, which are compiled only when the
+// "gonum" build tag is specified.
+
+//go:build gonum
+// +build gonum
+
+package sapi_test
+
+import (
+	"github.com/lanl/sapi"
+	"gonum.org/v1/gonum/mat"
+	"reflect"
+	"testing"
+)
+
+// TestGonum ensures that a problem survives a round trip through a gonum
+// symmetric matrix.
+func TestGonum(t *testing.T) {
+	// Convert a problem to a symmetric matrix.
+	prob := sapi.Problem{
+		{I: 1, J: 0, Value: 1.0},
+		{I: 1, J: 1, Value: -0.5},
+		{I: 0, J: 1, Value: 0.5},
+		{I: 1, J: 2, Value: -1.0},
+	}
+	m, err := prob.ToSymDense(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.At(0, 1) != 1.5 || m.At(1, 0) != 1.5 || m.At(1, 1) != -0.5 {
+		t.Fatalf("Incorrect matrix %v", mat.Formatted(m))
+	}
+
+	// Convert the matrix back to a problem.
+	exp := sapi.Problem{
+		{I: 0, J: 1, Value: 1.5},
+		{I: 1, J: 1, Value: -0.5},
+		{I: 1, J: 2, Value: -1.0},
+	}
+	if p := sapi.NewProblemFromSym(m); !reflect.DeepEqual(p, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, p)
+	}
+
+	// Ensure that a general matrix is treated as a QUBO matrix.
+	q := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+	p, err := sapi.NewProblemFromMatrix(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = sapi.Problem{
+		{I: 0, J: 0, Value: 1},
+		{I: 0, J: 1, Value: 5},
+		{I: 1, J: 1, Value: 4},
+	}
+	if !reflect.DeepEqual(p, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, p)
+	}
+}
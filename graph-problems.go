@@ -0,0 +1,212 @@
+// This file provides encoders of common graph problems as Ising-model and
+// QUBO problems.  In each case, the input graph is represented as a Problem
+// in which every quadratic entry, regardless of its value, denotes an edge
+// (see ProblemGraph).
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+// MaxCutToIsing encodes the maximum-cut problem on graph g as an Ising-model
+// problem.  A solution's spins indicate the side of the cut on which each
+// vertex lies.  MaxCutToIsing also returns an energy offset such that a
+// solution's energy plus the offset equals the negative of the number of
+// edges cut.  Every solution is feasible.
+func MaxCutToIsing(g Problem) (Problem, float64) {
+	// An edge contributes 1/2 when uncut and -1/2 when cut.
+	cs := g.couplers()
+	for i := range cs {
+		cs[i].Value = 0.5
+	}
+	return cs, -float64(len(cs)) / 2
+}
+
+// CutSize returns the number of edges in graph g whose endpoints have
+// different values in a solution.  It works with both ±1 and 0/1 solutions.
+func CutSize(g Problem, soln []int8) int {
+	n := 0
+	for _, pe := range g.couplers() {
+		if pe.J < len(soln) && soln[pe.I] != soln[pe.J] {
+			n++
+		}
+	}
+	return n
+}
+
+// CutPartition decodes a solution to a problem produced by MaxCutToIsing
+// into the variables on each side of the cut.  Variables with value -1 (or
+// 0) are returned in the first list, and variables with value +1 in the
+// second.  Variables with other values (e.g., 3 for "unused") are omitted.
+func CutPartition(soln []int8) ([]int, []int) {
+	var a, b []int
+	for v, s := range soln {
+		switch s {
+		case -1, 0:
+			a = append(a, v)
+		case 1:
+			b = append(b, v)
+		}
+	}
+	return a, b
+}
+
+// IndependentSetToQubo encodes the maximum-independent-set problem on graph
+// g as a QUBO problem.  A variable's value of 1 indicates that the
+// corresponding vertex is in the set.  Each vertex contributes -1 to the
+// energy, and each edge whose endpoints are both in the set contributes
+// penalty.  A penalty greater than 1 guarantees that every ground state is
+// a maximum independent set, whose energy is the negative of its size.  A
+// penalty of zero or less selects a default of 2.
+func IndependentSetToQubo(g Problem, penalty float64) Problem {
+	if penalty <= 0.0 {
+		penalty = 2.0
+	}
+	var pb ProblemBuilder
+	for _, v := range g.Graph().Vertices() {
+		pb.AddLinear(v, -1.0)
+	}
+	for _, pe := range g.couplers() {
+		pb.AddQuadratic(pe.I, pe.J, penalty)
+	}
+	return pb.Build()
+}
+
+// DecodeIndependentSet returns the vertices selected by a solution to a
+// problem produced by IndependentSetToQubo, in increasing order.
+func DecodeIndependentSet(soln []int8) []int {
+	var set []int
+	for v, x := range soln {
+		if x == 1 {
+			set = append(set, v)
+		}
+	}
+	return set
+}
+
+// IsIndependentSet reports whether no two vertices in a set are adjacent in
+// graph g.
+func IsIndependentSet(g Problem, set []int) bool {
+	in := make(map[int]bool, len(set))
+	for _, v := range set {
+		in[v] = true
+	}
+	for _, pe := range g.couplers() {
+		if in[pe.I] && in[pe.J] {
+			return false
+		}
+	}
+	return true
+}
+
+// A ColoringEncoding is a QUBO encoding of the graph-coloring problem.
+// Variable Var(v, c) is 1 if and only if vertex v is assigned color c.  A
+// solution's energy plus Offset is zero if and only if the solution
+// represents a proper coloring.
+type ColoringEncoding struct {
+	Problem  Problem     // QUBO problem to solve
+	Offset   float64     // Constant to add to each solution's energy
+	Vertices []int       // Vertices of the graph, in increasing order
+	Colors   int         // Number of colors available
+	index    map[int]int // Map from a vertex to its position in Vertices
+}
+
+// ColoringToQubo encodes the problem of coloring graph g with k colors as a
+// QUBO problem.  Each vertex is represented by k one-hot variables, and
+// penalty is charged both for violating a vertex's one-hot constraint and for
+// assigning the same color to adjacent vertices.  A penalty of zero or less
+// selects a default of 1.
+func ColoringToQubo(g Problem, k int, penalty float64) (*ColoringEncoding, error) {
+	if k < 1 {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "At least one color is required (saw %d)", k)
+	}
+	if penalty <= 0.0 {
+		penalty = 1.0
+	}
+
+	// Number the vertices.
+	ce := &ColoringEncoding{
+		Vertices: g.Graph().Vertices(),
+		Colors:   k,
+	}
+	ce.index = make(map[int]int, len(ce.Vertices))
+	for i, v := range ce.Vertices {
+		ce.index[v] = i
+	}
+
+	// Penalize each vertex with other than exactly one color:
+	// penalty*(1 - Σx)² = penalty*(1 - Σx + 2Σx·x').
+	var pb ProblemBuilder
+	for _, v := range ce.Vertices {
+		for c := 0; c < k; c++ {
+			pb.AddLinear(ce.Var(v, c), -penalty)
+			for d := c + 1; d < k; d++ {
+				pb.AddQuadratic(ce.Var(v, c), ce.Var(v, d), 2*penalty)
+			}
+		}
+		ce.Offset += penalty
+	}
+
+	// Penalize adjacent vertices that share a color.
+	for _, pe := range g.couplers() {
+		for c := 0; c < k; c++ {
+			pb.AddQuadratic(ce.Var(pe.I, c), ce.Var(pe.J, c), penalty)
+		}
+	}
+	ce.Problem = pb.Build()
+	return ce, nil
+}
+
+// Var returns the variable that indicates that vertex v is assigned color c.
+// It returns -1 if v is not a vertex of the encoded graph or c is out of
+// range.
+func (ce *ColoringEncoding) Var(v, c int) int {
+	i, ok := ce.index[v]
+	if !ok || c < 0 || c >= ce.Colors {
+		return -1
+	}
+	return i*ce.Colors + c
+}
+
+// Decode maps each vertex to its color in a solution to ce.Problem.  It
+// additionally reports whether each vertex was assigned exactly one color.
+// Vertices with no color are omitted from the map, and vertices with more
+// than one color are assigned the smallest.
+func (ce *ColoringEncoding) Decode(soln []int8) (map[int]int, bool) {
+	colors := make(map[int]int, len(ce.Vertices))
+	ok := true
+	for _, v := range ce.Vertices {
+		n := 0
+		for c := ce.Colors - 1; c >= 0; c-- {
+			x := ce.Var(v, c)
+			if x < len(soln) && soln[x] == 1 {
+				colors[v] = c
+				n++
+			}
+		}
+		if n != 1 {
+			ok = false
+		}
+	}
+	return colors, ok
+}
+
+// IsProperColoring reports whether a coloring assigns a color to every
+// vertex of graph g and assigns different colors to adjacent vertices.
+func IsProperColoring(g Problem, colors map[int]int) bool {
+	verts := g.Graph().Vertices()
+	for _, v := range verts {
+		if _, ok := colors[v]; !ok {
+			return false
+		}
+	}
+	for _, pe := range g.couplers() {
+		if colors[pe.I] == colors[pe.J] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+// This file provides graph-theoretic queries on a problem's couplings.
+
+package sapi
+
+import (
+	"sort"
+)
+
+// A ProblemGraph is an adjacency index of a problem's quadratic terms.  Every
+// quadratic term contributes an edge, even if its value is zero, so a
+// ProblemGraph can also represent a hardware adjacency such as that returned
+// by HardwareAdjacency.  Variables that appear only in linear terms are
+// vertices with no neighbors.
+type ProblemGraph struct {
+	adj map[int][]int // Sorted, duplicate-free neighbors of each variable
+}
+
+// Graph constructs a ProblemGraph from a problem.  Use Graph rather than the
+// Problem methods Neighbors and Degree when performing many queries on the
+// same problem, as those construct a new ProblemGraph on each call.
+func (p Problem) Graph() *ProblemGraph {
+	// Collect each variable's neighbors.
+	adj := make(map[int][]int)
+	for _, pe := range p {
+		if pe.I == pe.J {
+			if _, ok := adj[pe.I]; !ok {
+				adj[pe.I] = nil
+			}
+			continue
+		}
+		adj[pe.I] = append(adj[pe.I], pe.J)
+		adj[pe.J] = append(adj[pe.J], pe.I)
+	}
+
+	// Sort the neighbors and remove duplicates.
+	for v, ns := range adj {
+		sort.Ints(ns)
+		uniq := ns[:0]
+		for i, n := range ns {
+			if i == 0 || n != ns[i-1] {
+				uniq = append(uniq, n)
+			}
+		}
+		adj[v] = uniq
+	}
+	return &ProblemGraph{adj: adj}
+}
+
+// Vertices returns all variables in the graph in increasing order.
+func (g *ProblemGraph) Vertices() []int {
+	vs := make([]int, 0, len(g.adj))
+	for v := range g.adj {
+		vs = append(vs, v)
+	}
+	sort.Ints(vs)
+	return vs
+}
+
+// Neighbors returns the variables that share a quadratic term with variable
+// i, in increasing order.  The caller must not modify the result.
+func (g *ProblemGraph) Neighbors(i int) []int {
+	return g.adj[i]
+}
+
+// Degree returns the number of distinct neighbors of variable i.
+func (g *ProblemGraph) Degree(i int) int {
+	return len(g.adj[i])
+}
+
+// Adjacent reports whether variables i and j share a quadratic term.
+func (g *ProblemGraph) Adjacent(i, j int) bool {
+	ns := g.adj[i]
+	k := sort.SearchInts(ns, j)
+	return k < len(ns) && ns[k] == j
+}
+
+// ConnectedComponents partitions the graph's variables into connected
+// components.  Each component's variables are sorted, and components are
+// returned in order of their smallest variable.
+func (g *ProblemGraph) ConnectedComponents() [][]int {
+	var comps [][]int
+	seen := make(map[int]bool, len(g.adj))
+	for _, v := range g.Vertices() {
+		if seen[v] {
+			continue
+		}
+
+		// Perform a depth-first search from v.
+		var comp []int
+		stack := []int{v}
+		seen[v] = true
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			comp = append(comp, u)
+			for _, n := range g.adj[u] {
+				if !seen[n] {
+					seen[n] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+		sort.Ints(comp)
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+// Neighbors returns the variables that share a quadratic term with variable
+// i, in increasing order.
+func (p Problem) Neighbors(i int) []int {
+	return p.Graph().Neighbors(i)
+}
+
+// Degree returns the number of distinct variables that share a quadratic
+// term with variable i.
+func (p Problem) Degree(i int) int {
+	return p.Graph().Degree(i)
+}
+
+// ConnectedComponents partitions a problem's variables into connected
+// components.  Unlike Components, which is intended for solving, it treats
+// zero-valued quadratic terms as edges and returns only variable numbers.
+func (p Problem) ConnectedComponents() [][]int {
+	return p.Graph().ConnectedComponents()
+}
@@ -0,0 +1,90 @@
+// This file provides content hashes of problems.
+
+package sapi
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"sort"
+)
+
+// Hash returns a hexadecimal SHA-256 hash of a problem's canonical form (see
+// Canonicalize).  Problems that differ only in the order of their entries,
+// in the order of I and J within an entry, or in how a coefficient is split
+// among duplicate entries therefore hash identically, making Hash suitable
+// as a key for caching embeddings or results and for deduplicating problems
+// across program runs.  Zero-valued entries are significant, as they
+// contribute variables and edges to a problem's graph.
+func (p Problem) Hash() string {
+	h := sha256.New()
+	var buf [24]byte
+	for _, pe := range p.Canonicalize() {
+		v := pe.Value
+		if v == 0 {
+			v = 0 // Map -0 to +0.
+		}
+		binary.LittleEndian.PutUint64(buf[0:], uint64(int64(pe.I)))
+		binary.LittleEndian.PutUint64(buf[8:], uint64(int64(pe.J)))
+		binary.LittleEndian.PutUint64(buf[16:], math.Float64bits(v))
+		h.Write(buf[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GraphHash is like Hash but depends only on a problem's structure: the
+// variables it contains and which pairs of them are coupled, not the values
+// of its coefficients.  Problems with equal graph hashes can therefore share
+// an embedding, and adding or removing a linear term on a variable that
+// already appears in a coupler does not change the graph hash.  GraphHash
+// can also identify a solver's working graph, as returned by
+// HardwareAdjacency.
+func (p Problem) GraphHash() string {
+	// Collect the sets of variables and of couplers.  A coupler between a
+	// variable and itself is a linear term and contributes only its
+	// variable.
+	varSet := make(map[int]struct{}, len(p))
+	cplSet := make(map[[2]int]struct{}, len(p))
+	for _, pe := range p {
+		varSet[pe.I] = struct{}{}
+		varSet[pe.J] = struct{}{}
+		switch {
+		case pe.I < pe.J:
+			cplSet[[2]int{pe.I, pe.J}] = struct{}{}
+		case pe.I > pe.J:
+			cplSet[[2]int{pe.J, pe.I}] = struct{}{}
+		}
+	}
+	vars := make([]int, 0, len(varSet))
+	for v := range varSet {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	cpls := make([][2]int, 0, len(cplSet))
+	for c := range cplSet {
+		cpls = append(cpls, c)
+	}
+	sort.Slice(cpls, func(i, j int) bool {
+		if cpls[i][0] != cpls[j][0] {
+			return cpls[i][0] < cpls[j][0]
+		}
+		return cpls[i][1] < cpls[j][1]
+	})
+
+	// Hash the number of variables, the variables, and the couplers.
+	h := sha256.New()
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[0:], uint64(len(vars)))
+	h.Write(buf[:8])
+	for _, v := range vars {
+		binary.LittleEndian.PutUint64(buf[0:], uint64(int64(v)))
+		h.Write(buf[:8])
+	}
+	for _, c := range cpls {
+		binary.LittleEndian.PutUint64(buf[0:], uint64(int64(c[0])))
+		binary.LittleEndian.PutUint64(buf[8:], uint64(int64(c[1])))
+		h.Write(buf[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
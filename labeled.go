@@ -0,0 +1,81 @@
+// This file supports problems whose variables are identified by string
+// labels rather than by integer indices.
+
+package sapi
+
+// A LabeledEntry is the analogue of a ProblemEntry for a LabeledProblem.  If
+// U and V are equal, the entry represents a linear term; otherwise, it
+// represents a quadratic term.
+type LabeledEntry struct {
+	U     string  // Label of the first variable
+	V     string  // Label of the second variable
+	Value float64 // Coefficient
+}
+
+// A LabeledProblem is a Problem whose variables are identified by arbitrary
+// string labels.
+type LabeledProblem []LabeledEntry
+
+// Index converts a LabeledProblem to a Problem.  Integer indices are assigned
+// to labels in order of their first appearance.  Index returns the Problem
+// and a slice mapping each index back to its label.
+func (lp LabeledProblem) Index() (Problem, []string) {
+	idx := make(map[string]int, len(lp))
+	labels := make([]string, 0, len(lp))
+	lookup := func(lbl string) int {
+		if i, ok := idx[lbl]; ok {
+			return i
+		}
+		i := len(labels)
+		idx[lbl] = i
+		labels = append(labels, lbl)
+		return i
+	}
+	p := make(Problem, len(lp))
+	for i, le := range lp {
+		p[i] = ProblemEntry{I: lookup(le.U), J: lookup(le.V), Value: le.Value}
+	}
+	return p, labels
+}
+
+// A LabeledResult is an IsingResult whose solutions can be interpreted in
+// terms of the labels of a LabeledProblem.
+type LabeledResult struct {
+	IsingResult
+	Labels []string // Label corresponding to each solution index
+}
+
+// Sample returns the ith solution as a map from label to value.
+func (lr LabeledResult) Sample(i int) map[string]int8 {
+	soln := lr.Solutions[i]
+	m := make(map[string]int8, len(lr.Labels))
+	for v, lbl := range lr.Labels {
+		if v < len(soln) {
+			m[lbl] = soln[v]
+		}
+	}
+	return m
+}
+
+// SolveLabeledIsing solves an Ising-model LabeledProblem on a given sampler.
+// The problem is converted to a Problem using Index before submission, so
+// the sampler must accept variables numbered from zero (e.g., an
+// EmbeddingComposite or an ExactSolver rather than a physical Solver).
+func SolveLabeledIsing(s Sampler, lp LabeledProblem, sp SolverParameters) (LabeledResult, error) {
+	p, labels := lp.Index()
+	ir, err := s.SolveIsing(p, sp)
+	if err != nil {
+		return LabeledResult{}, err
+	}
+	return LabeledResult{IsingResult: ir, Labels: labels}, nil
+}
+
+// SolveLabeledQubo is the QUBO analogue of SolveLabeledIsing.
+func SolveLabeledQubo(s Sampler, lp LabeledProblem, sp SolverParameters) (LabeledResult, error) {
+	p, labels := lp.Index()
+	ir, err := s.SolveQubo(p, sp)
+	if err != nil {
+		return LabeledResult{}, err
+	}
+	return LabeledResult{IsingResult: ir, Labels: labels}, nil
+}
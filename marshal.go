@@ -0,0 +1,190 @@
+// This file provides JSON encodings of various sapi types.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// postprocessingNames maps each Postprocessing value to a textual name.
+var postprocessingNames = map[Postprocessing]string{
+	PostprocessNone:         "none",
+	PostprocessSampling:     "sampling",
+	PostprocessOptimization: "optimization",
+}
+
+// String returns a textual representation of a Postprocessing value.
+func (pp Postprocessing) String() string {
+	if nm, ok := postprocessingNames[pp]; ok {
+		return nm
+	}
+	return "unknown"
+}
+
+// MarshalText encodes a Postprocessing value as text.
+func (pp Postprocessing) MarshalText() ([]byte, error) {
+	nm, ok := postprocessingNames[pp]
+	if !ok {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unrecognized postprocessing type %d", int(pp))
+	}
+	return []byte(nm), nil
+}
+
+// UnmarshalText decodes a Postprocessing value from text.
+func (pp *Postprocessing) UnmarshalText(text []byte) error {
+	for v, nm := range postprocessingNames {
+		if nm == string(text) {
+			*pp = v
+			return nil
+		}
+	}
+	return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unrecognized postprocessing type %q", string(text))
+}
+
+// solverPropertiesJSON has the same fields as SolverProperties but not its
+// methods.  This prevents MarshalJSON and UnmarshalJSON from recursing.
+type solverPropertiesJSON SolverProperties
+
+// MarshalJSON encodes a SolverProperties as JSON.  Lists of qubits, couplers,
+// and parameters are written in a canonical order so that the JSON
+// representations of two snapshots of the same solver can be compared
+// textually.
+func (sp *SolverProperties) MarshalJSON() ([]byte, error) {
+	// Copy the properties, sorting lists as we go.
+	spj := solverPropertiesJSON(*sp)
+	spj.SupportedProblemTypes = sortedStrings(sp.SupportedProblemTypes)
+	spj.Parameters = sortedStrings(sp.Parameters)
+	if qp := sp.QuantumProps; qp != nil {
+		// Sort qubits in ascending order and couplers in
+		// lexicographic order, with the smaller qubit number first.
+		qubits := make([]int, len(qp.Qubits))
+		copy(qubits, qp.Qubits)
+		sort.Ints(qubits)
+		couplers := make([][2]int, len(qp.Couplers))
+		for i, c := range qp.Couplers {
+			if c[0] > c[1] {
+				c[0], c[1] = c[1], c[0]
+			}
+			couplers[i] = c
+		}
+		sort.Slice(couplers, func(i, j int) bool {
+			if couplers[i][0] != couplers[j][0] {
+				return couplers[i][0] < couplers[j][0]
+			}
+			return couplers[i][1] < couplers[j][1]
+		})
+		spj.QuantumProps = &QuantumSolverProperties{
+			NumQubits: qp.NumQubits,
+			Qubits:    qubits,
+			Couplers:  couplers,
+		}
+	}
+	return json.Marshal(spj)
+}
+
+// UnmarshalJSON decodes a SolverProperties from JSON.
+func (sp *SolverProperties) UnmarshalJSON(data []byte) error {
+	var spj solverPropertiesJSON
+	if err := json.Unmarshal(data, &spj); err != nil {
+		return err
+	}
+	*sp = SolverProperties(spj)
+	return nil
+}
+
+// sortedStrings returns a sorted copy of a slice of strings.
+func sortedStrings(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+	sorted := make([]string, len(ss))
+	copy(sorted, ss)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// parametersTypeName returns a name for the concrete type of a
+// SolverParameters.
+func parametersTypeName(sp SolverParameters) string {
+	switch sp.(type) {
+	case *SwOptimizeSolverParameters:
+		return "sw_optimize"
+	case *SwSampleSolverParameters:
+		return "sw_sample"
+	case *SwHeuristicSolverParameters:
+		return "heuristic"
+	case *QuantumSolverParameters:
+		return "quantum"
+	default:
+		return ""
+	}
+}
+
+// newParametersByTypeName returns a default-initialized SolverParameters
+// given a name returned by parametersTypeName.
+func newParametersByTypeName(name string) (SolverParameters, error) {
+	switch name {
+	case "sw_optimize":
+		return newSwOptimizeSolverParameters(), nil
+	case "sw_sample":
+		return newSwSampleSolverParameters(), nil
+	case "heuristic":
+		return newSwHeuristicSolverParameters(), nil
+	case "quantum":
+		return newQuantumSolverParameters(), nil
+	default:
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unrecognized solver-parameters type %q", name)
+	}
+}
+
+// solverParametersJSON is the JSON representation of a SolverParameters
+// tagged with its concrete type.
+type solverParametersJSON struct {
+	Type       string          `json:"type"`       // Name of the concrete type ("sw_optimize", "sw_sample", "heuristic", or "quantum")
+	Parameters json.RawMessage `json:"parameters"` // The parameters themselves
+}
+
+// MarshalSolverParameters encodes a SolverParameters as JSON, recording its
+// concrete type so that UnmarshalSolverParameters can reconstruct it.  This
+// lets experiment configurations be stored alongside their results and re-run
+// exactly.
+func MarshalSolverParameters(sp SolverParameters) ([]byte, error) {
+	name := parametersTypeName(sp)
+	if name == "" {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unrecognized solver-parameters type %T", sp)
+	}
+	params, err := json.Marshal(sp)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(solverParametersJSON{Type: name, Parameters: params})
+}
+
+// UnmarshalSolverParameters decodes a SolverParameters produced by
+// MarshalSolverParameters into a value of the recorded concrete type.  Fields
+// absent from the encoding retain SAPI's defaults.  The decoded parameters
+// are not associated with any solver, so setters such as SetAnnealOffsets
+// perform only solver-independent checks on them.
+func UnmarshalSolverParameters(data []byte) (SolverParameters, error) {
+	var spj solverParametersJSON
+	if err := json.Unmarshal(data, &spj); err != nil {
+		return nil, err
+	}
+	sp, err := newParametersByTypeName(spj.Type)
+	if err != nil {
+		return nil, err
+	}
+	if len(spj.Parameters) > 0 {
+		if err = json.Unmarshal(spj.Parameters, sp); err != nil {
+			return nil, err
+		}
+	}
+	return sp, nil
+}
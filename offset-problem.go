@@ -0,0 +1,84 @@
+// This file provides a problem type that carries its own energy offset.
+
+package sapi
+
+// An OffsetProblem is a Problem together with a constant energy offset.  The
+// energy of a solution to an OffsetProblem is the energy of the solution to
+// Problem plus Offset.  Transformations such as ToIsing, ToQubo, and
+// FixVariables fold the energy differences they introduce into Offset, and
+// the Solve methods add Offset to every returned energy, so energies are
+// always reported in the frame of the original problem.
+type OffsetProblem struct {
+	Problem Problem // Problem without the constant term
+	Offset  float64 // Constant energy offset
+}
+
+// BuildOffsetProblem returns the accumulated problem (see Build) together
+// with the builder's offset.
+func (pb *ProblemBuilder) BuildOffsetProblem() OffsetProblem {
+	return OffsetProblem{Problem: pb.Build(), Offset: pb.offset}
+}
+
+// Canonicalize canonicalizes the problem (see Problem.Canonicalize) and
+// retains the offset.
+func (op OffsetProblem) Canonicalize() OffsetProblem {
+	return OffsetProblem{Problem: op.Problem.Canonicalize(), Offset: op.Offset}
+}
+
+// ToIsing converts a QUBO OffsetProblem to an Ising-model OffsetProblem with
+// the same energies.
+func (op OffsetProblem) ToIsing() OffsetProblem {
+	ip, ofs := op.Problem.ToIsing()
+	return OffsetProblem{Problem: ip, Offset: op.Offset + ofs}
+}
+
+// ToQubo converts an Ising-model OffsetProblem to a QUBO OffsetProblem with
+// the same energies.
+func (op OffsetProblem) ToQubo() OffsetProblem {
+	qp, ofs := op.Problem.ToQubo()
+	return OffsetProblem{Problem: qp, Offset: op.Offset + ofs}
+}
+
+// FixVariables applies Problem.FixVariables to a QUBO OffsetProblem.  It
+// returns the simplified problem, with the energy of the fixed variables
+// folded into its offset, and a map from each fixed variable to its value.
+func (op OffsetProblem) FixVariables(m FixVariablesMethod) (OffsetProblem, map[int]int8, error) {
+	fvr, err := op.Problem.FixVariables(m)
+	if err != nil {
+		return OffsetProblem{}, nil, err
+	}
+	return OffsetProblem{Problem: fvr.NewProblem, Offset: op.Offset + fvr.Offset}, fvr.FixedVars, nil
+}
+
+// IsingEnergy returns the energy, including the offset, of a solution to an
+// Ising-model OffsetProblem.
+func (op OffsetProblem) IsingEnergy(s []int8) float64 {
+	return op.Problem.IsingEnergy(s) + op.Offset
+}
+
+// QuboEnergy returns the energy, including the offset, of a solution to a
+// QUBO OffsetProblem.
+func (op OffsetProblem) QuboEnergy(s []int8) float64 {
+	return op.Problem.QuboEnergy(s) + op.Offset
+}
+
+// SolveIsing solves an Ising-model OffsetProblem on a given sampler.  The
+// offset is added to every returned energy.
+func (op OffsetProblem) SolveIsing(s Sampler, sp SolverParameters) (IsingResult, error) {
+	ir, err := s.SolveIsing(op.Problem, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	ir.AddOffset(op.Offset)
+	return ir, nil
+}
+
+// SolveQubo is the QUBO analogue of SolveIsing.
+func (op OffsetProblem) SolveQubo(s Sampler, sp SolverParameters) (IsingResult, error) {
+	ir, err := s.SolveQubo(op.Problem, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	ir.AddOffset(op.Offset)
+	return ir, nil
+}
@@ -0,0 +1,59 @@
+// This file lets a Connection supply default solver parameters to all of its
+// solvers.
+
+package sapi
+
+// A ParameterDefaults specifies default values for the solver parameters that
+// are common to multiple solver types.  Zero-valued fields leave the
+// corresponding SAPI default in place.  Fields that do not apply to a given
+// solver type are ignored for that type.
+type ParameterDefaults struct {
+	NumReads      int                        // Number of samples to take
+	MaxAnswers    int                        // Maximum number of answers to return
+	AnswerMode    *SolverParameterAnswerMode // Whether to return individual answers or a histogram
+	AnnealingTime int                        // Annealing time in microseconds (quantum solvers only)
+	AutoScale     *bool                      // Scale coefficients to their maximum range (quantum solvers only)
+}
+
+// apply overwrites the fields of a SolverParameters with all non-zero
+// defaults.
+func (d *ParameterDefaults) apply(sp SolverParameters) {
+	switch sp := sp.(type) {
+	case *SwOptimizeSolverParameters:
+		if d.NumReads > 0 {
+			sp.NumReads = d.NumReads
+		}
+		if d.MaxAnswers > 0 {
+			sp.MaxAnswers = d.MaxAnswers
+		}
+		if d.AnswerMode != nil {
+			sp.AnswerMode = *d.AnswerMode
+		}
+	case *SwSampleSolverParameters:
+		if d.NumReads > 0 {
+			sp.NumReads = d.NumReads
+		}
+		if d.MaxAnswers > 0 {
+			sp.MaxAnswers = d.MaxAnswers
+		}
+		if d.AnswerMode != nil {
+			sp.AnswerMode = *d.AnswerMode
+		}
+	case *QuantumSolverParameters:
+		if d.NumReads > 0 {
+			sp.NumReads = d.NumReads
+		}
+		if d.MaxAnswers > 0 {
+			sp.MaxAnswers = d.MaxAnswers
+		}
+		if d.AnswerMode != nil {
+			sp.AnswerMode = *d.AnswerMode
+		}
+		if d.AnnealingTime > 0 {
+			sp.AnnealingTime = d.AnnealingTime
+		}
+		if d.AutoScale != nil {
+			sp.AutoScale = *d.AutoScale
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// This file provides a functional-option means of constructing solver
+// parameters.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+// A ParameterOption modifies a set of solver parameters.  It returns an error
+// if the parameter it sets does not apply to the given type of parameters.
+type ParameterOption func(SolverParameters) error
+
+// NewParameters returns a sampler's default solver parameters modified by a
+// list of options.  This replaces type-switching on the result of
+// NewSolverParameters.  It fails if any option does not apply to the
+// sampler's type of parameters.
+func NewParameters(s Sampler, opts ...ParameterOption) (SolverParameters, error) {
+	return applyOptions(s.NewSolverParameters(), opts)
+}
+
+// applyOptions returns a copy of a set of solver parameters modified by a
+// list of options.  The original parameters are left unmodified.
+func applyOptions(base SolverParameters, opts []ParameterOption) (SolverParameters, error) {
+	sp := base.Clone()
+	for _, opt := range opts {
+		if err := opt(sp); err != nil {
+			return nil, err
+		}
+	}
+	return sp, nil
+}
+
+// SolveIsingWith solves an Ising-model problem using a copy of a base set of
+// solver parameters modified by a list of options.  Because the base
+// parameters are never modified, they can be shared safely among concurrent
+// calls that need different settings.
+func (s *Solver) SolveIsingWith(p Problem, base SolverParameters, overrides ...ParameterOption) (IsingResult, error) {
+	sp, err := applyOptions(base, overrides)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return s.SolveIsing(p, sp)
+}
+
+// SolveQuboWith is the QUBO analogue of SolveIsingWith.
+func (s *Solver) SolveQuboWith(p Problem, base SolverParameters, overrides ...ParameterOption) (IsingResult, error) {
+	sp, err := applyOptions(base, overrides)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return s.SolveQubo(p, sp)
+}
+
+// inapplicable returns an error reporting that a parameter does not apply to
+// a given type of solver parameters.
+func inapplicable(sp SolverParameters, name string) error {
+	if tn := parametersTypeName(sp); tn != "" {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The %s parameter does not apply to %s solver parameters", name, tn)
+	}
+	return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The %s parameter does not apply to parameters of type %T", name, sp)
+}
+
+// WithNumReads sets the number of samples to take.
+func WithNumReads(n int) ParameterOption {
+	return func(sp SolverParameters) error {
+		switch sp := sp.(type) {
+		case *SwOptimizeSolverParameters:
+			sp.NumReads = n
+		case *SwSampleSolverParameters:
+			sp.NumReads = n
+		case *QuantumSolverParameters:
+			sp.NumReads = n
+		default:
+			return inapplicable(sp, "num_reads")
+		}
+		return nil
+	}
+}
+
+// WithMaxAnswers sets the maximum number of answers to return.
+func WithMaxAnswers(n int) ParameterOption {
+	return func(sp SolverParameters) error {
+		if err := checkMaxAnswers(n); err != nil {
+			return err
+		}
+		switch sp := sp.(type) {
+		case *SwOptimizeSolverParameters:
+			sp.MaxAnswers = n
+		case *SwSampleSolverParameters:
+			sp.MaxAnswers = n
+		case *QuantumSolverParameters:
+			sp.MaxAnswers = n
+		default:
+			return inapplicable(sp, "max_answers")
+		}
+		return nil
+	}
+}
+
+// WithAnswerMode sets whether to return individual answers or a histogram.
+func WithAnswerMode(m SolverParameterAnswerMode) ParameterOption {
+	return func(sp SolverParameters) error {
+		switch sp := sp.(type) {
+		case *SwOptimizeSolverParameters:
+			sp.AnswerMode = m
+		case *SwSampleSolverParameters:
+			sp.AnswerMode = m
+		case *QuantumSolverParameters:
+			sp.AnswerMode = m
+		default:
+			return inapplicable(sp, "answer_mode")
+		}
+		return nil
+	}
+}
+
+// WithBeta sets the Boltzmann distribution parameter.
+func WithBeta(beta float64) ParameterOption {
+	return func(sp SolverParameters) error {
+		switch sp := sp.(type) {
+		case *SwSampleSolverParameters:
+			sp.Beta = beta
+		case *QuantumSolverParameters:
+			sp.Beta = beta
+		default:
+			return inapplicable(sp, "beta")
+		}
+		return nil
+	}
+}
+
+// WithRandomSeed seeds a software solver's random-number generator.
+func WithRandomSeed(seed uint) ParameterOption {
+	return func(sp SolverParameters) error {
+		switch sp := sp.(type) {
+		case *SwSampleSolverParameters:
+			sp.UseRandomSeed = true
+			sp.RandomSeed = seed
+		case *SwHeuristicSolverParameters:
+			sp.UseRandomSeed = true
+			sp.RandomSeed = seed
+		default:
+			return inapplicable(sp, "random_seed")
+		}
+		return nil
+	}
+}
+
+// quantumOption returns a ParameterOption that applies only to quantum
+// solver parameters.
+func quantumOption(name string, set func(*QuantumSolverParameters) error) ParameterOption {
+	return func(sp SolverParameters) error {
+		qsp, ok := sp.(*QuantumSolverParameters)
+		if !ok {
+			return inapplicable(sp, name)
+		}
+		return set(qsp)
+	}
+}
+
+// WithAnnealingTime sets the annealing time in microseconds.
+func WithAnnealingTime(us int) ParameterOption {
+	return quantumOption("annealing_time", func(qsp *QuantumSolverParameters) error {
+		qsp.AnnealingTime = us
+		return nil
+	})
+}
+
+// WithAutoScale sets whether to scale coefficients to their maximum range.
+func WithAutoScale(b bool) ParameterOption {
+	return quantumOption("auto_scale", func(qsp *QuantumSolverParameters) error {
+		qsp.AutoScale = b
+		return nil
+	})
+}
+
+// WithNumSpinReversals sets the number of spin-reversal transformations to
+// perform.
+func WithNumSpinReversals(n int) ParameterOption {
+	return quantumOption("num_spin_reversal_transforms", func(qsp *QuantumSolverParameters) error {
+		qsp.NumSpinReversals = n
+		return nil
+	})
+}
+
+// WithPostprocess sets the type of classical postprocessing to perform.
+func WithPostprocess(pp Postprocessing) ParameterOption {
+	return quantumOption("postprocess", func(qsp *QuantumSolverParameters) error {
+		qsp.Postprocess = pp
+		return nil
+	})
+}
+
+// WithPostprocessing requests server-side postprocessing using
+// SetPostprocessing.
+func WithPostprocessing(pp Postprocessing, beta float64, chains Embeddings) ParameterOption {
+	return quantumOption("postprocess", func(qsp *QuantumSolverParameters) error {
+		return qsp.SetPostprocessing(pp, beta, chains)
+	})
+}
+
+// WithAnnealOffsets sets per-qubit anneal offsets using SetAnnealOffsets.
+func WithAnnealOffsets(offsets []float64) ParameterOption {
+	return quantumOption("anneal_offsets", func(qsp *QuantumSolverParameters) error {
+		return qsp.SetAnnealOffsets(offsets)
+	})
+}
+
+// WithAnnealSchedule sets a piecewise-linear anneal schedule using
+// SetAnnealSchedule.
+func WithAnnealSchedule(sched []SchedulePoint) ParameterOption {
+	return quantumOption("anneal_schedule", func(qsp *QuantumSolverParameters) error {
+		return qsp.SetAnnealSchedule(sched)
+	})
+}
+
+// WithFluxBiases sets per-qubit flux-bias offsets using SetFluxBiases.
+func WithFluxBiases(biases []float64) ParameterOption {
+	return quantumOption("flux_biases", func(qsp *QuantumSolverParameters) error {
+		return qsp.SetFluxBiases(biases)
+	})
+}
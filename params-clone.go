@@ -0,0 +1,33 @@
+// This file provides deep copies of each type of SolverParameters.
+
+package sapi
+
+// Clone returns a deep copy of a SwOptimizeSolverParameters.
+func (p *SwOptimizeSolverParameters) Clone() SolverParameters {
+	c := *p
+	return &c
+}
+
+// Clone returns a deep copy of a SwSampleSolverParameters.
+func (p *SwSampleSolverParameters) Clone() SolverParameters {
+	c := *p
+	return &c
+}
+
+// Clone returns a deep copy of a SwHeuristicSolverParameters.
+func (p *SwHeuristicSolverParameters) Clone() SolverParameters {
+	c := *p
+	return &c
+}
+
+// Clone returns a deep copy of a QuantumSolverParameters.  The copy remains
+// associated with the same solver, so its setters validate against the same
+// solver properties.
+func (p *QuantumSolverParameters) Clone() SolverParameters {
+	c := *p
+	c.Chains = append([]int(nil), p.Chains...)
+	c.AnnealOffsets = append([]float64(nil), p.AnnealOffsets...)
+	c.FluxBiases = append([]float64(nil), p.FluxBiases...)
+	c.AnnealSchedule = append([]SchedulePoint(nil), p.AnnealSchedule...)
+	return &c
+}
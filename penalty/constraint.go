@@ -0,0 +1,95 @@
+// This file compiles linear constraints on binary variables into penalty
+// functions.
+
+package penalty
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// linearTerms returns the variables of a linear function in increasing order
+// together with their coefficients.
+func linearTerms(coeffs map[int]float64) ([]int, []float64) {
+	vars := make([]int, 0, len(coeffs))
+	for v := range coeffs {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	cs := make([]float64, len(vars))
+	for i, v := range vars {
+		cs[i] = coeffs[v]
+	}
+	return vars, cs
+}
+
+// integral reports whether every number in a list is an integer.
+func integral(xs ...float64) bool {
+	for _, x := range xs {
+		if x != math.Trunc(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// weighted multiplies a gadget's penalty, offset, and gap by a weight.
+func (g Gadget) weighted(w float64) Gadget {
+	g.Problem = g.Problem.Scale(w)
+	g.Offset *= w
+	g.Gap *= w
+	return g
+}
+
+// ConstraintEq returns a gadget that penalizes violations of the constraint
+// Σ coeffs[v]·x_v = rhs on binary variables x_v.  The penalty is
+// weight·(Σ coeffs[v]·x_v - rhs)².  If all coefficients and rhs are
+// integers, the gadget's gap is weight; otherwise, the gap is reported as 0
+// because it depends on the values involved.  See SuggestWeight for a means
+// of choosing the weight.
+func ConstraintEq(coeffs map[int]float64, rhs, weight float64) Gadget {
+	vars, cs := linearTerms(coeffs)
+	g := squared(vars, cs, -rhs)
+	if !integral(append(cs, rhs)...) {
+		g.Gap = 0
+	}
+	return g.weighted(weight)
+}
+
+// ConstraintLe returns a gadget that penalizes violations of the constraint
+// Σ coeffs[v]·x_v ≤ rhs on binary variables x_v.  The coefficients must be
+// integers.  The inequality is converted to the equality
+// Σ coeffs[v]·x_v + s = ⌊rhs⌋, where s is a non-negative integer slack
+// variable encoded with BinaryInt in binary variables numbered consecutively
+// from firstSlack.  The slack variables are reported as the gadget's
+// Ancillas.  The gadget's gap is weight.  A constraint of the form
+// Σ coeffs[v]·x_v ≥ rhs can be expressed by negating the coefficients and
+// rhs.  ConstraintLe fails if the coefficients are not integers or if the
+// constraint cannot be satisfied.
+func ConstraintLe(coeffs map[int]float64, rhs, weight float64, firstSlack int) (Gadget, error) {
+	// Determine the range of the slack variable.
+	vars, cs := linearTerms(coeffs)
+	if !integral(cs...) {
+		return Gadget{}, fmt.Errorf("ConstraintLe requires integer coefficients")
+	}
+	minLHS := 0.0
+	for _, c := range cs {
+		minLHS += math.Min(c, 0)
+	}
+	bound := math.Floor(rhs)
+	if bound < minLHS {
+		return Gadget{}, fmt.Errorf("The left-hand side cannot be less than %v, so it cannot be at most %v", minLHS, rhs)
+	}
+
+	// Add the slack variable's terms to the left-hand side and convert the
+	// resulting equality to a penalty.
+	slack := BinaryInt(firstSlack, 0, int(bound-minLHS))
+	for i, v := range slack.Vars {
+		vars = append(vars, v)
+		cs = append(cs, float64(slack.Coeffs[i]))
+	}
+	g := squared(vars, cs, -bound)
+	g.Ancillas = slack.Vars
+	return g.weighted(weight), nil
+}
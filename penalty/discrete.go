@@ -0,0 +1,90 @@
+// This file provides encodings of discrete variables in binary variables.
+
+package penalty
+
+import (
+	"github.com/lanl/sapi"
+)
+
+// A DiscreteVar represents a variable that takes one of d values, 0 through
+// d-1, encoded in a set of binary variables.  Its embedded Gadget penalizes
+// binary assignments that do not correspond to any value.  Scale the penalty
+// (e.g., with Problem.Scale) to exceed the magnitude of whatever objective
+// function it is added to.
+type DiscreteVar struct {
+	Gadget
+	Vars     []int // Variable numbers of the binary variables
+	NumVals  int   // Number of values the discrete variable can take
+	isOneHot bool  // true for one-hot encoding; false for domain-wall encoding
+}
+
+// OneHot encodes a discrete variable with len(vars) values using one binary
+// variable per value.  Value i is represented by vars[i] = 1 and all other
+// variables 0.  The penalty is (Σ vars - 1)², which has a gap of 1.
+func OneHot(vars []int) DiscreteVar {
+	coeffs := make([]float64, len(vars))
+	for i := range coeffs {
+		coeffs[i] = 1
+	}
+	return DiscreteVar{
+		Gadget:   squared(vars, coeffs, -1),
+		Vars:     append([]int(nil), vars...),
+		NumVals:  len(vars),
+		isOneHot: true,
+	}
+}
+
+// DomainWall encodes a discrete variable with len(vars)+1 values using one
+// fewer binary variable than OneHot.  Value i is represented by vars[0]
+// through vars[i-1] = 1 and the remaining variables 0, so the value is the
+// position of the "domain wall" between the 1s and the 0s.  The penalty is
+// Σ (vars[i+1] - vars[i]·vars[i+1]), which penalizes each 0 that precedes a
+// 1 and has a gap of 1.
+func DomainWall(vars []int) DiscreteVar {
+	var pb sapi.ProblemBuilder
+	for _, v := range vars {
+		pb.AddLinear(v, 0)
+	}
+	for i := 0; i+1 < len(vars); i++ {
+		pb.AddLinear(vars[i+1], 1)
+		pb.AddQuadratic(vars[i], vars[i+1], -1)
+	}
+	return DiscreteVar{
+		Gadget:  Gadget{Problem: pb.Build(), Gap: 1},
+		Vars:    append([]int(nil), vars...),
+		NumVals: len(vars) + 1,
+	}
+}
+
+// Decode maps a solution (0/1 values indexed by variable number) to the
+// discrete variable's value.  It returns false if the binary variables do
+// not represent a valid value.
+func (dv DiscreteVar) Decode(soln []int8) (int, bool) {
+	if dv.isOneHot {
+		val := -1
+		for i, v := range dv.Vars {
+			switch soln[v] {
+			case 0:
+			case 1:
+				if val >= 0 {
+					return 0, false
+				}
+				val = i
+			default:
+				return 0, false
+			}
+		}
+		return val, val >= 0
+	}
+	val := 0
+	for i, v := range dv.Vars {
+		switch {
+		case soln[v] == 1 && val == i:
+			val++
+		case soln[v] == 0:
+		default:
+			return 0, false
+		}
+	}
+	return val, true
+}
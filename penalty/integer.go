@@ -0,0 +1,123 @@
+// This file provides encodings of integer variables in binary variables.
+
+package penalty
+
+import (
+	"fmt"
+	"github.com/lanl/sapi"
+	"math"
+)
+
+// An IntVar represents an integer variable x in [Lo, Hi] encoded as
+// x = Lo + Σ Coeffs[i]·Vars[i], where each Vars[i] is a binary variable.
+// Every binary assignment represents a value in [Lo, Hi], and every value in
+// [Lo, Hi] is represented by at least one assignment, so the encoding
+// requires no penalty of its own.
+type IntVar struct {
+	First  int   // Variable number of the first binary variable
+	Vars   []int // Variable numbers of the binary variables
+	Coeffs []int // Weight of each binary variable
+	Lo     int   // Minimum value
+	Hi     int   // Maximum value
+}
+
+// newIntVar constructs an IntVar over consecutive variables starting from
+// first, given the weight of each binary variable.
+func newIntVar(first, lo, hi int, coeffs []int) IntVar {
+	vars := make([]int, len(coeffs))
+	for i := range vars {
+		vars[i] = first + i
+	}
+	return IntVar{First: first, Vars: vars, Coeffs: coeffs, Lo: lo, Hi: hi}
+}
+
+// UnaryInt encodes an integer in [lo, hi] using hi - lo binary variables,
+// numbered consecutively from first, each of weight 1.  The unary encoding
+// requires the most variables but has the smallest coefficients and the
+// most redundancy.
+func UnaryInt(first, lo, hi int) IntVar {
+	coeffs := make([]int, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		coeffs = append(coeffs, 1)
+	}
+	return newIntVar(first, lo, hi, coeffs)
+}
+
+// BinaryInt encodes an integer in [lo, hi] using ⌈log₂(hi - lo + 1)⌉ binary
+// variables, numbered consecutively from first, with weights 1, 2, 4, ...
+// The final weight is reduced if necessary so that the encoding cannot
+// exceed hi.
+func BinaryInt(first, lo, hi int) IntVar {
+	return boundedInt(first, lo, hi, math.MaxInt32)
+}
+
+// BoundedInt is like BinaryInt but limits each weight to at most maxCoeff,
+// adding binary variables of weight maxCoeff as needed.  This trades
+// additional variables for a smaller dynamic range of coefficients, which
+// matters on hardware with limited precision.  maxCoeff must be positive.
+func BoundedInt(first, lo, hi, maxCoeff int) (IntVar, error) {
+	if maxCoeff <= 0 {
+		return IntVar{}, fmt.Errorf("BoundedInt requires a positive maximum coefficient, not %d", maxCoeff)
+	}
+	return boundedInt(first, lo, hi, maxCoeff), nil
+}
+
+// boundedInt implements BoundedInt for a maxCoeff already known to be
+// positive.
+func boundedInt(first, lo, hi, maxCoeff int) IntVar {
+	var coeffs []int
+	sum := 0
+	for c := 1; sum < hi-lo; c *= 2 {
+		if c > maxCoeff {
+			c = maxCoeff
+		}
+		if sum+c > hi-lo {
+			c = hi - lo - sum
+		}
+		coeffs = append(coeffs, c)
+		sum += c
+	}
+	return newIntVar(first, lo, hi, coeffs)
+}
+
+// Next returns the number of the first variable following the variables
+// used by an IntVar, which is convenient for allocating variables
+// consecutively.
+func (iv IntVar) Next() int {
+	return iv.First + len(iv.Vars)
+}
+
+// Linear returns the integer variable as a linear function of its binary
+// variables: a map from variable number to coefficient plus a constant.
+func (iv IntVar) Linear() (map[int]float64, float64) {
+	terms := make(map[int]float64, len(iv.Vars))
+	for i, v := range iv.Vars {
+		terms[v] += float64(iv.Coeffs[i])
+	}
+	return terms, float64(iv.Lo)
+}
+
+// Decode maps a solution (0/1 values indexed by variable number) to the
+// integer variable's value.
+func (iv IntVar) Decode(soln []int8) int {
+	x := iv.Lo
+	for i, v := range iv.Vars {
+		x += iv.Coeffs[i] * int(soln[v])
+	}
+	return x
+}
+
+// SuggestWeight suggests a penalty weight for constraints added to a QUBO
+// objective function.  The suggestion exceeds the difference between the
+// objective's largest and smallest possible values, so a penalty of at least
+// the weight (e.g., a constraint with a gap of 1 scaled by the weight)
+// outweighs any improvement in the objective that violating the constraint
+// could achieve.  The bound is conservative; smaller weights often work
+// better in practice because they use more of the hardware's precision.
+func SuggestWeight(obj sapi.Problem) float64 {
+	w := 0.0
+	for _, pe := range obj.Canonicalize() {
+		w += math.Abs(pe.Value)
+	}
+	return w + 1
+}
@@ -0,0 +1,105 @@
+// Package penalty provides pre-derived penalty models ("gadgets") for common
+// logic gates.  Each gadget is a QUBO problem whose energy is zero for every
+// assignment of its variables that is consistent with the gate and at least
+// the gadget's gap for every inconsistent assignment.  Gadgets are
+// parameterized by the variable numbers of the gate's inputs, outputs, and
+// any ancillary variables, so they can be combined with Problem.Add to build
+// larger circuits.  For example, the penalty for y = a ∧ b on variables 0, 1,
+// and 2 is simply
+//
+//	penalty.AND(0, 1, 2).Problem
+//
+// The package also provides one-hot and domain-wall encodings of discrete
+// variables in binary variables.
+package penalty
+
+import (
+	"github.com/lanl/sapi"
+)
+
+// A Gadget is a penalty model for a logic gate.
+type Gadget struct {
+	Problem  sapi.Problem // QUBO penalty function
+	Offset   float64      // Constant to add to Problem's energy so that valid assignments have energy zero
+	Gap      float64      // Minimum energy (including Offset) of an invalid assignment
+	Ancillas []int        // Variable numbers of ancillary variables
+}
+
+// Ising returns the Ising-model form of a gadget's penalty and the constant
+// to add to the Ising-model energy so that valid assignments have energy
+// zero.  The gap is unchanged.
+func (g Gadget) Ising() (sapi.Problem, float64) {
+	ip, ofs := g.Problem.ToIsing()
+	return ip, ofs + g.Offset
+}
+
+// squared returns a gadget representing (Σ cᵢxᵢ + k)², which is zero when
+// the linear equation Σ cᵢxᵢ + k = 0 holds and at least 1 otherwise when
+// all coefficients are integers.
+func squared(vars []int, coeffs []float64, k float64) Gadget {
+	var pb sapi.ProblemBuilder
+	for a, va := range vars {
+		pb.AddLinear(va, coeffs[a]*coeffs[a]+2*k*coeffs[a])
+		for b := a + 1; b < len(vars); b++ {
+			pb.AddQuadratic(va, vars[b], 2*coeffs[a]*coeffs[b])
+		}
+	}
+	return Gadget{Problem: pb.Build(), Offset: k * k, Gap: 1}
+}
+
+// NOT returns a gadget for y = ¬a.  It has a gap of 1 and no ancillas.
+func NOT(a, y int) Gadget {
+	return squared([]int{a, y}, []float64{1, 1}, -1)
+}
+
+// AND returns a gadget for y = a ∧ b.  Its penalty is 3y + ab - 2ay - 2by.
+// It has a gap of 1 and no ancillas.
+func AND(a, b, y int) Gadget {
+	var pb sapi.ProblemBuilder
+	pb.AddLinear(a, 0)
+	pb.AddLinear(b, 0)
+	pb.AddLinear(y, 3)
+	pb.AddQuadratic(a, b, 1)
+	pb.AddQuadratic(a, y, -2)
+	pb.AddQuadratic(b, y, -2)
+	return Gadget{Problem: pb.Build(), Gap: 1}
+}
+
+// OR returns a gadget for y = a ∨ b.  Its penalty is a + b + y + ab - 2ay -
+// 2by.  It has a gap of 1 and no ancillas.
+func OR(a, b, y int) Gadget {
+	var pb sapi.ProblemBuilder
+	pb.AddLinear(a, 1)
+	pb.AddLinear(b, 1)
+	pb.AddLinear(y, 1)
+	pb.AddQuadratic(a, b, 1)
+	pb.AddQuadratic(a, y, -2)
+	pb.AddQuadratic(b, y, -2)
+	return Gadget{Problem: pb.Build(), Gap: 1}
+}
+
+// XOR returns a gadget for y = a ⊕ b.  Because XOR has no quadratic penalty
+// on three variables, the gadget requires one ancilla, anc, which takes the
+// value a ∧ b.  Its penalty is (a + b - y - 2anc)².  It has a gap of 1.
+func XOR(a, b, y, anc int) Gadget {
+	g := squared([]int{a, b, y, anc}, []float64{1, 1, -1, -2}, 0)
+	g.Ancillas = []int{anc}
+	return g
+}
+
+// HalfAdder returns a gadget for a half adder with inputs a and b, sum s =
+// a ⊕ b, and carry c = a ∧ b.  Its penalty is (a + b - s - 2c)².  It has a
+// gap of 1 and no ancillas.
+func HalfAdder(a, b, s, c int) Gadget {
+	return squared([]int{a, b, s, c}, []float64{1, 1, -1, -2}, 0)
+}
+
+// Majority returns a gadget for y = maj(a, b, c), which is 1 if at least two
+// of its inputs are 1.  The gadget requires one ancilla, anc, which takes
+// the value a + b + c - 2y.  Its penalty is (a + b + c - 2y - anc)².  It has
+// a gap of 1.
+func Majority(a, b, c, y, anc int) Gadget {
+	g := squared([]int{a, b, c, y, anc}, []float64{1, 1, 1, -2, -1}, 0)
+	g.Ancillas = []int{anc}
+	return g
+}
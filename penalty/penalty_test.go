@@ -0,0 +1,188 @@
+// This file tests the penalty models provided by the penalty package.
+
+package penalty_test
+
+import (
+	"github.com/lanl/sapi"
+	"github.com/lanl/sapi/penalty"
+	"math"
+	"testing"
+)
+
+// checkGadget enumerates every assignment to a gadget's variables and
+// ensures that valid assignments have zero energy and invalid assignments
+// have at least the gadget's gap, in both QUBO and Ising form.  valid
+// reports whether an assignment (0/1 values indexed by variable number) is
+// consistent with the gate, ignoring ancillas.
+func checkGadget(t *testing.T, name string, g penalty.Gadget, nv int, valid func(x []int8) bool) {
+	ip, iofs := g.Ising()
+	minValid := math.Inf(1)
+	for bits := 0; bits < 1<<uint(nv); bits++ {
+		// Construct the assignment in both QUBO and Ising form.
+		x := make([]int8, nv)
+		s := make([]int8, nv)
+		for v := range x {
+			x[v] = int8((bits >> uint(v)) & 1)
+			s[v] = 2*x[v] - 1
+		}
+
+		// Check the assignment's energy.
+		e := g.Problem.QuboEnergy(x) + g.Offset
+		if ie := ip.IsingEnergy(s) + iofs; math.Abs(e-ie) > 1e-9 {
+			t.Fatalf("%s: QUBO energy %v and Ising energy %v differ for %v", name, e, ie, x)
+		}
+		switch {
+		case valid(x):
+			minValid = math.Min(minValid, e)
+		case e < g.Gap-1e-9:
+			t.Fatalf("%s: Invalid assignment %v has energy %v, which is less than the gap %v", name, x, e, g.Gap)
+		}
+	}
+	if math.Abs(minValid) > 1e-9 {
+		t.Fatalf("%s: Expected valid assignments to reach energy 0 but saw %v", name, minValid)
+	}
+}
+
+// TestGadgets ensures that each gadget implements its gate.
+func TestGadgets(t *testing.T) {
+	checkGadget(t, "NOT", penalty.NOT(0, 1), 2, func(x []int8) bool {
+		return x[1] == 1-x[0]
+	})
+	checkGadget(t, "AND", penalty.AND(0, 1, 2), 3, func(x []int8) bool {
+		return x[2] == x[0]&x[1]
+	})
+	checkGadget(t, "OR", penalty.OR(0, 1, 2), 3, func(x []int8) bool {
+		return x[2] == x[0]|x[1]
+	})
+	checkGadget(t, "XOR", penalty.XOR(0, 1, 2, 3), 4, func(x []int8) bool {
+		return x[2] == x[0]^x[1] && x[3] == x[0]&x[1]
+	})
+	checkGadget(t, "HalfAdder", penalty.HalfAdder(0, 1, 2, 3), 4, func(x []int8) bool {
+		return x[2] == x[0]^x[1] && x[3] == x[0]&x[1]
+	})
+	checkGadget(t, "Majority", penalty.Majority(0, 1, 2, 3, 4), 5, func(x []int8) bool {
+		n := x[0] + x[1] + x[2]
+		y := int8(0)
+		if n >= 2 {
+			y = 1
+		}
+		return x[3] == y && x[4] == n-2*y
+	})
+}
+
+// TestGadgetSolve ensures that solving an AND gadget with an exact solver
+// yields only the gate's truth table at zero energy.
+func TestGadgetSolve(t *testing.T) {
+	g := penalty.AND(0, 1, 2)
+	var es sapi.ExactSolver
+	res, err := es.SolveQubo(g.Problem, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, soln := range res.Solutions {
+		if res.Energies[i]+g.Offset == 0 && soln[2] != soln[0]&soln[1] {
+			t.Fatalf("Zero-energy solution %v violates AND", soln)
+		}
+	}
+}
+
+// TestDiscreteVar ensures that exactly the valid encodings of a discrete
+// variable have zero penalty and decode successfully.
+func TestDiscreteVar(t *testing.T) {
+	for _, dv := range []penalty.DiscreteVar{
+		penalty.OneHot([]int{0, 1, 2, 3}),
+		penalty.DomainWall([]int{0, 1, 2, 3}),
+	} {
+		seen := make(map[int]bool)
+		for bits := 0; bits < 16; bits++ {
+			x := make([]int8, 4)
+			for v := range x {
+				x[v] = int8((bits >> uint(v)) & 1)
+			}
+			e := dv.Problem.QuboEnergy(x) + dv.Offset
+			val, ok := dv.Decode(x)
+			switch {
+			case ok && e != 0:
+				t.Fatalf("Valid encoding %v of %d has energy %v", x, val, e)
+			case !ok && e < dv.Gap:
+				t.Fatalf("Invalid encoding %v has energy %v", x, e)
+			case ok:
+				seen[val] = true
+			}
+		}
+		if len(seen) != dv.NumVals {
+			t.Fatalf("Expected %d distinct values but saw %d", dv.NumVals, len(seen))
+		}
+	}
+}
+
+// TestIntVar ensures that each integer encoding represents exactly the
+// values in its range.
+func TestIntVar(t *testing.T) {
+	bnd, err := penalty.BoundedInt(0, 1, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iv := range []penalty.IntVar{
+		penalty.UnaryInt(0, -2, 3),
+		penalty.BinaryInt(0, -2, 10),
+		bnd,
+	} {
+		nv := len(iv.Vars)
+		seen := make(map[int]bool)
+		for bits := 0; bits < 1<<uint(nv); bits++ {
+			x := make([]int8, nv)
+			for v := range x {
+				x[v] = int8((bits >> uint(v)) & 1)
+			}
+			val := iv.Decode(x)
+			if val < iv.Lo || val > iv.Hi {
+				t.Fatalf("Encoding %v of %v decodes to out-of-range value %d", x, iv, val)
+			}
+			seen[val] = true
+		}
+		if len(seen) != iv.Hi-iv.Lo+1 {
+			t.Fatalf("Expected %d values from %v but saw %d", iv.Hi-iv.Lo+1, iv, len(seen))
+		}
+	}
+
+	// Ensure that an empty encoding still allocates from its first
+	// variable.
+	if n := penalty.UnaryInt(7, 3, 3).Next(); n != 7 {
+		t.Fatalf("Expected Next to return 7 but saw %d", n)
+	}
+
+	// Ensure that a nonpositive maximum coefficient is rejected.
+	if _, err := penalty.BoundedInt(0, 1, 20, 0); err == nil {
+		t.Fatal("BoundedInt failed to reject a zero maximum coefficient")
+	}
+}
+
+// TestConstraints ensures that equality and inequality constraints penalize
+// exactly the assignments that violate them.
+func TestConstraints(t *testing.T) {
+	// Test x0 + 2x1 - x2 = 1.
+	coeffs := map[int]float64{0: 1, 1: 2, 2: -1}
+	eq := penalty.ConstraintEq(coeffs, 1, 2)
+	checkGadget(t, "ConstraintEq", eq, 3, func(x []int8) bool {
+		return x[0]+2*x[1]-x[2] == 1
+	})
+
+	// Test 2x0 + 3x1 + x2 ≤ 4, with slack variables starting at 3.
+	coeffs = map[int]float64{0: 2, 1: 3, 2: 1}
+	le, err := penalty.ConstraintLe(coeffs, 4.5, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nv := le.Ancillas[len(le.Ancillas)-1] + 1
+	slack := penalty.BinaryInt(3, 0, 4)
+	checkGadget(t, "ConstraintLe", le, nv, func(x []int8) bool {
+		lhs := int(2*x[0] + 3*x[1] + x[2])
+		return lhs <= 4 && lhs+slack.Decode(x) == 4
+	})
+
+	// Ensure that an unsatisfiable constraint is rejected.
+	if _, err := penalty.ConstraintLe(coeffs, -1, 1, 3); err == nil {
+		t.Fatal("ConstraintLe failed to reject an unsatisfiable constraint")
+	}
+}
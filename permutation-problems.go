@@ -0,0 +1,252 @@
+// This file provides encoders of permutation problems, such as the
+// traveling-salesman problem, as QUBO problems.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"math"
+)
+
+// A PermutationEncoding is a QUBO encoding of a problem whose solutions are
+// permutations of N items.  Variable Var(i, p) is 1 if and only if item i is
+// placed in position p.  Weight is charged for each violation of the
+// constraints that every item occupies exactly one position and every
+// position holds exactly one item.  A valid solution's energy plus Offset
+// equals the cost of the corresponding permutation.
+type PermutationEncoding struct {
+	Problem Problem // QUBO problem to solve
+	Offset  float64 // Constant to add to each solution's energy
+	N       int     // Number of items and of positions
+	Weight  float64 // Weight of the permutation constraints
+}
+
+// Var returns the variable that indicates that item i is placed in position
+// p.
+func (enc *PermutationEncoding) Var(i, p int) int {
+	return i*enc.N + p
+}
+
+// newPermutationEncoding returns a PermutationEncoding of n items whose
+// Problem is populated with the permutation constraints.
+func newPermutationEncoding(n int, weight float64, pb *ProblemBuilder) *PermutationEncoding {
+	// Penalize each row and each column of the assignment matrix with other
+	// than exactly one 1: weight*(1 - Σx)² = weight*(1 - Σx + 2Σx·x').
+	enc := &PermutationEncoding{N: n, Weight: weight}
+	for a := 0; a < n; a++ {
+		for b := 0; b < n; b++ {
+			pb.AddLinear(enc.Var(a, b), -2*weight)
+			for c := b + 1; c < n; c++ {
+				pb.AddQuadratic(enc.Var(a, b), enc.Var(a, c), 2*weight)
+				pb.AddQuadratic(enc.Var(b, a), enc.Var(c, a), 2*weight)
+			}
+		}
+	}
+	enc.Offset = 2 * float64(n) * weight
+	return enc
+}
+
+// checkSquare returns an error if a matrix is not n×n.
+func checkSquare(name string, m [][]float64, n int) error {
+	if len(m) != n {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The %s matrix has %d rows instead of %d", name, len(m), n)
+	}
+	for i, row := range m {
+		if len(row) != n {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Row %d of the %s matrix has %d columns instead of %d", i, name, len(row), n)
+		}
+	}
+	return nil
+}
+
+// maxAbs returns the largest absolute value in a matrix.
+func maxAbs(m [][]float64) float64 {
+	mx := 0.0
+	for _, row := range m {
+		for _, v := range row {
+			mx = math.Max(mx, math.Abs(v))
+		}
+	}
+	return mx
+}
+
+// SuggestTSPWeight suggests a constraint weight for TSPToQubo.  The weight
+// is twice the largest distance, which exceeds the cost saved by removing a
+// city from a tour.  As with any penalty method, a larger weight favors
+// valid tours at the expense of a flatter energy landscape.
+func SuggestTSPWeight(dist [][]float64) float64 {
+	if w := 2 * maxAbs(dist); w > 0 {
+		return w
+	}
+	return 1
+}
+
+// TSPToQubo encodes the traveling-salesman problem on a matrix of distances
+// as a QUBO problem.  dist[i][j] is the distance from city i to city j;
+// the matrix need not be symmetric.  Position p of the permutation is the
+// pth city visited, and the tour returns from the last city to the first.
+// A weight of zero or less selects the weight suggested by
+// SuggestTSPWeight.
+func TSPToQubo(dist [][]float64, weight float64) (*PermutationEncoding, error) {
+	n := len(dist)
+	if err := checkSquare("distance", dist, n); err != nil {
+		return nil, err
+	}
+	if weight <= 0 {
+		weight = SuggestTSPWeight(dist)
+	}
+
+	// Charge dist[i][j] whenever city j immediately follows city i.
+	var pb ProblemBuilder
+	enc := newPermutationEncoding(n, weight, &pb)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i == j || dist[i][j] == 0 {
+				continue
+			}
+			for p := 0; p < n; p++ {
+				pb.AddQuadratic(enc.Var(i, p), enc.Var(j, (p+1)%n), dist[i][j])
+			}
+		}
+	}
+	enc.Problem = pb.Build()
+	return enc, nil
+}
+
+// TourLength returns the length of a closed tour that visits cities in the
+// given order.
+func TourLength(dist [][]float64, tour []int) float64 {
+	length := 0.0
+	for p, i := range tour {
+		length += dist[i][tour[(p+1)%len(tour)]]
+	}
+	return length
+}
+
+// SuggestQAPWeight suggests a constraint weight for QAPToQubo.  The weight
+// is the largest total flow into and out of any facility times the largest
+// distance, which bounds the cost saved by leaving a facility unassigned.
+func SuggestQAPWeight(flow, dist [][]float64) float64 {
+	mx := 0.0
+	for i := range flow {
+		f := 0.0
+		for j := range flow {
+			f += math.Abs(flow[i][j]) + math.Abs(flow[j][i])
+		}
+		mx = math.Max(mx, f)
+	}
+	if w := mx * maxAbs(dist); w > 0 {
+		return w
+	}
+	return 1
+}
+
+// QAPToQubo encodes the quadratic assignment problem as a QUBO problem.
+// Item i of the permutation is facility i, and position p is location p.
+// The cost of an assignment is the sum over all facilities i and j of
+// flow[i][j] times the distance between their locations.  A weight of zero
+// or less selects the weight suggested by SuggestQAPWeight.
+func QAPToQubo(flow, dist [][]float64, weight float64) (*PermutationEncoding, error) {
+	n := len(flow)
+	if err := checkSquare("flow", flow, n); err != nil {
+		return nil, err
+	}
+	if err := checkSquare("distance", dist, n); err != nil {
+		return nil, err
+	}
+	if weight <= 0 {
+		weight = SuggestQAPWeight(flow, dist)
+	}
+
+	// Charge flow[i][j]*dist[k][l] whenever facility i is at location k and
+	// facility j is at location l.  Because a valid solution places each
+	// facility at exactly one location, terms with i == j contribute only
+	// when k == l.
+	var pb ProblemBuilder
+	enc := newPermutationEncoding(n, weight, &pb)
+	for i := 0; i < n; i++ {
+		for k := 0; k < n; k++ {
+			if v := flow[i][i] * dist[k][k]; v != 0 {
+				pb.AddLinear(enc.Var(i, k), v)
+			}
+		}
+		for j := 0; j < n; j++ {
+			if i == j || flow[i][j] == 0 {
+				continue
+			}
+			for k := 0; k < n; k++ {
+				for l := 0; l < n; l++ {
+					if k != l && dist[k][l] != 0 {
+						pb.AddQuadratic(enc.Var(i, k), enc.Var(j, l), flow[i][j]*dist[k][l])
+					}
+				}
+			}
+		}
+	}
+	enc.Problem = pb.Build()
+	return enc, nil
+}
+
+// AssignmentCost returns the cost of a quadratic assignment in which
+// facility i is placed at location assign[i].
+func AssignmentCost(flow, dist [][]float64, assign []int) float64 {
+	cost := 0.0
+	for i, k := range assign {
+		for j, l := range assign {
+			cost += flow[i][j] * dist[k][l]
+		}
+	}
+	return cost
+}
+
+// Assignment decodes a solution into the position of each item.  It
+// additionally reports whether the solution represents a valid permutation.
+// Items with no position are assigned -1, and items with more than one
+// position are assigned the smallest.
+func (enc *PermutationEncoding) Assignment(soln []int8) ([]int, bool) {
+	assign := make([]int, enc.N)
+	valid := true
+	used := make([]bool, enc.N)
+	for i := range assign {
+		assign[i] = -1
+		for p := enc.N - 1; p >= 0; p-- {
+			if x := enc.Var(i, p); x < len(soln) && soln[x] == 1 {
+				if assign[i] != -1 {
+					valid = false
+				}
+				assign[i] = p
+			}
+		}
+		switch {
+		case assign[i] == -1:
+			valid = false
+		case used[assign[i]]:
+			valid = false
+		default:
+			used[assign[i]] = true
+		}
+	}
+	return assign, valid
+}
+
+// Order decodes a solution into the item in each position, such as the
+// sequence of cities in a tour.  It additionally reports whether the
+// solution represents a valid permutation.
+func (enc *PermutationEncoding) Order(soln []int8) ([]int, bool) {
+	assign, valid := enc.Assignment(soln)
+	order := make([]int, enc.N)
+	for p := range order {
+		order[p] = -1
+	}
+	for i, p := range assign {
+		if p >= 0 && order[p] == -1 {
+			order[p] = i
+		}
+	}
+	return order, valid
+}
@@ -0,0 +1,47 @@
+// This file provides a policy for making explicit the limited precision with
+// which a quantum solver represents problem coefficients.
+
+package sapi
+
+import (
+	"math"
+)
+
+// A PrecisionPolicy rounds problem coefficients to the precision a solver can
+// effectively represent.  Because a quantum solver scales all coefficients by
+// the largest magnitude, precision is measured relative to that magnitude.
+type PrecisionPolicy struct {
+	Bits      int                   // Bits of precision relative to the largest coefficient magnitude (0 = unlimited)
+	Underflow func(pe ProblemEntry) // Function to invoke on each nonzero coefficient that rounds to zero (nil = ignore)
+}
+
+// Apply returns a copy of a problem with each coefficient rounded to the
+// nearest multiple of the largest coefficient magnitude divided by 2^Bits.
+// It is safe to invoke Apply on a nil policy, in which case the problem is
+// returned unmodified.
+func (pp *PrecisionPolicy) Apply(p Problem) Problem {
+	if pp == nil || pp.Bits <= 0 {
+		return p
+	}
+
+	// Determine the rounding granularity.
+	maxAbs := 0.0
+	for _, pe := range p {
+		maxAbs = math.Max(maxAbs, math.Abs(pe.Value))
+	}
+	if maxAbs == 0.0 {
+		return p
+	}
+	step := maxAbs / math.Exp2(float64(pp.Bits))
+
+	// Round each coefficient, reporting underflow as we go.
+	rp := make(Problem, len(p))
+	for i, pe := range p {
+		rp[i] = pe
+		rp[i].Value = math.Round(pe.Value/step) * step
+		if rp[i].Value == 0.0 && pe.Value != 0.0 && pp.Underflow != nil {
+			pp.Underflow(pe)
+		}
+	}
+	return rp
+}
@@ -0,0 +1,26 @@
+// This file provides arithmetic on problems, which is useful for composing
+// objective functions from penalty terms.
+
+package sapi
+
+// Add returns the sum of two problems in canonical form (see Canonicalize).
+func (p Problem) Add(q Problem) Problem {
+	sum := make(Problem, 0, len(p)+len(q))
+	sum = append(sum, p...)
+	sum = append(sum, q...)
+	return sum.Canonicalize()
+}
+
+// Sub returns the difference of two problems, p - q, in canonical form (see
+// Canonicalize).
+func (p Problem) Sub(q Problem) Problem {
+	return p.Add(q.scaled(-1))
+}
+
+// Scale returns a problem with every coefficient multiplied by c, in
+// canonical form (see Canonicalize).  For example, an objective function
+// obj subject to constraints cons with penalty weight λ can be expressed as
+// obj.Add(cons.Scale(λ)).
+func (p Problem) Scale(c float64) Problem {
+	return p.scaled(c).Canonicalize()
+}
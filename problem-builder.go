@@ -0,0 +1,81 @@
+// This file provides a means of constructing a problem incrementally.
+
+package sapi
+
+import (
+	"sort"
+)
+
+// A ProblemBuilder accumulates linear and quadratic terms into a Problem.
+// Terms that refer to the same variable or pair of variables are merged by
+// summing their values.  The zero value is an empty builder ready for use.
+type ProblemBuilder struct {
+	entries Problem        // Accumulated entries, each with I ≤ J
+	index   map[[2]int]int // Map from {I, J} to an offset into entries
+	offset  float64        // Constant energy offset
+}
+
+// NewProblemBuilder returns an empty ProblemBuilder.
+func NewProblemBuilder() *ProblemBuilder {
+	return &ProblemBuilder{}
+}
+
+// add accumulates a value into the entry for {i, j}, where i ≤ j.
+func (pb *ProblemBuilder) add(i, j int, v float64) {
+	if pb.index == nil {
+		pb.index = make(map[[2]int]int)
+	}
+	k := [2]int{i, j}
+	if n, ok := pb.index[k]; ok {
+		pb.entries[n].Value += v
+		return
+	}
+	pb.index[k] = len(pb.entries)
+	pb.entries = append(pb.entries, ProblemEntry{I: i, J: j, Value: v})
+}
+
+// AddLinear adds v to the linear coefficient of variable i.
+func (pb *ProblemBuilder) AddLinear(i int, v float64) {
+	pb.add(i, i, v)
+}
+
+// AddQuadratic adds v to the coupling between variables i and j.  The order
+// of i and j does not matter.  As in a Problem, i == j denotes variable i's
+// linear coefficient.
+func (pb *ProblemBuilder) AddQuadratic(i, j int, v float64) {
+	if i > j {
+		i, j = j, i
+	}
+	pb.add(i, j, v)
+}
+
+// SetOffset sets the constant energy offset associated with the problem.
+// Use BuildOffsetProblem to include it in solution energies.
+func (pb *ProblemBuilder) SetOffset(v float64) {
+	pb.offset = v
+}
+
+// Offset returns the constant energy offset associated with the problem.
+func (pb *ProblemBuilder) Offset() float64 {
+	return pb.offset
+}
+
+// Len returns the number of distinct terms accumulated so far.
+func (pb *ProblemBuilder) Len() int {
+	return len(pb.entries)
+}
+
+// Build returns the accumulated problem in canonical form (see Canonicalize).
+// The builder can continue to be used afterwards without affecting the
+// returned Problem.
+func (pb *ProblemBuilder) Build() Problem {
+	p := make(Problem, len(pb.entries))
+	copy(p, pb.entries)
+	sort.Slice(p, func(i, j int) bool {
+		if p[i].I != p[j].I {
+			return p[i].I < p[j].I
+		}
+		return p[i].J < p[j].J
+	})
+	return p
+}
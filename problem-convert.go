@@ -0,0 +1,92 @@
+// This file converts problems to and from other common representations.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+// NewProblemFromMaps constructs a Problem from a map of linear coefficients
+// and a map of quadratic coefficients.  Keys of J need not have I ≤ J;
+// entries that refer to the same pair of variables are summed.  The result is
+// in canonical form (see Canonicalize).
+func NewProblemFromMaps(h map[int]float64, J map[[2]int]float64) Problem {
+	var pb ProblemBuilder
+	for i, v := range h {
+		pb.AddLinear(i, v)
+	}
+	for ij, v := range J {
+		pb.AddQuadratic(ij[0], ij[1], v)
+	}
+	return pb.Build()
+}
+
+// ToMaps is the inverse of NewProblemFromMaps.  It returns a map of linear
+// coefficients and a map of quadratic coefficients, each with keys [I, J]
+// such that I < J.  Duplicate entries are summed.
+func (p Problem) ToMaps() (map[int]float64, map[[2]int]float64) {
+	h := make(map[int]float64)
+	J := make(map[[2]int]float64)
+	for _, pe := range p {
+		switch {
+		case pe.I == pe.J:
+			h[pe.I] += pe.Value
+		case pe.I < pe.J:
+			J[[2]int{pe.I, pe.J}] += pe.Value
+		default:
+			J[[2]int{pe.J, pe.I}] += pe.Value
+		}
+	}
+	return h, J
+}
+
+// NewProblemFromDense constructs a Problem from a square QUBO matrix Q, as
+// is common in MATLAB and NumPy code.  Q is normally upper-triangular.  If
+// not, each below-diagonal element Q[j][i] is added to Q[i][j] so that the
+// result represents the same objective function, xᵀQx.  Zero-valued elements
+// are omitted from the result, which is in canonical form (see
+// Canonicalize).
+func NewProblemFromDense(Q [][]float64) (Problem, error) {
+	n := len(Q)
+	for i, row := range Q {
+		if len(row) != n {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Row %d of a %d×%d matrix contains %d elements", i, n, n, len(row))
+		}
+	}
+	var p Problem
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			v := Q[i][j]
+			if j != i {
+				v += Q[j][i]
+			}
+			if v != 0.0 {
+				p = append(p, ProblemEntry{I: i, J: j, Value: v})
+			}
+		}
+	}
+	return p, nil
+}
+
+// ToDense is the inverse of NewProblemFromDense.  It returns an n×n
+// upper-triangular matrix in which duplicate entries are summed.  ToDense
+// fails if any variable's index is not less than n.
+func (p Problem) ToDense(n int) ([][]float64, error) {
+	Q := make([][]float64, n)
+	for i := range Q {
+		Q[i] = make([]float64, n)
+	}
+	for _, pe := range p {
+		i, j := pe.I, pe.J
+		if i > j {
+			i, j = j, i
+		}
+		if i < 0 || j >= n {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "%s does not fit in a %d×%d matrix", pe, n, n)
+		}
+		Q[i][j] += pe.Value
+	}
+	return Q, nil
+}
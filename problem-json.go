@@ -0,0 +1,125 @@
+// This file defines a canonical JSON serialization of problems.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// ProblemFormat identifies the version of the JSON problem format written by
+// MarshalProblem.
+const ProblemFormat = "sapi-problem/1"
+
+// A ProblemDocument is a problem plus the information needed to interpret it
+// outside of the program that created it.
+//
+// MarshalProblem encodes a ProblemDocument as a JSON object of the following
+// form:
+//
+//	{
+//	  "format": "sapi-problem/1",
+//	  "type": "ising",
+//	  "entries": [[0, 0, -0.5], [0, 1, 1], [1, 1, 0.25]],
+//	  "offset": 0,
+//	  "metadata": {"source": "example"}
+//	}
+//
+// "format" is always ProblemFormat.  "type" is either "ising" or "qubo".
+// "entries" lists the problem in canonical form (see Canonicalize), each
+// entry encoded as [I, J, Value].  "offset" is a constant energy offset, and
+// "metadata" is an arbitrary map of strings to strings; "metadata" is omitted
+// if empty.  Because the entries are canonicalized and metadata keys are
+// sorted, equivalent problems produce identical encodings, which makes
+// encoded problems suitable for archiving and diffing.
+type ProblemDocument struct {
+	Type     string            // "ising" or "qubo"
+	Problem  Problem           // The problem itself
+	Offset   float64           // Constant energy offset
+	Metadata map[string]string // Arbitrary, user-defined information
+}
+
+// problemDocumentJSON is the JSON representation of a ProblemDocument.
+type problemDocumentJSON struct {
+	Format   string            `json:"format"`
+	Type     string            `json:"type"`
+	Entries  [][]json.Number   `json:"entries"`
+	Offset   float64           `json:"offset"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// checkProblemType ensures that a problem type is either "ising" or "qubo".
+func checkProblemType(ptype string) error {
+	if ptype != "ising" && ptype != "qubo" {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Problem type must be \"ising\" or \"qubo\", not %q", ptype)
+	}
+	return nil
+}
+
+// MarshalProblem encodes a ProblemDocument as JSON.
+func MarshalProblem(doc *ProblemDocument) ([]byte, error) {
+	if err := checkProblemType(doc.Type); err != nil {
+		return nil, err
+	}
+	pj := problemDocumentJSON{
+		Format:   ProblemFormat,
+		Type:     doc.Type,
+		Entries:  make([][]json.Number, 0, len(doc.Problem)),
+		Offset:   doc.Offset,
+		Metadata: doc.Metadata,
+	}
+	for _, pe := range doc.Problem.Canonicalize() {
+		v, err := json.Marshal(pe.Value)
+		if err != nil {
+			return nil, err
+		}
+		pj.Entries = append(pj.Entries, []json.Number{
+			json.Number(strconv.Itoa(pe.I)),
+			json.Number(strconv.Itoa(pe.J)),
+			json.Number(v),
+		})
+	}
+	return json.Marshal(pj)
+}
+
+// UnmarshalProblem decodes a ProblemDocument produced by MarshalProblem.
+func UnmarshalProblem(data []byte) (*ProblemDocument, error) {
+	// Decode the document as a whole.
+	var pj problemDocumentJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return nil, err
+	}
+	if pj.Format != ProblemFormat {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unsupported problem format %q", pj.Format)
+	}
+	if err := checkProblemType(pj.Type); err != nil {
+		return nil, err
+	}
+
+	// Decode each entry.
+	doc := &ProblemDocument{
+		Type:     pj.Type,
+		Problem:  make(Problem, len(pj.Entries)),
+		Offset:   pj.Offset,
+		Metadata: pj.Metadata,
+	}
+	for k, ent := range pj.Entries {
+		if len(ent) != 3 {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Entry %d contains %d elements instead of [I, J, Value]", k, len(ent))
+		}
+		i, err1 := strconv.Atoi(ent[0].String())
+		j, err2 := strconv.Atoi(ent[1].String())
+		v, err3 := ent[2].Float64()
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Entry %d, %v, is not of the form [I, J, Value]", k, ent)
+		}
+		doc.Problem[k] = ProblemEntry{I: i, J: j, Value: v}
+	}
+	return doc, nil
+}
@@ -0,0 +1,168 @@
+// This file provides human-readable textual forms for problems.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// String formats a ProblemEntry as either "h[i]=value" for a linear term or
+// "J[i,j]=value" for a quadratic term.
+func (pe ProblemEntry) String() string {
+	v := strconv.FormatFloat(pe.Value, 'g', -1, 64)
+	if pe.I == pe.J {
+		return "h[" + strconv.Itoa(pe.I) + "]=" + v
+	}
+	return "J[" + strconv.Itoa(pe.I) + "," + strconv.Itoa(pe.J) + "]=" + v
+}
+
+// String formats a problem in canonical form (see Canonicalize), one
+// ProblemEntry per line.  The result can be read back with ParseProblem.
+func (p Problem) String() string {
+	var sb strings.Builder
+	for _, pe := range p.Canonicalize() {
+		sb.WriteString(pe.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// problemEntryRE matches the textual form of a single ProblemEntry.
+var problemEntryRE = regexp.MustCompile(`^([hJ])\s*\[\s*(-?\d+)\s*(?:,\s*(-?\d+)\s*)?\]\s*=\s*(\S+)$`)
+
+// ParseProblem parses the textual form of a problem produced by
+// Problem.String.  Entries are separated by newlines or semicolons and may be
+// surrounded by whitespace.  A "#" introduces a comment that extends to the
+// end of the line.  Entries are returned in the order they appear, without
+// canonicalization.
+func ParseProblem(s string) (Problem, error) {
+	var p Problem
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for ln := 1; scanner.Scan(); ln++ {
+		// Discard comments.
+		line := scanner.Text()
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+
+		// Parse each entry on the line.
+		for _, txt := range strings.Split(line, ";") {
+			txt = strings.TrimSpace(txt)
+			if txt == "" {
+				continue
+			}
+			m := problemEntryRE.FindStringSubmatch(txt)
+			if m == nil {
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Failed to parse %q as h[i]=value or J[i,j]=value", ln, txt)
+			}
+			var pe ProblemEntry
+			var err error
+			if pe.I, err = strconv.Atoi(m[2]); err != nil {
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Invalid index %q", ln, m[2])
+			}
+			pe.J = pe.I
+			switch {
+			case m[1] == "h" && m[3] != "":
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: A linear term takes one index, not two, in %q", ln, txt)
+			case m[1] == "J" && m[3] == "":
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: A quadratic term takes two indices, not one, in %q", ln, txt)
+			case m[1] == "J":
+				if pe.J, err = strconv.Atoi(m[3]); err != nil {
+					return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Invalid index %q", ln, m[3])
+				}
+				if pe.I == pe.J {
+					return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: A quadratic term requires two distinct indices in %q", ln, txt)
+				}
+			}
+			if pe.Value, err = strconv.ParseFloat(m[4], 64); err != nil {
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Invalid coefficient %q", ln, m[4])
+			}
+			p = append(p, pe)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ReadCOO reads a problem in coordinate (edge-list) format, as is common in
+// benchmarking datasets.  Each line contains either "i j value" or, for an
+// adjacency such as that returned by HardwareAdjacency, simply "i j", in
+// which case the entry's Value is zero.  Fields are separated by whitespace,
+// and a "#" or "%" introduces a comment that extends to the end of the line.
+// Entries are returned in the order they appear, without canonicalization.
+func ReadCOO(r io.Reader) (Problem, error) {
+	var p Problem
+	scanner := bufio.NewScanner(r)
+	for ln := 1; scanner.Scan(); ln++ {
+		// Discard comments and blank lines.
+		line := scanner.Text()
+		if c := strings.IndexAny(line, "#%"); c >= 0 {
+			line = line[:c]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 && len(fields) != 3 {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Expected \"i j value\" or \"i j\" but saw %q", ln, line)
+		}
+
+		// Parse the entry.
+		var pe ProblemEntry
+		var err error
+		if pe.I, err = strconv.Atoi(fields[0]); err != nil {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Invalid index %q", ln, fields[0])
+		}
+		if pe.J, err = strconv.Atoi(fields[1]); err != nil {
+			return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Invalid index %q", ln, fields[1])
+		}
+		if len(fields) == 3 {
+			if pe.Value, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Line %d: Invalid coefficient %q", ln, fields[2])
+			}
+		}
+		p = append(p, pe)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WriteCOO writes a problem in the "i j value" coordinate format read by
+// ReadCOO, one ProblemEntry per line, in the order given.
+func WriteCOO(w io.Writer, p Problem) error {
+	bw := bufio.NewWriter(w)
+	for _, pe := range p {
+		v := strconv.FormatFloat(pe.Value, 'g', -1, 64)
+		if _, err := fmt.Fprintf(bw, "%d %d %s\n", pe.I, pe.J, v); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteAdjacencyCOO writes an adjacency in the "i j" coordinate format read
+// by ReadCOO, one coupler per line, in the order given.  Values are ignored.
+func WriteAdjacencyCOO(w io.Writer, adj Problem) error {
+	bw := bufio.NewWriter(w)
+	for _, pe := range adj {
+		if _, err := fmt.Fprintf(bw, "%d %d\n", pe.I, pe.J); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
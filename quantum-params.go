@@ -0,0 +1,314 @@
+// This file provides validated setters for solver parameters, chiefly the
+// quantum solver parameters that are constrained by a solver's properties.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"math"
+	"sort"
+)
+
+// SetAnnealOffsets assigns per-qubit anneal offsets.  If the parameters were
+// created by Solver.NewSolverParameters, each offset is checked against the
+// solver's AnnealOffsetProperties: it must lie within its qubit's range and
+// be a multiple of the quantization step.  On failure, the parameters are
+// left unmodified.
+func (p *QuantumSolverParameters) SetAnnealOffsets(offsets []float64) error {
+	if p.props != nil && len(offsets) > 0 {
+		aop := p.props.AnnealOffsets
+		if aop == nil {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The solver does not support anneal offsets")
+		}
+		if len(offsets) > len(aop.Ranges) {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "%d anneal offsets were given but the solver has only %d qubits", len(offsets), len(aop.Ranges))
+		}
+		for q, o := range offsets {
+			if r := aop.Ranges[q]; o < r[0] || o > r[1] {
+				return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Anneal offset %v for qubit %d lies outside the range [%v, %v]", o, q, r[0], r[1])
+			}
+			if aop.Step > 0 {
+				if n := o / aop.Step; math.Abs(n-math.Round(n)) > 1e-6 {
+					return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Anneal offset %v for qubit %d is not a multiple of the step size %v", o, q, aop.Step)
+				}
+			}
+		}
+	}
+	p.AnnealOffsets = offsets
+	return nil
+}
+
+// A SchedulePoint is one point in a piecewise-linear anneal schedule.
+type SchedulePoint struct {
+	Time float64 // Time in microseconds since the start of the anneal
+	S    float64 // Normalized anneal fraction, from 0.0 to 1.0
+}
+
+// SetAnnealSchedule assigns a piecewise-linear anneal schedule, which can
+// express pauses and quenches mid-anneal.  The schedule must start at time
+// 0, its times must strictly increase, every S must lie in [0, 1], and the
+// schedule must end with S = 1.0.  A forward anneal starts with S = 0.0; a
+// reverse anneal starts with S = 1.0.  If the parameters were created by
+// Solver.NewSolverParameters, the schedule is additionally checked against
+// the solver's AnnealScheduleProperties: it may contain at most MaxPoints
+// points, it may not exceed MaxAnnealingTime, and no segment may be steeper
+// than a full anneal in MinAnnealingTime.  On failure, the parameters are
+// left unmodified.
+func (p *QuantumSolverParameters) SetAnnealSchedule(sched []SchedulePoint) error {
+	if len(sched) == 0 {
+		p.AnnealSchedule = nil
+		return nil
+	}
+
+	// Perform checks that apply to all solvers.
+	if sched[0].Time != 0 {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An anneal schedule must start at time 0, not %v", sched[0].Time)
+	}
+	if s := sched[0].S; s != 0 && s != 1 {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An anneal schedule must start at s = 0 or s = 1, not %v", s)
+	}
+	if s := sched[len(sched)-1].S; s != 1 {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An anneal schedule must end at s = 1, not %v", s)
+	}
+	for i, pt := range sched {
+		if pt.S < 0 || pt.S > 1 {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Schedule point %d has s = %v, which lies outside [0, 1]", i, pt.S)
+		}
+		if i > 0 && pt.Time <= sched[i-1].Time {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Schedule point %d has time %v, which does not follow %v", i, pt.Time, sched[i-1].Time)
+		}
+	}
+
+	// Perform checks that depend on the solver's properties.
+	if p.props != nil {
+		asp := p.props.AnnealSchedule
+		if asp == nil {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The solver does not support custom anneal schedules")
+		}
+		if asp.MaxPoints > 0 && len(sched) > asp.MaxPoints {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An anneal schedule may contain at most %d points, not %d", asp.MaxPoints, len(sched))
+		}
+		if t := sched[len(sched)-1].Time; asp.MaxAnnealingTime > 0 && t > asp.MaxAnnealingTime {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "An anneal schedule may last at most %v µs, not %v µs", asp.MaxAnnealingTime, t)
+		}
+		if asp.MinAnnealingTime > 0 {
+			maxSlope := 1.0 / asp.MinAnnealingTime
+			for i := 1; i < len(sched); i++ {
+				slope := math.Abs(sched[i].S-sched[i-1].S) / (sched[i].Time - sched[i-1].Time)
+				if slope > maxSlope*(1+1e-6) {
+					return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Schedule segment %d has slope %v, which exceeds the maximum of %v per µs", i, slope, maxSlope)
+				}
+			}
+		}
+	}
+	p.AnnealSchedule = sched
+	return nil
+}
+
+// SetFluxBiases assigns per-qubit flux-bias offsets, in units of Φ0, as used
+// for chain calibration and virtual-graph workflows.  Every offset must be
+// finite.  If the parameters were created by Solver.NewSolverParameters, the
+// solver must additionally advertise the flux_biases parameter, there may be
+// no more offsets than qubits, and only working qubits may be given nonzero
+// offsets.  On failure, the parameters are left unmodified.
+func (p *QuantumSolverParameters) SetFluxBiases(biases []float64) error {
+	for q, fb := range biases {
+		if math.IsNaN(fb) || math.IsInf(fb, 0) {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Flux-bias offset %v for qubit %d is not finite", fb, q)
+		}
+	}
+	if p.props != nil && len(biases) > 0 {
+		if names := p.props.Parameters; len(names) > 0 {
+			i := sort.SearchStrings(names, "flux_biases")
+			if i == len(names) || names[i] != "flux_biases" {
+				return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The solver does not support flux-bias offsets")
+			}
+		}
+		if qp := p.props.QuantumProps; qp != nil {
+			if len(biases) > qp.NumQubits {
+				return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "%d flux-bias offsets were given but the solver has only %d qubits", len(biases), qp.NumQubits)
+			}
+			working := make(map[int]bool, len(qp.Qubits))
+			for _, q := range qp.Qubits {
+				working[q] = true
+			}
+			for q, fb := range biases {
+				if fb != 0 && !working[q] {
+					return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Qubit %d is not a working qubit and cannot take a flux-bias offset", q)
+				}
+			}
+		}
+	}
+	p.FluxBiases = biases
+	return nil
+}
+
+// SetPostprocessing requests that the server postprocess a quantum solver's
+// answers instead of returning them raw.  pp selects the type of
+// postprocessing.  beta is the Boltzmann distribution parameter used by
+// PostprocessSampling; it must be non-negative.  chains, in the same format
+// as an embedding, maps each qubit to the logical variable whose chain it
+// belongs to or to -1; postprocessing then treats each chain as a single
+// variable.  Pass nil to treat every qubit as its own variable.  If the
+// parameters were created by Solver.NewSolverParameters, the solver must
+// support the requested postprocessing, and every chained qubit must be a
+// working qubit.  On failure, the parameters are left unmodified.
+func (p *QuantumSolverParameters) SetPostprocessing(pp Postprocessing, beta float64, chains Embeddings) error {
+	// Perform checks that apply to all solvers.
+	if _, ok := postprocessingNames[pp]; !ok {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unrecognized postprocessing type %d", pp)
+	}
+	if err := checkBeta(beta); err != nil {
+		return err
+	}
+
+	// Perform checks that depend on the solver's properties.
+	if p.props != nil && pp != PostprocessNone {
+		found := false
+		for _, spp := range p.props.SupportedPostprocessing {
+			found = found || spp == pp
+		}
+		if !found {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The solver does not support %s postprocessing", pp)
+		}
+	}
+	if err := p.checkPostprocessChains(chains); err != nil {
+		return err
+	}
+	p.Postprocess = pp
+	p.Beta = beta
+	p.Chains = chains
+	return nil
+}
+
+// checkBeta ensures that a Boltzmann distribution parameter is non-negative.
+func checkBeta(beta float64) error {
+	if beta < 0 || math.IsNaN(beta) {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Beta must be non-negative, not %v", beta)
+	}
+	return nil
+}
+
+// checkPostprocessChains ensures, if the solver's properties are known, that
+// every qubit assigned to a postprocessing chain is a working qubit.
+func (p *QuantumSolverParameters) checkPostprocessChains(chains []int) error {
+	if p.props == nil || p.props.QuantumProps == nil || len(chains) == 0 {
+		return nil
+	}
+	qp := p.props.QuantumProps
+	if len(chains) > qp.NumQubits {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Chains were given for %d qubits but the solver has only %d qubits", len(chains), qp.NumQubits)
+	}
+	working := make(map[int]bool, len(qp.Qubits))
+	for _, q := range qp.Qubits {
+		working[q] = true
+	}
+	for q, v := range chains {
+		if v >= 0 && !working[q] {
+			return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Qubit %d is not a working qubit and cannot belong to a chain", q)
+		}
+	}
+	return nil
+}
+
+// SetPostprocessBeta sets the Boltzmann distribution parameter used by
+// PostprocessSampling.  beta must be non-negative.  On failure, the
+// parameters are left unmodified.
+func (p *QuantumSolverParameters) SetPostprocessBeta(beta float64) error {
+	if err := checkBeta(beta); err != nil {
+		return err
+	}
+	p.Beta = beta
+	return nil
+}
+
+// SetPostprocessChains specifies the logical chains that server-side
+// postprocessing should treat as single variables.  Each chain is a list of
+// qubits, and no qubit may appear in more than one chain.  The chains created
+// by EmbedProblem are the values of the embedding's Chains map.  Pass nil to
+// treat every qubit as its own variable.  If the parameters were created by
+// Solver.NewSolverParameters, every chained qubit must be a working qubit.
+// On failure, the parameters are left unmodified.
+func (p *QuantumSolverParameters) SetPostprocessChains(chains [][]int) error {
+	// Convert the list of chains to a per-qubit chain number.
+	nq := 0
+	for _, ch := range chains {
+		for _, q := range ch {
+			if q < 0 {
+				return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Invalid qubit number %d", q)
+			}
+			if q+1 > nq {
+				nq = q + 1
+			}
+		}
+	}
+	var perQubit []int
+	if nq > 0 {
+		perQubit = make([]int, nq)
+		for q := range perQubit {
+			perQubit[q] = -1
+		}
+		for c, ch := range chains {
+			for _, q := range ch {
+				if perQubit[q] >= 0 && perQubit[q] != c {
+					return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Qubit %d appears in chains %d and %d", q, perQubit[q], c)
+				}
+				perQubit[q] = c
+			}
+		}
+	}
+
+	// Validate and store the chains.
+	if err := p.checkPostprocessChains(perQubit); err != nil {
+		return err
+	}
+	p.Chains = perQubit
+	return nil
+}
+
+// checkMaxAnswers is the common code for each SolverParameters type's
+// SetMaxAnswers method.
+func checkMaxAnswers(n int) error {
+	if n < 1 {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "The maximum number of answers must be positive, not %d", n)
+	}
+	return nil
+}
+
+// SetMaxAnswers caps the number of answers a solver returns.  See
+// AnswerModeRaw for how the cap interacts with the answer mode.  On failure,
+// the parameters are left unmodified.
+func (p *QuantumSolverParameters) SetMaxAnswers(n int) error {
+	if err := checkMaxAnswers(n); err != nil {
+		return err
+	}
+	p.MaxAnswers = n
+	return nil
+}
+
+// SetMaxAnswers caps the number of answers a solver returns.  See
+// AnswerModeRaw for how the cap interacts with the answer mode.  On failure,
+// the parameters are left unmodified.
+func (p *SwOptimizeSolverParameters) SetMaxAnswers(n int) error {
+	if err := checkMaxAnswers(n); err != nil {
+		return err
+	}
+	p.MaxAnswers = n
+	return nil
+}
+
+// SetMaxAnswers caps the number of answers a solver returns.  See
+// AnswerModeRaw for how the cap interacts with the answer mode.  On failure,
+// the parameters are left unmodified.
+func (p *SwSampleSolverParameters) SetMaxAnswers(n int) error {
+	if err := checkMaxAnswers(n); err != nil {
+		return err
+	}
+	p.MaxAnswers = n
+	return nil
+}
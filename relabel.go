@@ -0,0 +1,51 @@
+// This file provides support for renumbering a problem's variables.
+
+package sapi
+
+import (
+	"sort"
+)
+
+// Compact renumbers a problem's variables densely from 0 to N-1, preserving
+// their relative order.  This is useful for passing problems with arbitrary
+// variable numbers to samplers or serializers that assume contiguous
+// numbering.  Compact returns the renumbered problem and a map from each
+// original variable number to its new number.
+func (p Problem) Compact() (Problem, map[int]int) {
+	// Collect and sort the variable numbers.
+	seen := make(map[int]bool)
+	for _, pe := range p {
+		seen[pe.I] = true
+		seen[pe.J] = true
+	}
+	vars := make([]int, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+
+	// Number the variables consecutively.
+	mapping := make(map[int]int, len(vars))
+	for i, v := range vars {
+		mapping[v] = i
+	}
+	return p.Relabel(mapping), mapping
+}
+
+// Relabel returns a copy of a problem with each variable v renumbered to
+// mapping[v].  Variables that do not appear in mapping retain their original
+// number.  The inverse of the map returned by Compact can be passed to
+// Relabel to restore a compacted problem's original numbering.
+func (p Problem) Relabel(mapping map[int]int) Problem {
+	relabel := func(v int) int {
+		if nv, ok := mapping[v]; ok {
+			return nv
+		}
+		return v
+	}
+	rp := make(Problem, len(p))
+	for i, pe := range p {
+		rp[i] = ProblemEntry{I: relabel(pe.I), J: relabel(pe.J), Value: pe.Value}
+	}
+	return rp
+}
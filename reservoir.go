@@ -0,0 +1,112 @@
+// This file provides a means of summarizing a stream of results using a
+// bounded amount of memory.
+
+package sapi
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// A Sample is a single solution and its energy.
+type Sample struct {
+	Solution []int8  // Solution (±1, 0/1, or 3 for "unused")
+	Energy   float64 // Energy of the solution
+}
+
+// ResultStats summarize all solutions added to a Reservoir.
+type ResultStats struct {
+	Count      int     // Number of solutions observed, counting repeated occurrences
+	MinEnergy  float64 // Lowest energy observed
+	MaxEnergy  float64 // Highest energy observed
+	MeanEnergy float64 // Mean energy
+	StdDev     float64 // Standard deviation of the energy
+	Best       Sample  // A solution with the lowest energy observed
+}
+
+// A Reservoir maintains a uniform random sample of fixed size over a stream
+// of IsingResults, along with summary statistics over the entire stream.  It
+// is intended for live displays that need representative solutions without
+// storing every solution.  A Reservoir is safe for concurrent use.
+type Reservoir struct {
+	size    int         // Maximum number of samples to retain
+	samples []Sample    // Retained samples
+	stats   ResultStats // Running statistics
+	m2      float64     // Sum of squared deviations from the mean (Welford's algorithm)
+	rng     *rand.Rand  // Random-number generator
+	mu      sync.Mutex  // Protects all of the above
+}
+
+// NewReservoir returns a Reservoir that retains at most size samples.  The
+// seed determines which samples are retained.
+func NewReservoir(size int, seed int64) *Reservoir {
+	return &Reservoir{
+		size:    size,
+		samples: make([]Sample, 0, size),
+		rng:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// add incorporates a single occurrence of a solution into the reservoir.  The
+// caller must hold the lock.
+func (r *Reservoir) add(soln []int8, e float64) {
+	// Update the statistics.
+	st := &r.stats
+	st.Count++
+	if st.Count == 1 || e < st.MinEnergy {
+		st.MinEnergy = e
+		st.Best = Sample{Solution: soln, Energy: e}
+	}
+	if st.Count == 1 || e > st.MaxEnergy {
+		st.MaxEnergy = e
+	}
+	delta := e - st.MeanEnergy
+	st.MeanEnergy += delta / float64(st.Count)
+	r.m2 += delta * (e - st.MeanEnergy)
+
+	// Update the sample (Vitter's Algorithm R).
+	if len(r.samples) < r.size {
+		r.samples = append(r.samples, Sample{Solution: soln, Energy: e})
+		return
+	}
+	if j := r.rng.Intn(st.Count); j < r.size {
+		r.samples[j] = Sample{Solution: soln, Energy: e}
+	}
+}
+
+// Add incorporates all solutions in an IsingResult into the reservoir.  A
+// solution with multiple occurrences is counted once per occurrence.
+func (r *Reservoir) Add(res IsingResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, soln := range res.Solutions {
+		n := 1
+		if res.Occurrences != nil {
+			n = res.Occurrences[i]
+		}
+		for j := 0; j < n; j++ {
+			r.add(soln, res.Energies[i])
+		}
+	}
+}
+
+// Samples returns a copy of the samples currently retained.
+func (r *Reservoir) Samples() []Sample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	samples := make([]Sample, len(r.samples))
+	copy(samples, r.samples)
+	return samples
+}
+
+// Stats returns summary statistics over all solutions added so far.
+func (r *Reservoir) Stats() ResultStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.stats
+	if st.Count > 1 {
+		st.StdDev = math.Sqrt(r.m2 / float64(st.Count-1))
+	}
+	return st
+}
@@ -0,0 +1,85 @@
+// This file lets callers supply the source of randomness used by all of the
+// package's randomized components.
+
+package sapi
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// randSource holds the package-wide source of randomness.
+var randSource struct {
+	src rand.Source64 // Caller-supplied source or nil to seed from the time
+	mu  sync.Mutex    // Protects src and serializes access to it
+}
+
+// SetRandSource specifies the source of randomness used by every randomized
+// component in the package: client-side gauge selection, embedding searches,
+// and the random seeds passed to SAPI's software samplers and FindEmbedding.
+// Supplying a deterministically seeded source makes all of these
+// reproducible.  Passing nil restores the default behavior, in which each
+// component seeds itself from the time (or, for SAPI's own algorithms, SAPI
+// chooses a seed).  The source is used under a lock, so it need not be safe
+// for concurrent use.
+func SetRandSource(src rand.Source64) {
+	randSource.mu.Lock()
+	randSource.src = src
+	randSource.mu.Unlock()
+}
+
+// lockedSource wraps a caller-supplied source of randomness so that it is
+// used only under the package-wide lock.  A lockedSource retains the source
+// it was created with, so a Rand built on it remains usable even after
+// SetRandSource replaces or removes the package-wide source.
+type lockedSource struct {
+	src rand.Source64 // Caller-supplied source
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (ls lockedSource) Int63() int64 {
+	randSource.mu.Lock()
+	defer randSource.mu.Unlock()
+	return ls.src.Int63()
+}
+
+// Uint64 returns a pseudo-random 64-bit integer.
+func (ls lockedSource) Uint64() uint64 {
+	randSource.mu.Lock()
+	defer randSource.mu.Unlock()
+	return ls.src.Uint64()
+}
+
+// Seed is required by the rand.Source interface but is ignored.  Use
+// SetRandSource to change the source of randomness.
+func (lockedSource) Seed(seed int64) {}
+
+// currentRandSource returns the caller-supplied source of randomness or nil
+// if none was supplied.
+func currentRandSource() rand.Source64 {
+	randSource.mu.Lock()
+	defer randSource.mu.Unlock()
+	return randSource.src
+}
+
+// newRand returns a random-number generator for a randomized component.  It
+// draws from the package-wide source if one was supplied and is otherwise
+// seeded from the time.
+func newRand() *rand.Rand {
+	if src := currentRandSource(); src != nil {
+		return rand.New(lockedSource{src: src})
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// randomSeed returns a seed for one of SAPI's randomized algorithms and true
+// if the caller supplied a source of randomness, or 0 and false otherwise.
+func randomSeed() (uint, bool) {
+	randSource.mu.Lock()
+	defer randSource.mu.Unlock()
+	if randSource.src == nil {
+		return 0, false
+	}
+	return uint(randSource.src.Uint64() >> 33), true
+}
@@ -0,0 +1,379 @@
+// This file provides a Go implementation of roof duality for QUBO problems,
+// which underlies the FixVariables sensitivity analysis.
+
+package sapi
+
+import (
+	"math"
+	"sort"
+)
+
+// A quboTerm represents a single quadratic term in a quboModel.
+type quboTerm struct {
+	i, j  int     // Dense variable indices
+	value float64 // Coefficient
+}
+
+// A quboModel is a dense representation of a QUBO problem used internally by
+// the roof-duality code.
+type quboModel struct {
+	vars   []int      // Original variable number of each dense index
+	offset float64    // Constant term
+	lin    []float64  // Linear coefficients
+	quad   []quboTerm // Quadratic coefficients
+}
+
+// newQuboModel converts a QUBO Problem to a quboModel.
+func newQuboModel(p Problem) *quboModel {
+	// Assign a dense index to each variable.
+	seen := make(map[int]struct{}, len(p))
+	for _, pe := range p {
+		seen[pe.I] = struct{}{}
+		seen[pe.J] = struct{}{}
+	}
+	vars := make([]int, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	idx := make(map[int]int, len(vars))
+	for i, v := range vars {
+		idx[v] = i
+	}
+
+	// Store the coefficients.
+	m := &quboModel{
+		vars: vars,
+		lin:  make([]float64, len(vars)),
+		quad: make([]quboTerm, 0, len(p)),
+	}
+	for _, pe := range p.Canonicalize() {
+		i, j := idx[pe.I], idx[pe.J]
+		if i == j {
+			m.lin[i] += pe.Value
+		} else {
+			m.quad = append(m.quad, quboTerm{i: i, j: j, value: pe.Value})
+		}
+	}
+	return m
+}
+
+// clamp returns a copy of a quboModel in which variable k is replaced by a
+// constant.  The variable remains in the model but with no coefficients.
+func (m *quboModel) clamp(k int, b int8) *quboModel {
+	m2 := &quboModel{
+		vars:   m.vars,
+		offset: m.offset,
+		lin:    make([]float64, len(m.lin)),
+		quad:   make([]quboTerm, 0, len(m.quad)),
+	}
+	copy(m2.lin, m.lin)
+	if b == 1 {
+		m2.offset += m2.lin[k]
+	}
+	m2.lin[k] = 0
+	for _, t := range m.quad {
+		switch {
+		case t.i == k && b == 1:
+			m2.lin[t.j] += t.value
+		case t.j == k && b == 1:
+			m2.lin[t.i] += t.value
+		case t.i == k || t.j == k:
+			// Clamping to 0 eliminates the term.
+		default:
+			m2.quad = append(m2.quad, t)
+		}
+	}
+	return m2
+}
+
+// energy returns the energy of a 0/1 assignment to a quboModel's variables.
+func (m *quboModel) energy(x []int8) float64 {
+	e := m.offset
+	for i, v := range m.lin {
+		e += v * float64(x[i])
+	}
+	for _, t := range m.quad {
+		e += t.value * float64(x[t.i]*x[t.j])
+	}
+	return e
+}
+
+// localSearch improves an assignment by repeatedly flipping single variables
+// until no flip lowers the energy.  It returns the final energy.
+func (m *quboModel) localSearch(x []int8) float64 {
+	// Precompute each variable's neighbors.
+	nbrs := make([][]quboTerm, len(m.lin))
+	for _, t := range m.quad {
+		nbrs[t.i] = append(nbrs[t.i], t)
+		nbrs[t.j] = append(nbrs[t.j], quboTerm{i: t.j, j: t.i, value: t.value})
+	}
+
+	// Flip variables greedily.
+	for improved := true; improved; {
+		improved = false
+		for i := range x {
+			d := m.lin[i]
+			for _, t := range nbrs[i] {
+				d += t.value * float64(x[t.j])
+			}
+			if x[i] == 1 {
+				d = -d
+			}
+			if d < -1e-12 {
+				x[i] = 1 - x[i]
+				improved = true
+			}
+		}
+	}
+	return m.energy(x)
+}
+
+// An implicationNetwork is the flow network used to compute the roof dual of
+// a QUBO problem.  Node 2i represents literal x_i, node 2i+1 represents its
+// complement, and the final two nodes represent the constants 1 (the source)
+// and 0 (the sink).
+type implicationNetwork struct {
+	head []int     // First arc leaving each node or -1
+	next []int     // Next arc leaving the same node or -1
+	to   []int     // Arc destination
+	cap  []float64 // Residual capacity of each arc (arc a^1 is a's reverse)
+	src  int       // Source node
+	sink int       // Sink node
+	eps  float64   // Capacities smaller than this are treated as zero
+	lvl  []int     // Dinic level graph
+	iter []int     // Dinic current-arc pointers
+	c0   float64   // Constant term of the model's posiform
+}
+
+// newImplicationNetwork constructs the implication network of a quboModel.
+func newImplicationNetwork(m *quboModel) *implicationNetwork {
+	n := len(m.lin)
+	g := &implicationNetwork{
+		head: make([]int, 2*n+2),
+		src:  2 * n,
+		sink: 2*n + 1,
+		c0:   m.offset,
+	}
+	for i := range g.head {
+		g.head[i] = -1
+	}
+
+	// Rewrite the problem as a posiform, a sum of nonnegatively weighted
+	// products of literals, and add arcs for each term.
+	maxMag := 0.0
+	lin := make([]float64, n)
+	copy(lin, m.lin)
+	for _, t := range m.quad {
+		switch {
+		case t.value > 0:
+			g.addTerm(2*t.i, 2*t.j, t.value)
+		case t.value < 0:
+			// a x_i x_j = a x_i - a x_i (1 - x_j)
+			lin[t.i] += t.value
+			g.addTerm(2*t.i, 2*t.j+1, -t.value)
+		}
+		maxMag = math.Max(maxMag, math.Abs(t.value))
+	}
+	for i, v := range lin {
+		switch {
+		case v > 0:
+			g.addTerm(2*i, g.src, v)
+		case v < 0:
+			// a x_i = a - a (1 - x_i)
+			g.c0 += v
+			g.addTerm(2*i+1, g.src, -v)
+		}
+		maxMag = math.Max(maxMag, math.Abs(v))
+	}
+	g.eps = 1e-12 * math.Max(maxMag, 1.0)
+	return g
+}
+
+// addArc adds an arc and its zero-capacity reverse to the network.
+func (g *implicationNetwork) addArc(u, v int, c float64) {
+	g.to = append(g.to, v, u)
+	g.cap = append(g.cap, c, 0)
+	g.next = append(g.next, g.head[u], g.head[v])
+	a := len(g.to) - 2
+	g.head[u] = a
+	g.head[v] = a + 1
+}
+
+// addTerm adds the arcs corresponding to posiform term c·u·v.  Passing the
+// source node for v indicates a linear term c·u.
+func (g *implicationNetwork) addTerm(u, v int, c float64) {
+	c /= 2
+	if v == g.src {
+		// A linear term implies 1 → ¬u and u → 0.
+		g.addArc(g.src, u^1, c)
+		g.addArc(u, g.sink, c)
+		return
+	}
+	g.addArc(u, v^1, c)
+	g.addArc(v, u^1, c)
+}
+
+// bfs constructs the Dinic level graph and reports if the sink is reachable.
+func (g *implicationNetwork) bfs() bool {
+	for i := range g.lvl {
+		g.lvl[i] = -1
+	}
+	g.lvl[g.src] = 0
+	queue := []int{g.src}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for a := g.head[u]; a != -1; a = g.next[a] {
+			v := g.to[a]
+			if g.cap[a] > g.eps && g.lvl[v] < 0 {
+				g.lvl[v] = g.lvl[u] + 1
+				queue = append(queue, v)
+			}
+		}
+	}
+	return g.lvl[g.sink] >= 0
+}
+
+// dfs pushes up to f units of flow from u to the sink along the level graph.
+func (g *implicationNetwork) dfs(u int, f float64) float64 {
+	if u == g.sink {
+		return f
+	}
+	for ; g.iter[u] != -1; g.iter[u] = g.next[g.iter[u]] {
+		a := g.iter[u]
+		v := g.to[a]
+		if g.cap[a] <= g.eps || g.lvl[v] != g.lvl[u]+1 {
+			continue
+		}
+		if d := g.dfs(v, math.Min(f, g.cap[a])); d > g.eps {
+			g.cap[a] -= d
+			g.cap[a^1] += d
+			return d
+		}
+	}
+	return 0
+}
+
+// maxFlow computes the maximum flow from the source to the sink.
+func (g *implicationNetwork) maxFlow() float64 {
+	g.lvl = make([]int, len(g.head))
+	g.iter = make([]int, len(g.head))
+	flow := 0.0
+	for g.bfs() {
+		copy(g.iter, g.head)
+		for {
+			f := g.dfs(g.src, math.Inf(1))
+			if f <= g.eps {
+				break
+			}
+			flow += f
+		}
+	}
+	return flow
+}
+
+// roofDual computes the roof-duality lower bound on a quboModel's minimum
+// energy.  It additionally returns, for each variable, the value it takes in
+// all optimal solutions (strong persistency) or -1 if that value cannot be
+// determined.
+func (m *quboModel) roofDual() (float64, []int8) {
+	// Compute the bound.
+	g := newImplicationNetwork(m)
+	lb := g.c0 + g.maxFlow()
+
+	// Every literal reachable from the source in the residual network is
+	// true in all optimal solutions.
+	reach := make([]bool, len(g.head))
+	reach[g.src] = true
+	queue := []int{g.src}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for a := g.head[u]; a != -1; a = g.next[a] {
+			v := g.to[a]
+			if g.cap[a] > g.eps && !reach[v] {
+				reach[v] = true
+				queue = append(queue, v)
+			}
+		}
+	}
+	fixed := make([]int8, len(m.lin))
+	for i := range fixed {
+		switch {
+		case reach[2*i] && !reach[2*i+1]:
+			fixed[i] = 1
+		case reach[2*i+1] && !reach[2*i]:
+			fixed[i] = 0
+		default:
+			fixed[i] = -1
+		}
+	}
+	return lb, fixed
+}
+
+// LowerBound returns the roof-duality lower bound on a QUBO problem's minimum
+// energy.  No solution has lower energy than the bound, so the gap between a
+// solution's energy and the bound limits how far that solution can be from
+// optimal, even when the true ground-state energy is unknown.  The bound is
+// tight for problems whose quadratic coefficients are all non-positive.
+func (p Problem) LowerBound() float64 {
+	lb, _ := newQuboModel(p).roofDual()
+	return lb
+}
+
+// IsingLowerBound is the Ising-model analogue of LowerBound.
+func (p Problem) IsingLowerBound() float64 {
+	qp, ofs := p.ToQubo()
+	return qp.LowerBound() + ofs
+}
+
+// A FixingSensitivity reports how close a variable came to being fixed by
+// roof duality.  A margin is the difference between a roof-duality lower
+// bound on the energy with the variable clamped to one value and the energy
+// of the best solution found with the variable unclamped.  A positive margin
+// proves that the variable can be fixed to the other value; a negative margin
+// indicates by how much the bound falls short.
+type FixingSensitivity struct {
+	Var     int     // Variable number
+	Margin0 float64 // Lower bound with Var=1 minus best energy; positive implies Var can be fixed to 0
+	Margin1 float64 // Lower bound with Var=0 minus best energy; positive implies Var can be fixed to 1
+}
+
+// FixingSensitivities reports, for each variable of a QUBO problem that roof
+// duality cannot fix, how close the variable came to being fixable.  Large
+// negative margins indicate variables that are far from being fixable; small
+// negative margins indicate variables whose coefficients need only modest
+// strengthening for FixVariables to eliminate them.  The result is sorted by
+// variable number.
+func (p Problem) FixingSensitivities() []FixingSensitivity {
+	// Find the variables that roof duality can already fix.
+	m := newQuboModel(p)
+	_, fixed := m.roofDual()
+
+	// Find a good solution to serve as an upper bound on the minimum
+	// energy.
+	x := make([]int8, len(fixed))
+	for i, f := range fixed {
+		if f == 1 {
+			x[i] = 1
+		}
+	}
+	ub := m.localSearch(x)
+
+	// Probe each unfixed variable in both directions.
+	sens := make([]FixingSensitivity, 0, len(fixed))
+	for i, f := range fixed {
+		if f != -1 {
+			continue
+		}
+		lb1, _ := m.clamp(i, 1).roofDual()
+		lb0, _ := m.clamp(i, 0).roofDual()
+		sens = append(sens, FixingSensitivity{
+			Var:     m.vars[i],
+			Margin0: lb1 - ub,
+			Margin1: lb0 - ub,
+		})
+	}
+	return sens
+}
@@ -0,0 +1,39 @@
+// This file defines an interface common to SAPI solvers and to the various
+// solver wrappers this package provides.
+
+package sapi
+
+// A Sampler is anything that can solve Ising-model and QUBO problems.  Both
+// *Solver and the solver wrappers defined by this package (e.g.,
+// *VirtualGraphSolver) implement Sampler, so application code and solver
+// wrappers can be written once against the interface.
+type Sampler interface {
+	// SolveIsing solves an Ising-model problem.
+	SolveIsing(p Problem, sp SolverParameters) (IsingResult, error)
+
+	// SolveQubo solves a QUBO problem.
+	SolveQubo(p Problem, sp SolverParameters) (IsingResult, error)
+
+	// NewSolverParameters returns a set of parameters appropriate for
+	// passing to SolveIsing and SolveQubo.
+	NewSolverParameters() SolverParameters
+
+	// Properties returns the sampler's properties.  For samplers that
+	// present a logical graph, QuantumProps describes that graph.
+	Properties() *SolverProperties
+}
+
+// Ensure at compile time that all of our samplers implement Sampler.
+var (
+	_ Sampler = (*Solver)(nil)
+	_ Sampler = (*VirtualGraphSolver)(nil)
+	_ Sampler = (*EmbeddingComposite)(nil)
+	_ Sampler = (*FixedEmbeddingComposite)(nil)
+	_ Sampler = (*TilingComposite)(nil)
+	_ Sampler = (*SpinReversalComposite)(nil)
+	_ Sampler = (*FaultInjector)(nil)
+	_ Sampler = (*FilterComposite)(nil)
+	_ Sampler = ExactSolver{}
+	_ Sampler = (*AutoScaleComposite)(nil)
+	_ Sampler = (*ComponentComposite)(nil)
+)
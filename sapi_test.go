@@ -3,8 +3,16 @@
 package sapi_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"github.com/lanl/sapi"
+	"github.com/lanl/sapi/embedder"
+	"io/ioutil"
+	"math"
+	"math/rand"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -111,6 +119,27 @@ func TestLocalSolver(t *testing.T) {
 	prepareLocal(t)
 }
 
+// TestLocalFindSolver ensures we can select a local solver by its
+// capabilities.
+func TestLocalFindSolver(t *testing.T) {
+	conn := sapi.LocalConnection()
+	solver, err := conn.FindSolver(sapi.Criteria{
+		NameGlob:      "c4-*",
+		SupportsIsing: true,
+		MinQubits:     4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solver.IsQuantum() {
+		t.Fatalf("Expected a software solver but saw %s", solver.Name)
+	}
+	_, err = conn.FindSolver(sapi.Criteria{RequiresQPU: true})
+	if err == nil {
+		t.Fatal("Expected no quantum solvers on a local connection")
+	}
+}
+
 // prepareRemote is a helper function that initializes a remote connection and
 // solver.
 func prepareRemote(t *testing.T) (*sapi.Connection, *sapi.Solver) {
@@ -202,48 +231,31 @@ func TestRemoteHardwareAdjacency(t *testing.T) {
 	}
 }
 
-// couplersToAdj constructs an adjacency list from a list of couplers.
-func couplersToAdj(cs [][2]int) map[int]map[int]bool {
-	adj := make(map[int]map[int]bool)
-	for _, c := range cs {
-		q0, q1 := c[0], c[1]
-		if _, ok := adj[q0]; !ok {
-			adj[q0] = make(map[int]bool, 8)
-		}
-		adj[q0][q1] = true
-		if _, ok := adj[q1]; !ok {
-			adj[q1] = make(map[int]bool, 8)
-		}
-		adj[q1][q0] = true
-	}
-	return adj
-}
-
 // findFourCycle finds a set of four distinct qubits with connections (0, 1),
 // (1, 2), (2, 3), and (3, 0).
 func findFourCycle(s *sapi.Solver) []int {
-	// Search every set of four neighbors until we find a square.
+	// Construct an adjacency index from the solver's couplers.
 	props := s.Properties()
-	adj := couplersToAdj(props.QuantumProps.Couplers)
+	couplers := make(sapi.Problem, len(props.QuantumProps.Couplers))
+	for i, c := range props.QuantumProps.Couplers {
+		couplers[i] = sapi.ProblemEntry{I: c[0], J: c[1]}
+	}
+	g := couplers.Graph()
+
+	// Search every set of four neighbors until we find a square.
 	for _, q0 := range props.QuantumProps.Qubits {
-		for q1 := range adj[q0] {
-			if q1 == q0 {
-				continue
-			}
-			for q2 := range adj[q1] {
-				if q2 == q1 || q2 == q0 {
+		for _, q1 := range g.Neighbors(q0) {
+			for _, q2 := range g.Neighbors(q1) {
+				if q2 == q0 {
 					continue
 				}
-				for q3 := range adj[q2] {
-					if q3 == q2 || q3 == q1 || q3 == q0 {
+				for _, q3 := range g.Neighbors(q2) {
+					if q3 == q1 || q3 == q0 {
 						continue
 					}
 
 					// Ensure we have a square.
-					if adj[q0][q1] &&
-						adj[q1][q2] &&
-						adj[q2][q3] &&
-						adj[q3][q0] {
+					if g.Adjacent(q3, q0) {
 						return []int{q0, q1, q2, q3}
 					}
 				}
@@ -442,21 +454,380 @@ func TestRemoteAsyncSolveQubo(t *testing.T) {
 	testAnd(t, true, solver, run)
 }
 
+// TestLocalValidateProblem ensures that ValidateProblem accepts a valid
+// problem and reports every flaw in an invalid one.
+func TestLocalValidateProblem(t *testing.T) {
+	_, solver := prepareLocal(t)
+	sp := solver.NewSolverParameters()
+	cyc := findFourCycle(solver)
+	good := sapi.Problem{{I: cyc[0], J: cyc[1], Value: -1.0}}
+	if err := solver.ValidateProblem(good, sp); err != nil {
+		t.Fatal(err)
+	}
+	bad := sapi.Problem{
+		{I: cyc[0], J: cyc[2], Value: -1.0},
+		{I: cyc[0], J: cyc[0], Value: 100.0},
+	}
+	nErrs := 1
+	if ir := solver.Properties().IsingRanges; ir != nil && ir.HMax < 100.0 {
+		nErrs++
+	}
+	err := solver.ValidateProblem(bad, sp)
+	if ve, ok := err.(sapi.ValidationError); !ok || len(ve) != nErrs {
+		t.Fatalf("Expected %d validation errors but saw %v", nErrs, err)
+	}
+}
+
+// TestLocalCheckParameters ensures that CheckParameters rejects parameters
+// of the wrong type for a solver.
+func TestLocalCheckParameters(t *testing.T) {
+	_, solver := prepareLocal(t)
+	if err := solver.CheckParameters(solver.NewSolverParameters()); err != nil {
+		t.Fatal(err)
+	}
+	if err := solver.CheckParameters(&sapi.QuantumSolverParameters{}); err == nil {
+		t.Fatalf("Expected solver %s to reject quantum solver parameters", solver.Name)
+	}
+}
+
+// TestLocalSolveContext ensures that SolveIsingContext solves a problem when
+// its context is live and refuses to when its context is canceled.
+func TestLocalSolveContext(t *testing.T) {
+	_, solver := prepareLocal(t)
+	cyc := findFourCycle(solver)
+	p := sapi.Problem{{I: cyc[0], J: cyc[1], Value: -1.0}}
+	if _, err := solver.SolveIsingContext(context.Background(), p, solver.NewSolverParameters()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := solver.SolveIsingContext(ctx, p, solver.NewSolverParameters()); err != context.Canceled {
+		t.Fatalf("Expected %v but saw %v", context.Canceled, err)
+	}
+}
+
+// TestLocalSolveMany ensures that SolveMany returns results in the same
+// order as the problems.
+func TestLocalSolveMany(t *testing.T) {
+	_, solver := prepareLocal(t)
+	cyc := findFourCycle(solver)
+	probs := make([]sapi.Problem, 5)
+	for i := range probs {
+		// Alternate between ferromagnetic and antiferromagnetic
+		// couplers.
+		j := -1.0
+		if i%2 == 1 {
+			j = 1.0
+		}
+		probs[i] = sapi.Problem{{I: cyc[0], J: cyc[1], Value: j}}
+	}
+	results, err := solver.SolveMany(probs, solver.NewSolverParameters(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, res := range results {
+		s0, s1 := res.Solutions[0][cyc[0]], res.Solutions[0][cyc[1]]
+		if (i%2 == 0) != (s0 == s1) {
+			t.Fatalf("Result %d has spins %d and %d", i, s0, s1)
+		}
+	}
+}
+
+// TestLocalRefreshProperties ensures that refreshing an unchanged solver's
+// properties does not report a change to its working graph.
+func TestLocalRefreshProperties(t *testing.T) {
+	conn, solver := prepareLocal(t)
+	changed := false
+	conn.OnEvent(func(ev sapi.Event) {
+		if ev.Kind == sapi.EventGraphChanged {
+			changed = true
+		}
+	})
+	props := solver.Properties()
+	if solver.Properties() != props {
+		t.Fatal("Expected Properties to return cached properties")
+	}
+	fresh, err := solver.RefreshProperties()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fresh, props) {
+		t.Fatal("Expected refreshed properties to match the originals")
+	}
+	if changed {
+		t.Fatal("Unexpected EventGraphChanged")
+	}
+}
+
+// TestLocalValidateParameters ensures that Validate accepts a solver's
+// default parameters and reports every invalid value.
+func TestLocalValidateParameters(t *testing.T) {
+	_, solver := prepareLocal(t)
+	sp := solver.NewSolverParameters()
+	if err := sp.Validate(solver); err != nil {
+		t.Fatal(err)
+	}
+	sosp := sp.(*sapi.SwOptimizeSolverParameters)
+	sosp.NumReads = -1
+	sosp.AnswerMode = 99
+	err := sp.Validate(solver)
+	if ve, ok := err.(sapi.ValidationError); !ok || len(ve) != 2 {
+		t.Fatalf("Expected a ValidationError with two reasons but saw %v", err)
+	}
+}
+
+// TestLocalSolveWith ensures that SolveIsingWith applies per-call overrides
+// without modifying the base parameters.
+func TestLocalSolveWith(t *testing.T) {
+	_, solver := prepareLocal(t)
+	cyc := findFourCycle(solver)
+	p := sapi.Problem{{I: cyc[0], J: cyc[1], Value: -1.0}}
+	base := solver.NewSolverParameters()
+	orig := base.Clone()
+	if _, err := solver.SolveIsingWith(p, base, sapi.WithNumReads(7)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(base, orig) {
+		t.Fatal("Expected SolveIsingWith to leave the base parameters unmodified")
+	}
+}
+
+// TestRemoteAnnealOffsets ensures that SetAnnealOffsets accepts in-range
+// offsets and rejects out-of-range offsets.
+func TestRemoteAnnealOffsets(t *testing.T) {
+	_, solver := prepareRemote(t)
+	aop := solver.Properties().AnnealOffsets
+	if aop == nil || len(aop.Ranges) == 0 {
+		t.Skipf("Solver %s does not support anneal offsets", solver.Name)
+	}
+	qsp, ok := solver.NewSolverParameters().(*sapi.QuantumSolverParameters)
+	if !ok {
+		t.Skipf("Solver %s is not a quantum solver", solver.Name)
+	}
+	if err := qsp.SetAnnealOffsets([]float64{0.0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := qsp.SetAnnealOffsets([]float64{aop.Ranges[0][1] + 1.0}); err == nil {
+		t.Fatal("Expected an out-of-range anneal offset to be rejected")
+	}
+}
+
+// TestAnnealSchedule ensures that SetAnnealSchedule accepts a
+// pause-and-quench schedule and rejects malformed schedules.
+func TestAnnealSchedule(t *testing.T) {
+	var qsp sapi.QuantumSolverParameters
+	pause := []sapi.SchedulePoint{{0, 0}, {10, 0.4}, {60, 0.4}, {61, 1}}
+	if err := qsp.SetAnnealSchedule(pause); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][]sapi.SchedulePoint{
+		{{1, 0}, {20, 1}},            // Does not start at time 0
+		{{0, 0}, {20, 0.5}},          // Does not end at s = 1
+		{{0, 0}, {10, 0.5}, {5, 1}},  // Time decreases
+		{{0, 0}, {10, 1.5}, {20, 1}}, // s out of range
+	} {
+		if err := qsp.SetAnnealSchedule(bad); err == nil {
+			t.Fatalf("Expected schedule %v to be rejected", bad)
+		}
+	}
+	if len(qsp.AnnealSchedule) != len(pause) {
+		t.Fatal("Expected a rejected schedule to leave the parameters unmodified")
+	}
+}
+
+// TestAdaptiveAllocator ensures that an AdaptiveAllocator stops allocating
+// reads to a problem once it converges and spends the rest of its budget on
+// the problems that have not.
+func TestAdaptiveAllocator(t *testing.T) {
+	easy := sapi.Problem{{I: 0, J: 0, Value: -1.0}}
+	hard := make(sapi.Problem, 0, 12)
+	for i := 0; i < 12; i++ {
+		hard = append(hard, sapi.ProblemEntry{I: i, J: i, Value: -1.0})
+	}
+	fi := sapi.NewFaultInjector(nil, 1)
+	aa := sapi.NewAdaptiveAllocator(fi, 2000)
+	aa.RoundReads = 50
+	res, err := aa.SolveIsing([]sapi.Problem{easy, hard}, fi.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res[0].Converged || res[0].Reads != 50 {
+		t.Fatalf("Expected the easy problem to converge after 50 reads but saw %d reads (converged = %v)",
+			res[0].Reads, res[0].Converged)
+	}
+	if res[0].Reads+res[1].Reads != 2000 {
+		t.Fatalf("Expected 2000 reads to be allocated but saw %d", res[0].Reads+res[1].Reads)
+	}
+	for i, e := range res[1].Energies[1:] {
+		if e < res[1].Energies[i] {
+			t.Fatal("Expected merged solutions to be sorted by energy")
+		}
+	}
+}
+
+// TestFluxBiases ensures that SetFluxBiases accepts finite offsets and
+// rejects non-finite offsets.
+func TestFluxBiases(t *testing.T) {
+	var qsp sapi.QuantumSolverParameters
+	if err := qsp.SetFluxBiases([]float64{0.0, 1e-5, -2e-5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := qsp.SetFluxBiases([]float64{0.0, math.NaN()}); err == nil {
+		t.Fatal("Expected a NaN flux-bias offset to be rejected")
+	}
+	if len(qsp.FluxBiases) != 3 {
+		t.Fatal("Expected a rejected offset to leave the parameters unmodified")
+	}
+}
+
+// TestPostprocessing ensures that SetPostprocessing accepts valid settings
+// and rejects invalid ones.
+func TestPostprocessing(t *testing.T) {
+	var qsp sapi.QuantumSolverParameters
+	chains := sapi.Embeddings{0, 0, 1, -1}
+	if err := qsp.SetPostprocessing(sapi.PostprocessSampling, 3.0, chains); err != nil {
+		t.Fatal(err)
+	}
+	if qsp.Postprocess != sapi.PostprocessSampling || qsp.Beta != 3.0 || len(qsp.Chains) != 4 {
+		t.Fatalf("Unexpected parameters %#v", qsp)
+	}
+	if err := qsp.SetPostprocessing(sapi.PostprocessOptimization, -1.0, nil); err == nil {
+		t.Fatal("Expected a negative beta to be rejected")
+	}
+	if err := qsp.SetPostprocessChains([][]int{{3, 1}, {0}}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(qsp.Chains, []int{1, 0, -1, 0}) {
+		t.Fatalf("Expected chains [1 0 -1 0] but saw %v", qsp.Chains)
+	}
+	if err := qsp.SetPostprocessChains([][]int{{0, 1}, {1, 2}}); err == nil {
+		t.Fatal("Expected overlapping chains to be rejected")
+	}
+}
+
+// TestMaxAnswers ensures that SetMaxAnswers rejects non-positive caps and
+// that NumOccurrences treats a raw-mode result as one read per solution.
+func TestMaxAnswers(t *testing.T) {
+	var sosp sapi.SwOptimizeSolverParameters
+	if err := sosp.SetMaxAnswers(10); err != nil || sosp.MaxAnswers != 10 {
+		t.Fatalf("Expected MaxAnswers to be set to 10 but saw %d (%v)", sosp.MaxAnswers, err)
+	}
+	if err := sosp.SetMaxAnswers(0); err == nil {
+		t.Fatal("Expected a cap of 0 to be rejected")
+	}
+	raw := sapi.IsingResult{Solutions: [][]int8{{1}, {1}}, Energies: []float64{-1, -1}}
+	hist := sapi.IsingResult{Solutions: [][]int8{{1}}, Energies: []float64{-1}, Occurrences: []int{2}}
+	if raw.NumOccurrences(1) != 1 || hist.NumOccurrences(0) != 2 {
+		t.Fatal("Unexpected NumOccurrences")
+	}
+}
+
+// TestAutoScaleComposite ensures that an AutoScaleComposite scales a problem
+// to fill the Ising ranges and reports energies in the original frame.
+func TestAutoScaleComposite(t *testing.T) {
+	var es sapi.ExactSolver
+	p := sapi.Problem{
+		{I: 0, J: 0, Value: 0.25},
+		{I: 0, J: 1, Value: -0.5},
+	}
+	want, err := es.SolveIsing(p, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.ScaleFactor != 1.0 {
+		t.Fatalf("Expected an unscaled result to have a scale factor of 1 but saw %v", want.ScaleFactor)
+	}
+	as := sapi.NewAutoScaleComposite(es)
+	got, err := as.SolveIsing(p, as.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ScaleFactor != 2.0 {
+		t.Fatalf("Expected a scale factor of 2 but saw %v", got.ScaleFactor)
+	}
+	if !reflect.DeepEqual(got.Energies, want.Energies) {
+		t.Fatalf("Expected energies %v but saw %v", want.Energies, got.Energies)
+	}
+}
+
+// TestLocalEvents ensures that a Connection reports lifecycle events for an
+// asynchronously submitted problem.
+func TestLocalEvents(t *testing.T) {
+	conn, solver := prepareLocal(t)
+	seen := make(map[sapi.EventKind]int)
+	conn.OnEvent(func(ev sapi.Event) {
+		seen[ev.Kind]++
+	})
+	run := func(prob sapi.Problem, sp sapi.SolverParameters) (sapi.IsingResult, error) {
+		sub, err := solver.AsyncSolveIsing(prob, sp)
+		if err != nil {
+			return sapi.IsingResult{}, err
+		}
+		for !sub.AwaitCompletion(3 * time.Second) {
+		}
+		return sub.Result()
+	}
+	testAnd(t, true, solver, run)
+	for _, k := range []sapi.EventKind{sapi.EventSubmitted, sapi.EventCompleted} {
+		if seen[k] != 1 {
+			t.Fatalf("Expected exactly one %q event but saw %d", k, seen[k])
+		}
+	}
+}
+
+// xorProblem returns an Ising-model problem representing an XOR function, not
+// embedded in a Chimera graph.
+func xorProblem() sapi.Problem {
+	var pb sapi.ProblemBuilder
+	pb.AddLinear(0, 0.5)
+	pb.AddLinear(1, 0.5)
+	pb.AddLinear(2, 0.5)
+	pb.AddLinear(3, -1.0)
+	pb.AddQuadratic(0, 1, 0.5)
+	pb.AddQuadratic(0, 2, 0.5)
+	pb.AddQuadratic(0, 3, -1.0)
+	pb.AddQuadratic(1, 2, 0.5)
+	pb.AddQuadratic(1, 3, -1.0)
+	pb.AddQuadratic(2, 3, -1.0)
+	return pb.Build()
+}
+
+// verifyXor ensures that the lowest-energy solutions to xorProblem are
+// correct.  Because the energy of a correct solution may depend on the
+// embedding, we check all lowest-energy solutions and ignore all
+// higher-energy solutions.
+func verifyXor(t *testing.T, solns [][]int8, energies []float64) {
+	correctEnergy := energies[0]
+	for _, e := range energies {
+		if e < correctEnergy {
+			correctEnergy = e
+		}
+	}
+	nSolns := 0
+	for i, soln := range solns {
+		a, b, y := (soln[0]+1)/2, (soln[1]+1)/2, (soln[2]+1)/2
+		e := energies[i]
+		if e > correctEnergy {
+			t.Logf("Ignoring high-energy (%.2f) solution %v XOR %v = %v",
+				e, a == 1, b == 1, y == 1)
+			continue
+		}
+		t.Logf("Considering solution %v XOR %v = %v (energy = %.2f)",
+			a == 1, b == 1, y == 1, e)
+		if (a ^ b) != y {
+			t.Fatalf("Saw %v XOR %v = %v in solution %d (energy = %f)", a == 1, b == 1, y == 1, i+1, e)
+		}
+		nSolns++
+	}
+	if nSolns == 0 {
+		t.Fatalf("Saw no valid solutions (and %d invalid ones)", len(solns))
+	}
+}
+
 // testEmbedding ensures we can embed an XOR problem in a solver's topology,
 // solve it, and get the correct answer.
 func testEmbedding(t *testing.T, solver *sapi.Solver) {
-	// Define an XOR function, not embedded in a Chimera graph.
-	prob := make(sapi.Problem, 10)
-	prob[0] = sapi.ProblemEntry{I: 0, J: 0, Value: 0.5}
-	prob[1] = sapi.ProblemEntry{I: 1, J: 1, Value: 0.5}
-	prob[2] = sapi.ProblemEntry{I: 2, J: 2, Value: 0.5}
-	prob[3] = sapi.ProblemEntry{I: 3, J: 3, Value: -1.0}
-	prob[4] = sapi.ProblemEntry{I: 0, J: 1, Value: 0.5}
-	prob[5] = sapi.ProblemEntry{I: 0, J: 2, Value: 0.5}
-	prob[6] = sapi.ProblemEntry{I: 0, J: 3, Value: -1.0}
-	prob[7] = sapi.ProblemEntry{I: 1, J: 2, Value: 0.5}
-	prob[8] = sapi.ProblemEntry{I: 1, J: 3, Value: -1.0}
-	prob[9] = sapi.ProblemEntry{I: 2, J: 3, Value: -1.0}
+	prob := xorProblem()
 
 	// Retrieve the solver's adjacency graph and coefficient ranges.
 	adj, err := solver.HardwareAdjacency()
@@ -522,29 +893,8 @@ func testEmbedding(t *testing.T, solver *sapi.Solver) {
 		t.Fatal(err)
 	}
 
-	// Validate the solutions.  Because the energy of a correct solution
-	// depends on the embedding, we check all lowest-energy solutions and
-	// ignore all higher-energy solutions.
-	correctEnergy := res.Energies[0]
-	nSolns := 0
-	for i, soln := range solns {
-		a, b, y := (soln[0]+1)/2, (soln[1]+1)/2, (soln[2]+1)/2
-		e := res.Energies[i]
-		if e > correctEnergy {
-			t.Logf("Ignoring high-energy (%.2f) solution %v XOR %v = %v",
-				e, a == 1, b == 1, y == 1)
-			continue
-		}
-		t.Logf("Considering solution %v XOR %v = %v (energy = %.2f)",
-			a == 1, b == 1, y == 1, e)
-		if (a ^ b) != y {
-			t.Fatalf("Saw %v XOR %v = %v in solution %d (energy = %f)", a == 1, b == 1, y == 1, i+1, e)
-		}
-		nSolns++
-	}
-	if nSolns == 0 {
-		t.Fatalf("Saw no valid solutions (and %d invalid ones)", len(solns))
-	}
+	// Validate the solutions.
+	verifyXor(t, solns, res.Energies)
 }
 
 // TestLocalEmbedding ensures we can embed a problem in a local solver's
@@ -561,76 +911,441 @@ func TestRemoteEmbedding(t *testing.T) {
 	testEmbedding(t, solver)
 }
 
-// TestFixVariables ensures that FixVariables can detect that a problem
-// variable is unnecessary.
-func TestFixVariables(t *testing.T) {
-	// Construct a QUBO problem.
-	prob := make(sapi.Problem, 8)
-	prob[0] = sapi.ProblemEntry{I: 1, J: 1, Value: 1}
-	prob[1] = sapi.ProblemEntry{I: 2, J: 2, Value: 1}
-	prob[2] = sapi.ProblemEntry{I: 3, J: 3, Value: 1}
-	prob[3] = sapi.ProblemEntry{I: 4, J: 4, Value: 3}
-	prob[4] = sapi.ProblemEntry{I: 1, J: 2, Value: 1}
-	prob[5] = sapi.ProblemEntry{I: 1, J: 3, Value: -2}
-	prob[6] = sapi.ProblemEntry{I: 2, J: 3, Value: -2}
-	prob[7] = sapi.ProblemEntry{I: 1, J: 4, Value: 4}
-
-	// Find fixed variables.
-	fvr, err := prob.FixVariables(sapi.FixVariablesMethodOptimized)
+// TestLocalEmbeddingComposite ensures that an EmbeddingComposite can embed,
+// solve, and unembed a problem in a single call.
+func TestLocalEmbeddingComposite(t *testing.T) {
+	_, solver := prepareLocal(t)
+	ec, err := sapi.NewEmbeddingComposite(solver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec.ChainStrength = -2.0
+	sp := ec.NewSolverParameters()
+	if sp, ok := sp.(*sapi.SwOptimizeSolverParameters); ok {
+		sp.NumReads = 1000
+	}
+	res, err := ec.SolveIsing(xorProblem(), sp)
 	if err != nil {
 		t.Fatal(err)
 	}
+	verifyXor(t, res.Solutions, res.Energies)
+}
 
-	// Verify the result.
-	v, ok := fvr.FixedVars[4]
-	if !ok {
-		t.Fatal("Expected to see variable 4 fixed, but it wasn't")
+// TestLocalTilingComposite ensures that a TilingComposite returns more
+// solutions than were requested by solving multiple copies of a problem.
+func TestLocalTilingComposite(t *testing.T) {
+	_, solver := prepareLocal(t)
+	tc, err := sapi.NewTilingComposite(solver)
+	if err != nil {
+		t.Fatal(err)
 	}
-	if v != 0 {
-		t.Fatalf("Expected to see variable 4 fixed to 0, but it was fixed to %d", v)
+	tc.ChainStrength = -2.0
+	tc.MaxTiles = 4
+	tiles, err := tc.Tile(xorProblem())
+	if err != nil {
+		t.Fatal(err)
 	}
-	delete(fvr.FixedVars, 4)
-	for k, v := range fvr.FixedVars {
-		t.Fatalf("Did not expect variable %d to be fixed to %d", k, v)
+	if len(tiles) < 2 {
+		t.Fatalf("Expected at least 2 tiles but saw %d", len(tiles))
 	}
+	sp := tc.NewSolverParameters()
+	if sp, ok := sp.(*sapi.SwOptimizeSolverParameters); ok {
+		sp.NumReads = 10
+		sp.AnswerMode = sapi.AnswerModeRaw
+	}
+	res, err := tc.SolveIsing(xorProblem(), sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Solutions) != 10*len(tiles) {
+		t.Fatalf("Expected %d solutions but saw %d", 10*len(tiles), len(res.Solutions))
+	}
+	verifyXor(t, res.Solutions, res.Energies)
 }
 
-// TestCanonicalize tests that we can correctly canonicalize a Problem.
-func TestCanonicalize(t *testing.T) {
-	// Canonicalize a dummy problem.
-	orig := sapi.Problem{
-		sapi.ProblemEntry{I: 3, J: 2, Value: 1},
-		sapi.ProblemEntry{I: 5, J: 5, Value: 2},
-		sapi.ProblemEntry{I: 2, J: 3, Value: 3},
-		sapi.ProblemEntry{I: 5, J: 5, Value: 4},
-		sapi.ProblemEntry{I: 3, J: 5, Value: 5},
-		sapi.ProblemEntry{I: 2, J: 3, Value: 6},
-		sapi.ProblemEntry{I: 4, J: 1, Value: 7},
-		sapi.ProblemEntry{I: 6, J: 6, Value: 8},
+// TestLocalSpinReversalComposite ensures that a SpinReversalComposite
+// divides reads across gauges and correctly un-transforms the solutions.
+func TestLocalSpinReversalComposite(t *testing.T) {
+	_, solver := prepareLocal(t)
+	ec, err := sapi.NewEmbeddingComposite(solver)
+	if err != nil {
+		t.Fatal(err)
 	}
-	expected := sapi.Problem{
-		sapi.ProblemEntry{I: 1, J: 4, Value: 7},
-		sapi.ProblemEntry{I: 2, J: 3, Value: 10},
-		sapi.ProblemEntry{I: 3, J: 5, Value: 5},
-		sapi.ProblemEntry{I: 5, J: 5, Value: 6},
-		sapi.ProblemEntry{I: 6, J: 6, Value: 8},
+	ec.ChainStrength = -2.0
+	sr := sapi.NewSpinReversalComposite(ec, 3)
+	sp := sr.NewSolverParameters()
+	if sp, ok := sp.(*sapi.SwOptimizeSolverParameters); ok {
+		sp.NumReads = 10
+		sp.AnswerMode = sapi.AnswerModeRaw
 	}
-	canon := orig.Canonicalize()
-
-	// Ensure we received what we expected.
-	if len(canon) != len(expected) {
-		t.Fatalf("Expected %v but saw %v", expected, canon)
+	res, err := sr.SolveIsing(xorProblem(), sp)
+	if err != nil {
+		t.Fatal(err)
 	}
-	for i, pe := range canon {
-		if pe != expected[i] {
-			t.Fatalf("Expected %v but saw %v", expected, canon)
-		}
+	if len(res.Solutions) != 10 {
+		t.Fatalf("Expected 10 solutions but saw %d", len(res.Solutions))
 	}
+	verifyXor(t, res.Solutions, res.Energies)
 }
 
-// TestIsingQubo converts an Ising problem to QUBO and back.
-func TestIsingQubo(t *testing.T) {
-	// Convert from Ising to QUBO and back.
+// TestLocalDryRun ensures that a dry run of an embedded problem reports the
+// embedded problem without solving it.
+func TestLocalDryRun(t *testing.T) {
+	_, solver := prepareLocal(t)
+	ec, err := sapi.NewEmbeddingComposite(solver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl, err := ec.DryRunIsing(xorProblem(), ec.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.Solver != solver.Name || pl.ProblemType != "ising" {
+		t.Fatalf("Unexpected payload header %q/%q", pl.Solver, pl.ProblemType)
+	}
+	if pl.NumVariables < 4 || pl.Embedding == nil {
+		t.Fatalf("Expected an embedded problem but saw %d variables", pl.NumVariables)
+	}
+}
+
+// TestLocalFixedEmbeddingComposite ensures that a FixedEmbeddingComposite
+// reuses a single embedding and rejects problems that do not fit it.
+func TestLocalFixedEmbeddingComposite(t *testing.T) {
+	// Embed the XOR problem once.
+	_, solver := prepareLocal(t)
+	adj, err := solver.HardwareAdjacency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fep := sapi.NewFindEmbeddingParameters()
+	fep.Verbose = false
+	emb, err := sapi.FindEmbedding(xorProblem(), adj, fep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fe, err := sapi.NewFixedEmbeddingComposite(solver, emb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fe.ChainStrength = -2.0
+
+	// Solve the problem using the fixed embedding.
+	res, err := fe.SolveIsing(xorProblem(), fe.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyXor(t, res.Solutions, res.Energies)
+
+	// Ensure that a problem with an unembedded variable is rejected.
+	bad := append(xorProblem(), sapi.ProblemEntry{I: 4, J: 4, Value: 1.0})
+	if err := fe.Fits(bad); err == nil {
+		t.Fatal("Expected a problem with an unembedded variable to be rejected")
+	}
+}
+
+// TestComponents ensures that a problem is correctly partitioned into its
+// connected components.
+func TestComponents(t *testing.T) {
+	p := sapi.Problem{
+		{I: 0, J: 5, Value: 1.0},
+		{I: 5, J: 5, Value: -0.5},
+		{I: 2, J: 3, Value: -1.0},
+		{I: 3, J: 4, Value: 0.0},
+		{I: 3, J: 3, Value: 0.25},
+	}
+	comps := p.Components()
+	vars := make([][]int, len(comps))
+	for i, c := range comps {
+		vars[i] = c.Vars
+	}
+	expected := [][]int{{0, 5}, {2, 3}, {4}}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatalf("Expected components %v but saw %v", expected, vars)
+	}
+	if len(comps[1].Problem) != 2 || comps[1].Problem[0] != (sapi.ProblemEntry{I: 0, J: 1, Value: -1.0}) {
+		t.Fatalf("Incorrectly renumbered component %v", comps[1].Problem)
+	}
+}
+
+// TestLocalRouter ensures that a Router can solve two independent copies of
+// a problem and recombine the results.
+func TestLocalRouter(t *testing.T) {
+	// Construct two disjoint copies of the XOR problem.
+	_, solver := prepareLocal(t)
+	ec, err := sapi.NewEmbeddingComposite(solver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec.ChainStrength = -2.0
+	p := xorProblem()
+	for _, pe := range xorProblem() {
+		p = append(p, sapi.ProblemEntry{I: pe.I + 4, J: pe.J + 4, Value: pe.Value})
+	}
+
+	// Solve both copies and verify each of them.
+	r := sapi.Router{Routes: []sapi.Route{{Name: "embedded", Sampler: ec}}}
+	res, err := r.SolveIsing(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Components) != 2 {
+		t.Fatalf("Expected 2 components but saw %d", len(res.Components))
+	}
+	for _, off := range []int{0, 4} {
+		solns := make([][]int8, len(res.Solutions))
+		for i, soln := range res.Solutions {
+			solns[i] = soln[off : off+4]
+		}
+		verifyXor(t, solns, res.Energies)
+	}
+}
+
+// TestPrecisionPolicy ensures that a PrecisionPolicy rounds coefficients and
+// reports underflow.
+func TestPrecisionPolicy(t *testing.T) {
+	p := sapi.Problem{
+		{I: 0, J: 0, Value: 1.0},
+		{I: 0, J: 1, Value: -0.3},
+		{I: 1, J: 1, Value: 0.01},
+	}
+	var under []sapi.ProblemEntry
+	pp := &sapi.PrecisionPolicy{
+		Bits:      3,
+		Underflow: func(pe sapi.ProblemEntry) { under = append(under, pe) },
+	}
+	rp := pp.Apply(p)
+	expected := []float64{1.0, -0.25, 0.0}
+	for i, pe := range rp {
+		if pe.Value != expected[i] {
+			t.Fatalf("Expected coefficient %d to round to %v but saw %v", i, expected[i], pe.Value)
+		}
+	}
+	if len(under) != 1 || under[0] != p[2] {
+		t.Fatalf("Expected underflow of %v but saw %v", p[2], under)
+	}
+	if p[1].Value != -0.3 {
+		t.Fatal("Apply modified its argument")
+	}
+}
+
+// TestFaultInjector ensures that a FaultInjector injects failures and
+// otherwise returns valid solutions.
+func TestFaultInjector(t *testing.T) {
+	// Ensure that a network failure is reported as such.
+	fi := sapi.NewFaultInjector(nil, 1)
+	fi.NetworkRate = 1.0
+	_, err := fi.SolveIsing(xorProblem(), fi.NewSolverParameters())
+	if e, ok := err.(sapi.Error); !ok || e.N != sapi.NetworkError {
+		t.Fatalf("Expected a network error but saw %v", err)
+	}
+
+	// Ensure that random solutions are sorted by energy.
+	fi.NetworkRate = 0.0
+	sp := fi.NewSolverParameters().(*sapi.SwOptimizeSolverParameters)
+	sp.NumReads = 20
+	res, err := fi.SolveIsing(xorProblem(), sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Solutions) != 20 {
+		t.Fatalf("Expected 20 solutions but saw %d", len(res.Solutions))
+	}
+	for i := 1; i < len(res.Energies); i++ {
+		if res.Energies[i] < res.Energies[i-1] {
+			t.Fatalf("Energies are not sorted: %v", res.Energies)
+		}
+	}
+}
+
+// TestFilterComposite ensures that a FilterComposite applies an energy
+// cutoff and a limit on the number of solutions.
+func TestFilterComposite(t *testing.T) {
+	cutoff := 0.0
+	fc := &sapi.FilterComposite{
+		Sampler:    sapi.NewFaultInjector(nil, 2),
+		KeepLowest: 5,
+		MaxEnergy:  &cutoff,
+	}
+	sp := fc.NewSolverParameters().(*sapi.SwOptimizeSolverParameters)
+	sp.NumReads = 50
+	res, err := fc.SolveIsing(xorProblem(), sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Solutions) == 0 || len(res.Solutions) > 5 {
+		t.Fatalf("Expected 1-5 solutions but saw %d", len(res.Solutions))
+	}
+	for _, e := range res.Energies {
+		if e > cutoff {
+			t.Fatalf("Saw energy %v above the cutoff of %v", e, cutoff)
+		}
+	}
+}
+
+// TestFilterCompositeDiscardUnsupported ensures that a FilterComposite
+// refuses to discard broken chains when its sampler does not handle chains.
+func TestFilterCompositeDiscardUnsupported(t *testing.T) {
+	fc := &sapi.FilterComposite{
+		Sampler:             sapi.NewFaultInjector(nil, 2),
+		DiscardBrokenChains: true,
+	}
+	sp := fc.NewSolverParameters()
+	if _, err := fc.SolveIsing(xorProblem(), sp); err == nil {
+		t.Fatal("Expected DiscardBrokenChains to be rejected")
+	}
+}
+
+// TestReservoir ensures that a Reservoir bounds its sample size and computes
+// correct summary statistics.
+func TestReservoir(t *testing.T) {
+	r := sapi.NewReservoir(3, 1)
+	for i := 0; i < 4; i++ {
+		r.Add(sapi.IsingResult{
+			Solutions:   [][]int8{{1, -1}, {-1, -1}},
+			Energies:    []float64{-2.0, 2.0},
+			Occurrences: []int{1, 2},
+		})
+	}
+	if n := len(r.Samples()); n != 3 {
+		t.Fatalf("Expected 3 samples but saw %d", n)
+	}
+	st := r.Stats()
+	if st.Count != 12 || st.MinEnergy != -2.0 || st.MaxEnergy != 2.0 {
+		t.Fatalf("Incorrect statistics %+v", st)
+	}
+	if math.Abs(st.MeanEnergy-2.0/3.0) > 1e-9 {
+		t.Fatalf("Expected a mean energy of %v but saw %v", 2.0/3.0, st.MeanEnergy)
+	}
+}
+
+// TestSetRandSource ensures that supplying a deterministic source of
+// randomness makes randomized components reproducible.
+func TestSetRandSource(t *testing.T) {
+	defer sapi.SetRandSource(nil)
+	run := func() sapi.IsingResult {
+		sapi.SetRandSource(rand.NewSource(42).(rand.Source64))
+		sr := sapi.NewSpinReversalComposite(sapi.NewFaultInjector(nil, 1), 4)
+		sp := sr.NewSolverParameters().(*sapi.SwOptimizeSolverParameters)
+		sp.NumReads = 20
+		res, err := sr.SolveIsing(xorProblem(), sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	if r1, r2 := run(), run(); !reflect.DeepEqual(r1, r2) {
+		t.Fatal("Expected identical results from identically seeded runs")
+	}
+
+	// Ensure that a component created while a source was set remains
+	// usable after the source is removed.
+	sapi.SetRandSource(rand.NewSource(42).(rand.Source64))
+	sr := sapi.NewSpinReversalComposite(sapi.NewFaultInjector(nil, 1), 4)
+	sapi.SetRandSource(nil)
+	if _, err := sr.SolveIsing(xorProblem(), sr.NewSolverParameters()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestIncrementalUnembedder ensures that unembedding answers in chunks
+// produces correct running aggregates.
+func TestIncrementalUnembedder(t *testing.T) {
+	// Map qubits 0 and 1 to variable 0 and qubit 2 to variable 1.
+	emb := sapi.Embeddings{0, 0, 1}
+	prob := sapi.Problem{{I: 0, J: 1, Value: -1.0}}
+	iu := sapi.NewIncrementalUnembedder(emb, sapi.BrokenChainsVote, prob)
+	chunks := [][][]int8{
+		{{1, 1, -1}, {-1, -1, -1}},
+		{{1, 1, 1}},
+	}
+	for _, c := range chunks {
+		if _, err := iu.Add(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st := iu.Stats()
+	if st.PhysicalSamples != 3 || st.LogicalSamples != 3 || st.BestEnergy != -1.0 {
+		t.Fatalf("Incorrect statistics %+v", st)
+	}
+	expected := []float64{1.0 / 3.0, -1.0 / 3.0}
+	for v, m := range st.Magnetizations {
+		if math.Abs(m-expected[v]) > 1e-9 {
+			t.Fatalf("Expected magnetizations %v but saw %v", expected, st.Magnetizations)
+		}
+	}
+}
+
+// TestFixVariables ensures that FixVariables can detect that a problem
+// variable is unnecessary.
+func TestFixVariables(t *testing.T) {
+	// Construct a QUBO problem.
+	var pb sapi.ProblemBuilder
+	pb.AddLinear(1, 1)
+	pb.AddLinear(2, 1)
+	pb.AddLinear(3, 1)
+	pb.AddLinear(4, 3)
+	pb.AddQuadratic(1, 2, 1)
+	pb.AddQuadratic(1, 3, -2)
+	pb.AddQuadratic(2, 3, -2)
+	pb.AddQuadratic(1, 4, 4)
+	prob := pb.Build()
+
+	// Find fixed variables.
+	fvr, err := prob.FixVariables(sapi.FixVariablesMethodOptimized)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the result.
+	v, ok := fvr.FixedVars[4]
+	if !ok {
+		t.Fatal("Expected to see variable 4 fixed, but it wasn't")
+	}
+	if v != 0 {
+		t.Fatalf("Expected to see variable 4 fixed to 0, but it was fixed to %d", v)
+	}
+	delete(fvr.FixedVars, 4)
+	for k, v := range fvr.FixedVars {
+		t.Fatalf("Did not expect variable %d to be fixed to %d", k, v)
+	}
+}
+
+// TestCanonicalize tests that we can correctly canonicalize a Problem.
+func TestCanonicalize(t *testing.T) {
+	// Canonicalize a dummy problem.
+	orig := sapi.Problem{
+		sapi.ProblemEntry{I: 3, J: 2, Value: 1},
+		sapi.ProblemEntry{I: 5, J: 5, Value: 2},
+		sapi.ProblemEntry{I: 2, J: 3, Value: 3},
+		sapi.ProblemEntry{I: 5, J: 5, Value: 4},
+		sapi.ProblemEntry{I: 3, J: 5, Value: 5},
+		sapi.ProblemEntry{I: 2, J: 3, Value: 6},
+		sapi.ProblemEntry{I: 4, J: 1, Value: 7},
+		sapi.ProblemEntry{I: 6, J: 6, Value: 8},
+	}
+	expected := sapi.Problem{
+		sapi.ProblemEntry{I: 1, J: 4, Value: 7},
+		sapi.ProblemEntry{I: 2, J: 3, Value: 10},
+		sapi.ProblemEntry{I: 3, J: 5, Value: 5},
+		sapi.ProblemEntry{I: 5, J: 5, Value: 6},
+		sapi.ProblemEntry{I: 6, J: 6, Value: 8},
+	}
+	canon := orig.Canonicalize()
+
+	// Ensure we received what we expected.
+	if len(canon) != len(expected) {
+		t.Fatalf("Expected %v but saw %v", expected, canon)
+	}
+	for i, pe := range canon {
+		if pe != expected[i] {
+			t.Fatalf("Expected %v but saw %v", expected, canon)
+		}
+	}
+}
+
+// TestIsingQubo converts an Ising problem to QUBO and back.
+func TestIsingQubo(t *testing.T) {
+	// Convert from Ising to QUBO and back.
 	i1 := sapi.Problem{
 		sapi.ProblemEntry{I: 0, J: 0, Value: 1},
 		sapi.ProblemEntry{I: 1, J: 1, Value: 1},
@@ -665,15 +1380,7 @@ func TestToIsing(t *testing.T) {
 	_, solver := prepareLocal(t)
 
 	solveQubo := func(p sapi.Problem, sp sapi.SolverParameters) (sapi.IsingResult, error) {
-		ip, ofs := p.ToIsing()
-		ir, err := solver.SolveIsing(ip, sp)
-		if err != nil {
-			return ir, err
-		}
-		for i, e := range ir.Energies {
-			ir.Energies[i] = e + ofs
-		}
-		return ir, nil
+		return sapi.OffsetProblem{Problem: p}.ToIsing().SolveIsing(solver, sp)
 	}
 	testAnd(t, false, solver, solveQubo)
 }
@@ -684,17 +1391,1640 @@ func TestToQubo(t *testing.T) {
 	_, solver := prepareLocal(t)
 
 	solveIsing := func(p sapi.Problem, sp sapi.SolverParameters) (sapi.IsingResult, error) {
-		qp, ofs := p.ToQubo()
-		t.Logf("ISING = %v", p.Canonicalize()) // Temporary
-		t.Logf("QUBO = %v", qp.Canonicalize()) // Temporary
-		ir, err := solver.SolveQubo(qp, sp)
+		return sapi.OffsetProblem{Problem: p}.ToQubo().SolveQubo(solver, sp)
+	}
+	testAnd(t, true, solver, solveIsing)
+}
+
+// TestConvertCouplerOnly ensures that ToIsing and ToQubo preserve the
+// energy of every solution even when some variables appear only in
+// quadratic entries.
+func TestConvertCouplerOnly(t *testing.T) {
+	// Variables 0 and 2 have no linear term.
+	p := sapi.Problem{
+		{I: 1, J: 1, Value: -1.0},
+		{I: 0, J: 1, Value: 2.0},
+		{I: 1, J: 2, Value: -0.5},
+	}
+
+	// Check the coefficients of the converted problems.
+	ip, iofs := p.ToIsing()
+	if want := "h[0]=0.5\nJ[0,1]=0.5\nh[1]=-0.125\nJ[1,2]=-0.125\nh[2]=-0.125\n"; ip.String() != want {
+		t.Fatalf("Expected Ising problem %q but saw %q", want, ip.String())
+	}
+	qp, qofs := p.ToQubo()
+	if want := "h[0]=-4\nJ[0,1]=8\nh[1]=-5\nJ[1,2]=-2\nh[2]=1\n"; qp.String() != want {
+		t.Fatalf("Expected QUBO problem %q but saw %q", want, qp.String())
+	}
+
+	// Check that every solution has the same energy before and after
+	// conversion.
+	for bits := 0; bits < 8; bits++ {
+		x := make([]int8, 3)
+		s := make([]int8, 3)
+		for v := range x {
+			x[v] = int8(bits >> uint(v) & 1)
+			s[v] = 2*x[v] - 1
+		}
+		if e1, e2 := p.QuboEnergy(x), ip.IsingEnergy(s)+iofs; math.Abs(e1-e2) > 1e-9 {
+			t.Fatalf("Expected QUBO energy %v for %v but saw Ising energy %v", e1, x, e2)
+		}
+		if e1, e2 := p.IsingEnergy(s), qp.QuboEnergy(x)+qofs; math.Abs(e1-e2) > 1e-9 {
+			t.Fatalf("Expected Ising energy %v for %v but saw QUBO energy %v", e1, s, e2)
+		}
+	}
+}
+
+// TestSolveWithOffset ensures that solving a QUBO problem via its Ising-model
+// equivalent reports the same energies as solving the QUBO problem directly.
+func TestSolveWithOffset(t *testing.T) {
+	var es sapi.ExactSolver
+	qp := sapi.Problem{
+		{I: 0, J: 0, Value: 1.5},
+		{I: 1, J: 1, Value: -2.0},
+		{I: 2, J: 2, Value: 0.25},
+		{I: 0, J: 1, Value: 0.5},
+		{I: 1, J: 2, Value: -1.0},
+	}
+	want, err := es.SolveQubo(qp, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip, ofs := qp.ToIsing()
+	op := sapi.OffsetProblem{Problem: ip, Offset: ofs}
+	got, err := op.SolveIsing(es, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range want.Energies {
+		if math.Abs(got.Energies[i]-e) > 1e-9 {
+			t.Fatalf("Expected energy %d to be %v but saw %v", i, e, got.Energies[i])
+		}
+	}
+}
+
+// TestProblemText ensures that a problem survives a round trip through its
+// textual representation and that malformed text is rejected.
+func TestProblemText(t *testing.T) {
+	p := sapi.Problem{
+		{I: 7, J: 2, Value: 1.0},
+		{I: 3, J: 3, Value: -0.5},
+		{I: 2, J: 2, Value: 0.125},
+	}
+	txt := p.String()
+	if want := "h[2]=0.125\nJ[2,7]=1\nh[3]=-0.5\n"; txt != want {
+		t.Fatalf("Expected %q but saw %q", want, txt)
+	}
+	p2, err := sapi.ParseProblem("# A comment\n" + txt + "J[ 2 , 7 ] = 0.5; h[9]=1e-3\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p2.String(), "h[2]=0.125\nJ[2,7]=1.5\nh[3]=-0.5\nh[9]=0.001\n"; got != want {
+		t.Fatalf("Expected %q but saw %q", want, got)
+	}
+	for _, bad := range []string{"h[1,2]=1", "J[3]=1", "J[4,4]=1", "x[1]=2", "h[1]=abc", "h[99999999999999999999]=1"} {
+		if _, err := sapi.ParseProblem(bad); err == nil {
+			t.Fatalf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+// TestMarshalSolverParameters ensures that each type of SolverParameters
+// survives a round trip through its JSON representation.
+func TestMarshalSolverParameters(t *testing.T) {
+	for _, sp := range []sapi.SolverParameters{
+		&sapi.SwOptimizeSolverParameters{NumReads: 10, MaxAnswers: 5},
+		&sapi.SwSampleSolverParameters{Beta: 2.5, UseRandomSeed: true, RandomSeed: 42},
+		&sapi.SwHeuristicSolverParameters{IterationLimit: 7, TimeLimitSeconds: 1.5},
+		&sapi.QuantumSolverParameters{
+			NumReads:       100,
+			AutoScale:      true,
+			Postprocess:    sapi.PostprocessSampling,
+			AnnealSchedule: []sapi.SchedulePoint{{Time: 0, S: 0}, {Time: 20, S: 1}},
+		},
+	} {
+		data, err := sapi.MarshalSolverParameters(sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sp2, err := sapi.UnmarshalSolverParameters(data)
 		if err != nil {
-			return ir, err
+			t.Fatal(err)
 		}
-		for i, e := range ir.Energies {
-			ir.Energies[i] = e + ofs
+		if !reflect.DeepEqual(sp, sp2) {
+			t.Fatalf("Expected %#v but saw %#v", sp, sp2)
 		}
-		return ir, nil
 	}
-	testAnd(t, true, solver, solveIsing)
+}
+
+// TestCloneSolverParameters ensures that modifying a clone of a set of
+// solver parameters leaves the original unmodified.
+func TestCloneSolverParameters(t *testing.T) {
+	qsp := &sapi.QuantumSolverParameters{
+		NumReads:      100,
+		AnnealOffsets: []float64{0.1, 0.2},
+	}
+	c := qsp.Clone().(*sapi.QuantumSolverParameters)
+	if !reflect.DeepEqual(qsp, c) {
+		t.Fatalf("Expected %#v but saw %#v", qsp, c)
+	}
+	c.NumReads = 200
+	c.AnnealOffsets[0] = 0.3
+	if qsp.NumReads != 100 || qsp.AnnealOffsets[0] != 0.1 {
+		t.Fatal("Expected modifying a clone to leave the original unmodified")
+	}
+}
+
+// TestParameterOptions ensures that NewParameters applies options that suit
+// the sampler's parameters and rejects options that do not.
+func TestParameterOptions(t *testing.T) {
+	var es sapi.ExactSolver
+	sp, err := sapi.NewParameters(es, sapi.WithNumReads(5), sapi.WithMaxAnswers(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sosp := sp.(*sapi.SwOptimizeSolverParameters)
+	if sosp.NumReads != 5 || sosp.MaxAnswers != 2 {
+		t.Fatalf("Expected 5 reads and 2 answers but saw %d and %d", sosp.NumReads, sosp.MaxAnswers)
+	}
+	if _, err = sapi.NewParameters(es, sapi.WithAutoScale(true)); err == nil {
+		t.Fatal("Expected auto_scale to be rejected for sw_optimize parameters")
+	}
+}
+
+// TestReadConfig ensures that ReadConfig parses connection parameters and
+// per-class parameter defaults.
+func TestReadConfig(t *testing.T) {
+	cfg, err := sapi.ReadConfig(strings.NewReader(`{
+		"solver": "c4-sw_optimize",
+		"timeout": "90s",
+		"defaults": {"num_reads": 100},
+		"class_defaults": {
+			"quantum": {"annealing_time": 50, "answer_mode": "raw", "auto_scale": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Solver != "c4-sw_optimize" || cfg.Timeout != 90*time.Second || cfg.Defaults.NumReads != 100 {
+		t.Fatalf("Unexpected configuration %+v", cfg)
+	}
+	qd := cfg.ClassDefaults["quantum"]
+	if qd.AnnealingTime != 50 || qd.AnswerMode == nil || *qd.AnswerMode != sapi.AnswerModeRaw || qd.AutoScale == nil || !*qd.AutoScale {
+		t.Fatalf("Unexpected quantum defaults %+v", qd)
+	}
+	if _, err = sapi.ReadConfig(strings.NewReader(`{"class_defaults": {"bogus": {}}}`)); err == nil {
+		t.Fatal("Expected an unrecognized solver class to be rejected")
+	}
+}
+
+// TestArchive ensures that an Archive survives a round trip through its JSON
+// representation.
+func TestArchive(t *testing.T) {
+	// Write an archive to a buffer.
+	orig := &sapi.Archive{
+		Solver:      "c4-sw_optimize",
+		ProblemType: "ising",
+		Problem: sapi.Problem{
+			sapi.ProblemEntry{I: 0, J: 0, Value: 0.5},
+			sapi.ProblemEntry{I: 0, J: 1, Value: -1},
+		},
+		Parameters: &sapi.QuantumSolverParameters{NumReads: 123},
+		Embedding:  sapi.Embeddings{0, 1, -1},
+		Result: sapi.IsingResult{
+			Solutions: [][]int8{{-1, -1}},
+			Energies:  []float64{-1.5},
+		},
+	}
+	var buf bytes.Buffer
+	if err := orig.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the archive back and compare it to the original.
+	a, err := sapi.ReadArchive(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a.Problem, orig.Problem) {
+		t.Fatalf("Expected problem %v but saw %v", orig.Problem, a.Problem)
+	}
+	if !reflect.DeepEqual(a.Embedding, orig.Embedding) {
+		t.Fatalf("Expected embedding %v but saw %v", orig.Embedding, a.Embedding)
+	}
+	if !reflect.DeepEqual(a.Result, orig.Result) {
+		t.Fatalf("Expected result %v but saw %v", orig.Result, a.Result)
+	}
+	qsp, ok := a.Parameters.(*sapi.QuantumSolverParameters)
+	if !ok {
+		t.Fatalf("Expected *sapi.QuantumSolverParameters but saw %T", a.Parameters)
+	}
+	if qsp.NumReads != 123 {
+		t.Fatalf("Expected NumReads = 123 but saw %d", qsp.NumReads)
+	}
+
+	// Ensure that parameters of an unrecognized type are rejected.
+	orig.Parameters = struct{ *sapi.QuantumSolverParameters }{qsp}
+	if err := orig.Write(&bytes.Buffer{}); err == nil {
+		t.Fatal("Expected unrecognized parameters to be rejected")
+	}
+}
+
+// TestSolverPropertiesJSON ensures that SolverProperties survive a round trip
+// through JSON and that the JSON representation is canonically ordered.
+func TestSolverPropertiesJSON(t *testing.T) {
+	// Marshal a set of solver properties.
+	orig := &sapi.SolverProperties{
+		SupportedProblemTypes: []string{"qubo", "ising"},
+		IsingRanges:           &sapi.IsingRangeProperties{HMin: -2, HMax: 2, JMin: -1, JMax: 1},
+		QuantumProps: &sapi.QuantumSolverProperties{
+			NumQubits: 8,
+			Qubits:    []int{4, 0, 5},
+			Couplers:  [][2]int{{5, 0}, {0, 4}},
+		},
+		SupportedPostprocessing: []sapi.Postprocessing{sapi.PostprocessNone, sapi.PostprocessSampling},
+		Parameters:              []string{"num_reads", "answer_mode"},
+	}
+	data, err := json.Marshal(orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unmarshal the properties and compare them to the canonicalized
+	// original.
+	var sp sapi.SolverProperties
+	if err = json.Unmarshal(data, &sp); err != nil {
+		t.Fatal(err)
+	}
+	expected := *orig
+	expected.SupportedProblemTypes = []string{"ising", "qubo"}
+	expected.QuantumProps = &sapi.QuantumSolverProperties{
+		NumQubits: 8,
+		Qubits:    []int{0, 4, 5},
+		Couplers:  [][2]int{{0, 4}, {0, 5}},
+	}
+	expected.Parameters = []string{"answer_mode", "num_reads"}
+	if !reflect.DeepEqual(sp, expected) {
+		t.Fatalf("Expected %+v but saw %+v (JSON = %s)", expected, sp, data)
+	}
+}
+
+// TestFixingSensitivities ensures that FixingSensitivities omits variables
+// that roof duality can fix and reports margins for those it can't.
+func TestFixingSensitivities(t *testing.T) {
+	// Variable 4 can be fixed (see TestFixVariables), but the others
+	// can't.
+	prob := sapi.Problem{
+		sapi.ProblemEntry{I: 1, J: 1, Value: 1},
+		sapi.ProblemEntry{I: 2, J: 2, Value: 1},
+		sapi.ProblemEntry{I: 3, J: 3, Value: 1},
+		sapi.ProblemEntry{I: 4, J: 4, Value: 3},
+		sapi.ProblemEntry{I: 1, J: 2, Value: 1},
+		sapi.ProblemEntry{I: 1, J: 3, Value: -2},
+		sapi.ProblemEntry{I: 2, J: 3, Value: -2},
+		sapi.ProblemEntry{I: 1, J: 4, Value: 4},
+	}
+	sens := prob.FixingSensitivities()
+	for _, s := range sens {
+		if s.Var == 4 {
+			t.Fatal("Expected variable 4 to be fixed by roof duality")
+		}
+		if s.Margin0 > 0 || s.Margin1 > 0 {
+			t.Fatalf("Unfixed variable %d has a positive margin: %+v", s.Var, s)
+		}
+	}
+
+	// In x1 x2 - x1 - x2, both variables lie exactly on the boundary of
+	// fixability.
+	prob = sapi.Problem{
+		sapi.ProblemEntry{I: 1, J: 1, Value: -1},
+		sapi.ProblemEntry{I: 2, J: 2, Value: -1},
+		sapi.ProblemEntry{I: 1, J: 2, Value: 1},
+	}
+	sens = prob.FixingSensitivities()
+	if len(sens) != 2 {
+		t.Fatalf("Expected two unfixed variables but saw %v", sens)
+	}
+	for _, s := range sens {
+		if math.Abs(s.Margin0) > 1e-9 || math.Abs(s.Margin1) > 1e-9 {
+			t.Fatalf("Expected zero margins but saw %+v", s)
+		}
+	}
+}
+
+// TestEmbeddingMetrics ensures that we correctly summarize an embedding.
+func TestEmbeddingMetrics(t *testing.T) {
+	emb := sapi.Embeddings{0, 0, 1, -1, 2, 2, 2, -1}
+	m := emb.Metrics()
+	expected := sapi.EmbeddingMetrics{
+		NumVariables:    3,
+		NumQubits:       6,
+		MaxChainLength:  3,
+		MeanChainLength: 2,
+	}
+	if m != expected {
+		t.Fatalf("Expected %+v but saw %+v", expected, m)
+	}
+}
+
+// TestChainPaths ensures that ChainPaths reports the correct coordinates
+// and intra-chain couplers for a small embedding.
+func TestChainPaths(t *testing.T) {
+	// Embed variable 0 in qubits 0 and 4 and variable 1 in qubit 12 of a
+	// 1×2×4 Chimera graph.
+	emb := make(sapi.Embeddings, 16)
+	for q := range emb {
+		emb[q] = -1
+	}
+	emb[0], emb[4], emb[12] = 0, 0, 1
+	adj := sapi.Problem{
+		{I: 0, J: 4, Value: 1},
+		{I: 4, J: 0, Value: 1},
+		{I: 4, J: 12, Value: 1},
+	}
+	ts := sapi.Chimera(1, 2, 4)
+	paths, err := emb.ChainPaths(adj, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("Expected 2 paths but saw %d", len(paths))
+	}
+	if !reflect.DeepEqual(paths[0].Edges, [][2]int{{0, 1}}) {
+		t.Fatalf("Expected a single edge in chain 0 but saw %v", paths[0].Edges)
+	}
+	expected := sapi.ChimeraCoord{Row: 0, Col: 1, Side: 1, Index: 0}
+	if paths[1].Coords[0] != expected {
+		t.Fatalf("Expected qubit 12 at %+v but saw %+v", expected, paths[1].Coords[0])
+	}
+	if q := ts.Qubit(expected); q != 12 {
+		t.Fatalf("Expected %+v to map back to qubit 12 but saw %d", expected, q)
+	}
+}
+
+// TestTimingModel ensures that a TimingModel produces the expected estimate.
+func TestTimingModel(t *testing.T) {
+	tm := sapi.TimingModelFromResult(sapi.Timing{
+		QpuProgrammingTime:      10 * time.Millisecond,
+		QpuReadoutTimePerSample: 100 * time.Microsecond,
+		QpuDelayTimePerSample:   20 * time.Microsecond,
+	})
+	qsp := &sapi.QuantumSolverParameters{
+		AnnealingTime:    20,
+		NumReads:         100,
+		NumSpinReversals: 2,
+		ProgTherm:        1000,
+	}
+	expected := 2*11*time.Millisecond + 100*140*time.Microsecond
+	if est := tm.Estimate(qsp); est != expected {
+		t.Fatalf("Expected an estimate of %v but saw %v", expected, est)
+	}
+
+	// An anneal schedule overrides the annealing time.
+	qsp.AnnealSchedule = []sapi.SchedulePoint{{Time: 0, S: 0}, {Time: 50, S: 1}}
+	expected += 100 * 30 * time.Microsecond
+	if est := tm.Estimate(qsp); est != expected {
+		t.Fatalf("Expected an estimate of %v but saw %v", expected, est)
+	}
+}
+
+// TestSolverLimits ensures that solver limits are derived correctly from
+// solver properties.
+func TestSolverLimits(t *testing.T) {
+	props := &sapi.SolverProperties{
+		QuantumProps: &sapi.QuantumSolverProperties{
+			NumQubits: 8,
+			Qubits:    []int{0, 1, 2, 4, 5},
+			Couplers:  [][2]int{{0, 4}, {0, 5}, {1, 4}},
+		},
+	}
+	lim := props.Limits()
+	expected := sapi.SolverLimits{MaxVariables: 5, MaxCouplers: 3}
+	if lim != expected {
+		t.Fatalf("Expected limits %+v but saw %+v", expected, lim)
+	}
+
+	// The reads limit comes from the advertised range of reads, if any.
+	props.NumReadsRange = &[2]int{1, 500}
+	expected.MaxReads = 500
+	if lim = props.Limits(); lim != expected {
+		t.Fatalf("Expected limits %+v but saw %+v", expected, lim)
+	}
+}
+
+// biasedSampler is a Sampler that solves problems containing only h values
+// by sampling each qubit independently from a Boltzmann distribution with a
+// fixed bias and inverse temperature.  It exists to test Characterize.
+type biasedSampler struct {
+	bias, beta float64
+}
+
+// SolveIsing samples each qubit in a problem independently.
+func (bs biasedSampler) SolveIsing(p sapi.Problem, sp sapi.SolverParameters) (sapi.IsingResult, error) {
+	const n = 10000
+	res := sapi.IsingResult{
+		Solutions: make([][]int8, n),
+		Energies:  make([]float64, n),
+	}
+	for i := range res.Solutions {
+		res.Solutions[i] = make([]int8, len(p))
+	}
+	for _, pe := range p {
+		m := -math.Tanh(bs.beta * (pe.Value + bs.bias))
+		nUp := int(math.Round(n * (1 + m) / 2))
+		for i := range res.Solutions {
+			res.Solutions[i][pe.I] = -1
+			if i < nUp {
+				res.Solutions[i][pe.I] = 1
+			}
+		}
+	}
+	return res, nil
+}
+
+// SolveQubo treats a QUBO problem as though it were an Ising-model problem.
+func (bs biasedSampler) SolveQubo(p sapi.Problem, sp sapi.SolverParameters) (sapi.IsingResult, error) {
+	return bs.SolveIsing(p, sp)
+}
+
+// NewSolverParameters returns an empty set of parameters.
+func (bs biasedSampler) NewSolverParameters() sapi.SolverParameters {
+	return &sapi.SwOptimizeSolverParameters{}
+}
+
+// Properties returns an empty set of properties.
+func (bs biasedSampler) Properties() *sapi.SolverProperties {
+	return &sapi.SolverProperties{}
+}
+
+// TestCharacterize ensures that Characterize recovers a known bias and
+// inverse temperature.
+func TestCharacterize(t *testing.T) {
+	bs := biasedSampler{bias: 0.05, beta: 2.0}
+	cp, err := sapi.Characterize(bs, []int{0, 1, 2}, 0.2, bs.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for q := 0; q < 3; q++ {
+		if math.Abs(cp.Bias[q]-bs.bias) > 0.01 || math.Abs(cp.Beta[q]-bs.beta) > 0.1 {
+			t.Fatalf("Expected bias %v and beta %v for qubit %d but saw %v and %v",
+				bs.bias, bs.beta, q, cp.Bias[q], cp.Beta[q])
+		}
+	}
+}
+
+// TestReweightBoltzmann ensures that reweighting samples to a new inverse
+// temperature produces correctly normalized Boltzmann weights.
+func TestReweightBoltzmann(t *testing.T) {
+	ir := sapi.IsingResult{
+		Solutions:   [][]int8{{-1}, {+1}},
+		Energies:    []float64{-1, 1},
+		Occurrences: []int{3, 1},
+	}
+	w := ir.ReweightBoltzmann(0, math.Log(3)/2)
+	expected := []float64{0.9, 0.1} // 3·√3 : 1/√3
+	for i, e := range expected {
+		if math.Abs(w[i]-e) > 1e-9 {
+			t.Fatalf("Expected weights %v but saw %v", expected, w)
+		}
+	}
+	if ess := sapi.EffectiveSampleSize([]float64{0.5, 0.5}); math.Abs(ess-2) > 1e-9 {
+		t.Fatalf("Expected an effective sample size of 2 but saw %v", ess)
+	}
+}
+
+// TestProblemBuilder ensures that a ProblemBuilder merges duplicate terms and
+// produces a canonical problem.
+func TestProblemBuilder(t *testing.T) {
+	// Accumulate terms, some of them repeatedly and in either order.
+	pb := sapi.NewProblemBuilder()
+	pb.AddQuadratic(2, 0, 0.5)
+	pb.AddLinear(1, -1.0)
+	pb.AddQuadratic(0, 2, 0.25)
+	pb.AddLinear(1, 0.5)
+	pb.AddQuadratic(1, 1, 0.25)
+	pb.SetOffset(3.0)
+	if pb.Len() != 2 {
+		t.Fatalf("Expected 2 distinct terms but saw %d", pb.Len())
+	}
+
+	// Verify the result.
+	exp := sapi.Problem{
+		{I: 0, J: 2, Value: 0.75},
+		{I: 1, J: 1, Value: -0.25},
+	}
+	prob := pb.Build()
+	if !reflect.DeepEqual(prob, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, prob)
+	}
+	if pb.Offset() != 3.0 {
+		t.Fatalf("Expected an offset of 3 but saw %v", pb.Offset())
+	}
+
+	// Ensure that further additions do not affect the built problem.
+	pb.AddLinear(1, 1.0)
+	if prob[1].Value != -0.25 {
+		t.Fatalf("Build returned a problem that shares storage with the builder")
+	}
+}
+
+// TestLabeledProblem ensures that solutions to a LabeledProblem can be
+// retrieved by label.
+func TestLabeledProblem(t *testing.T) {
+	// Define a ferromagnetic chain in which one end is biased towards -1.
+	lp := sapi.LabeledProblem{
+		{U: "left", V: "middle", Value: -1.0},
+		{U: "middle", V: "right", Value: -1.0},
+		{U: "right", V: "right", Value: 0.5},
+	}
+	p, labels := lp.Index()
+	if len(p) != 3 || !reflect.DeepEqual(labels, []string{"left", "middle", "right"}) {
+		t.Fatalf("Unexpected indexing %v of labels %v", p, labels)
+	}
+
+	// Solve the problem and verify the ground state.
+	var es sapi.ExactSolver
+	res, err := sapi.SolveLabeledIsing(es, lp, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]int8{"left": -1, "middle": -1, "right": -1}
+	if s := res.Sample(0); !reflect.DeepEqual(s, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, s)
+	}
+}
+
+// TestProblemMaps ensures that a problem survives a round trip through its
+// map representation.
+func TestProblemMaps(t *testing.T) {
+	h := map[int]float64{0: 0.5, 2: -1.0}
+	J := map[[2]int]float64{{0, 1}: 1.0, {2, 1}: -0.5, {1, 2}: -0.25}
+	prob := sapi.NewProblemFromMaps(h, J)
+	exp := sapi.Problem{
+		{I: 0, J: 0, Value: 0.5},
+		{I: 0, J: 1, Value: 1.0},
+		{I: 1, J: 2, Value: -0.75},
+		{I: 2, J: 2, Value: -1.0},
+	}
+	if !reflect.DeepEqual(prob, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, prob)
+	}
+	h2, J2 := prob.ToMaps()
+	if !reflect.DeepEqual(h2, h) {
+		t.Fatalf("Expected %v but saw %v", h, h2)
+	}
+	expJ := map[[2]int]float64{{0, 1}: 1.0, {1, 2}: -0.75}
+	if !reflect.DeepEqual(J2, expJ) {
+		t.Fatalf("Expected %v but saw %v", expJ, J2)
+	}
+}
+
+// TestProblemDense ensures that a problem survives a round trip through its
+// dense-matrix representation.
+func TestProblemDense(t *testing.T) {
+	// Convert a matrix with one below-diagonal element to a Problem.
+	Q := [][]float64{
+		{1.0, 2.0, 0.0},
+		{0.5, -1.0, 3.0},
+		{0.0, 0.0, 0.0},
+	}
+	prob, err := sapi.NewProblemFromDense(Q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := sapi.Problem{
+		{I: 0, J: 0, Value: 1.0},
+		{I: 0, J: 1, Value: 2.5},
+		{I: 1, J: 1, Value: -1.0},
+		{I: 1, J: 2, Value: 3.0},
+	}
+	if !reflect.DeepEqual(prob, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, prob)
+	}
+
+	// Convert the Problem back to an upper-triangular matrix.
+	Q2, err := prob.ToDense(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expQ := [][]float64{
+		{1.0, 2.5, 0.0},
+		{0.0, -1.0, 3.0},
+		{0.0, 0.0, 0.0},
+	}
+	if !reflect.DeepEqual(Q2, expQ) {
+		t.Fatalf("Expected %v but saw %v", expQ, Q2)
+	}
+
+	// Ensure that invalid sizes are rejected.
+	if _, err := prob.ToDense(2); err == nil {
+		t.Fatal("ToDense failed to reject a too-small matrix")
+	}
+	if _, err := sapi.NewProblemFromDense([][]float64{{1.0, 2.0}}); err == nil {
+		t.Fatal("NewProblemFromDense failed to reject a non-square matrix")
+	}
+}
+
+// TestProblemEnergy ensures that energies computed locally match those
+// reported by a solver.
+func TestProblemEnergy(t *testing.T) {
+	var es sapi.ExactSolver
+	prob := xorProblem()
+	res, err := es.SolveIsing(prob, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, soln := range res.Solutions {
+		if e := prob.IsingEnergy(soln); e != res.Energies[i] {
+			t.Fatalf("Expected an Ising energy of %v for %v but saw %v", res.Energies[i], soln, e)
+		}
+	}
+	res, err = es.SolveQubo(prob, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, soln := range res.Solutions {
+		if e := prob.QuboEnergy(soln); e != res.Energies[i] {
+			t.Fatalf("Expected a QUBO energy of %v for %v but saw %v", res.Energies[i], soln, e)
+		}
+	}
+}
+
+// TestProblemEnergies ensures that batch energy evaluation agrees with
+// evaluation of individual solutions.
+func TestProblemEnergies(t *testing.T) {
+	// Generate enough random solutions to exercise parallel evaluation.
+	prob := append(xorProblem(), sapi.ProblemEntry{I: 3, J: 0, Value: 0.25})
+	rng := rand.New(rand.NewSource(4072))
+	solns := make([][]int8, 1000)
+	for i := range solns {
+		solns[i] = make([]int8, 4)
+		for v := range solns[i] {
+			solns[i][v] = int8(2*rng.Intn(2) - 1)
+		}
+	}
+
+	// Compare the two means of computing energies.
+	es := prob.Energies(solns)
+	if len(es) != len(solns) {
+		t.Fatalf("Expected %d energies but saw %d", len(solns), len(es))
+	}
+	for i, soln := range solns {
+		if e := prob.IsingEnergy(soln); math.Abs(e-es[i]) > 1e-9 {
+			t.Fatalf("Expected an energy of %v for %v but saw %v", e, soln, es[i])
+		}
+	}
+}
+
+// TestScaleProblem ensures that ScaleToRanges and Normalize compute the
+// expected scale factors.
+func TestScaleProblem(t *testing.T) {
+	// Scale a problem whose limiting coefficient is a negative coupler.
+	prob := sapi.Problem{
+		{I: 0, J: 0, Value: 0.5},
+		{I: 0, J: 1, Value: -0.25},
+		{I: 1, J: 0, Value: -0.25},
+	}
+	ranges := sapi.IsingRangeProperties{HMin: -2, HMax: 2, JMin: -1, JMax: 1}
+	sp, f := prob.ScaleToRanges(ranges)
+	if f != 2 || sp[0].Value != 1 || sp[1].Value != -0.5 {
+		t.Fatalf("Unexpected scaling %v by factor %v", sp, f)
+	}
+	if prob[0].Value != 0.5 {
+		t.Fatal("ScaleToRanges modified its input")
+	}
+
+	// Normalize the same problem.
+	np, f := prob.Normalize()
+	if f != 2 || np[0].Value != 1 || np[2].Value != -0.5 {
+		t.Fatalf("Unexpected normalization %v by factor %v", np, f)
+	}
+}
+
+// TestCompactProblem ensures that a problem's variables can be renumbered
+// densely and then restored.
+func TestCompactProblem(t *testing.T) {
+	// Compact a problem with sparse variable numbers.
+	prob := sapi.Problem{
+		{I: 10, J: 10, Value: 1.0},
+		{I: 10, J: 42, Value: -1.0},
+		{I: 7, J: 42, Value: 0.5},
+	}
+	cp, mapping := prob.Compact()
+	exp := sapi.Problem{
+		{I: 1, J: 1, Value: 1.0},
+		{I: 1, J: 2, Value: -1.0},
+		{I: 0, J: 2, Value: 0.5},
+	}
+	if !reflect.DeepEqual(cp, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, cp)
+	}
+
+	// Restore the original numbering.
+	inv := make(map[int]int, len(mapping))
+	for o, n := range mapping {
+		inv[n] = o
+	}
+	if rp := cp.Relabel(inv); !reflect.DeepEqual(rp, prob) {
+		t.Fatalf("Expected %v but saw %v", prob, rp)
+	}
+}
+
+// TestProblemValidFor ensures that ValidFor reports every missing qubit and
+// coupler.
+func TestProblemValidFor(t *testing.T) {
+	// Define a three-qubit path graph.
+	adj := sapi.Problem{{I: 0, J: 1}, {I: 1, J: 2}}
+	good := sapi.Problem{{I: 0, J: 0, Value: 1}, {I: 2, J: 1, Value: -1}}
+	if err := good.ValidFor(adj); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure that all errors in a bad problem are reported.
+	bad := sapi.Problem{
+		{I: 0, J: 2, Value: 1},  // Missing coupler
+		{I: 3, J: 3, Value: 1},  // Missing qubit
+		{I: 3, J: 1, Value: -1}, // Missing qubit (already reported) and coupler
+	}
+	err := bad.ValidFor(adj)
+	ve, ok := err.(sapi.ValidationError)
+	if !ok {
+		t.Fatalf("Expected a ValidationError but saw %v", err)
+	}
+	if len(ve) != 3 {
+		t.Fatalf("Expected 3 errors but saw %d:\n%v", len(ve), ve)
+	}
+}
+
+// TestProblemGraph ensures that graph queries on a problem return the
+// expected neighbors and components.
+func TestProblemGraph(t *testing.T) {
+	// Define a triangle, a separate edge, and an isolated variable.
+	prob := sapi.Problem{
+		{I: 0, J: 1, Value: 1},
+		{I: 2, J: 1, Value: 1},
+		{I: 0, J: 2},
+		{I: 1, J: 0, Value: -1}, // Duplicate edge
+		{I: 5, J: 6, Value: 1},
+		{I: 9, J: 9, Value: 1},
+	}
+	if ns := prob.Neighbors(1); !reflect.DeepEqual(ns, []int{0, 2}) {
+		t.Fatalf("Expected neighbors [0 2] but saw %v", ns)
+	}
+	if d := prob.Degree(9); d != 0 {
+		t.Fatalf("Expected degree 0 but saw %d", d)
+	}
+	exp := [][]int{{0, 1, 2}, {5, 6}, {9}}
+	if cc := prob.ConnectedComponents(); !reflect.DeepEqual(cc, exp) {
+		t.Fatalf("Expected components %v but saw %v", exp, cc)
+	}
+}
+
+// TestMarshalProblem ensures that a problem survives a round trip through
+// its JSON representation and that the encoding is canonical.
+func TestMarshalProblem(t *testing.T) {
+	// Encode a non-canonical problem.
+	doc := &sapi.ProblemDocument{
+		Type: "qubo",
+		Problem: sapi.Problem{
+			{I: 1, J: 0, Value: 0.5},
+			{I: 0, J: 0, Value: -1.25},
+			{I: 0, J: 1, Value: 0.5},
+		},
+		Offset:   2,
+		Metadata: map[string]string{"source": "test"},
+	}
+	data, err := sapi.MarshalProblem(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"format":"sapi-problem/1","type":"qubo","entries":[[0,0,-1.25],[0,1,1]],"offset":2,"metadata":{"source":"test"}}`
+	if string(data) != exp {
+		t.Fatalf("Expected %s but saw %s", exp, data)
+	}
+
+	// Decode the problem.
+	doc2, err := sapi.UnmarshalProblem(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Problem = doc.Problem.Canonicalize()
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Fatalf("Expected %v but saw %v", doc, doc2)
+	}
+
+	// Ensure that malformed entries are rejected.
+	bad := `{"format":"sapi-problem/1","type":"ising","entries":[[0,1.5,1]]}`
+	if _, err := sapi.UnmarshalProblem([]byte(bad)); err == nil {
+		t.Fatal("UnmarshalProblem failed to reject a non-integral index")
+	}
+}
+
+// TestCOO ensures that problems and adjacencies can be read and written in
+// coordinate format.
+func TestCOO(t *testing.T) {
+	// Read a problem with comments and an adjacency-style entry.
+	txt := `% A small problem
+0 0 -0.5
+0 1 1.25   # Coupler
+1 2
+`
+	prob, err := sapi.ReadCOO(strings.NewReader(txt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := sapi.Problem{
+		{I: 0, J: 0, Value: -0.5},
+		{I: 0, J: 1, Value: 1.25},
+		{I: 1, J: 2, Value: 0},
+	}
+	if !reflect.DeepEqual(prob, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, prob)
+	}
+
+	// Write the problem and an adjacency.
+	var buf bytes.Buffer
+	if err := sapi.WriteCOO(&buf, prob); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != "0 0 -0.5\n0 1 1.25\n1 2 0\n" {
+		t.Fatalf("Unexpected COO output %q", s)
+	}
+	buf.Reset()
+	if err := sapi.WriteAdjacencyCOO(&buf, prob[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != "0 1\n1 2\n" {
+		t.Fatalf("Unexpected adjacency output %q", s)
+	}
+
+	// Ensure that malformed lines are rejected.
+	if _, err := sapi.ReadCOO(strings.NewReader("0 1 2 3\n")); err == nil {
+		t.Fatal("ReadCOO failed to reject a four-field line")
+	}
+}
+
+// TestProblemArithmetic ensures that problems can be added, subtracted, and
+// scaled.
+func TestProblemArithmetic(t *testing.T) {
+	obj := sapi.Problem{{I: 0, J: 0, Value: 1}, {I: 0, J: 1, Value: -1}}
+	cons := sapi.Problem{{I: 1, J: 0, Value: 0.5}, {I: 1, J: 1, Value: 2}}
+	exp := sapi.Problem{
+		{I: 0, J: 0, Value: 1},
+		{I: 0, J: 1, Value: 0.5},
+		{I: 1, J: 1, Value: 6},
+	}
+	if p := obj.Add(cons.Scale(3)); !reflect.DeepEqual(p, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, p)
+	}
+	exp = sapi.Problem{
+		{I: 0, J: 0, Value: 1},
+		{I: 0, J: 1, Value: -1.5},
+		{I: 1, J: 1, Value: -2},
+	}
+	if p := obj.Sub(cons); !reflect.DeepEqual(p, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, p)
+	}
+}
+
+// TestGaugeTransform ensures that solutions of a gauge-transformed problem
+// map back to solutions of the original problem with the same energy.
+func TestGaugeTransform(t *testing.T) {
+	// Solve a gauge-transformed problem.
+	prob := xorProblem()
+	flips := []bool{true, false, true, false}
+	gp := prob.GaugeTransform(flips)
+	var es sapi.ExactSolver
+	res, err := es.SolveIsing(gp, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Map the solutions back and compare energies.
+	sapi.ApplyGaugeToSolutions(res.Solutions, flips)
+	for i, soln := range res.Solutions {
+		if e := prob.IsingEnergy(soln); math.Abs(e-res.Energies[i]) > 1e-9 {
+			t.Fatalf("Expected an energy of %v for %v but saw %v", res.Energies[i], soln, e)
+		}
+	}
+}
+
+// TestGenerateRANk ensures that GenerateRANk assigns an integral coupling in
+// the expected range to every coupler.
+func TestGenerateRANk(t *testing.T) {
+	adj := sapi.Problem{{I: 0, J: 1}, {I: 1, J: 2}, {I: 2, J: 0}, {I: 1, J: 0}}
+	prob := sapi.GenerateRANk(adj, 3, rand.New(rand.NewSource(4083)))
+	if len(prob) != 3 {
+		t.Fatalf("Expected 3 couplers but saw %d", len(prob))
+	}
+	for _, pe := range prob {
+		v := math.Abs(pe.Value)
+		if pe.I == pe.J || v < 1 || v > 3 || v != math.Trunc(v) {
+			t.Fatalf("Invalid RAN-3 entry %v", pe)
+		}
+	}
+}
+
+// TestGenerateFrustratedLoops ensures that the planted solution of a
+// frustrated-loop problem is a ground state.
+func TestGenerateFrustratedLoops(t *testing.T) {
+	// Generate a problem on a 3×3 grid.
+	var adj sapi.Problem
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			v := 3*r + c
+			if c < 2 {
+				adj = append(adj, sapi.ProblemEntry{I: v, J: v + 1})
+			}
+			if r < 2 {
+				adj = append(adj, sapi.ProblemEntry{I: v, J: v + 3})
+			}
+		}
+	}
+	prob, planted, err := sapi.GenerateFrustratedLoops(adj, 5, 3, rand.New(rand.NewSource(4084)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prob) == 0 || len(planted) != 9 {
+		t.Fatalf("Unexpected problem %v with planted solution %v", prob, planted)
+	}
+
+	// Compare the planted energy to the true ground-state energy.
+	var es sapi.ExactSolver
+	res, err := es.SolveIsing(prob, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := prob.IsingEnergy(planted); math.Abs(e-res.Energies[0]) > 1e-9 {
+		t.Fatalf("Planted energy %v differs from ground-state energy %v", e, res.Energies[0])
+	}
+}
+
+// TestSATToIsing ensures that SAT instances convert to Ising-model problems
+// whose energies count violated clauses.
+func TestSATToIsing(t *testing.T) {
+	// Verify a 2-SAT instance's energies against direct evaluation.
+	rng := rand.New(rand.NewSource(4085))
+	clauses := sapi.GenerateSAT(6, 10, 2, rng)
+	prob, ofs, err := sapi.TwoSATToIsing(clauses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var es sapi.ExactSolver
+	res, err := es.SolveIsing(prob, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, soln := range res.Solutions {
+		unsat := 0
+		for _, cl := range clauses {
+			sat := false
+			for _, lit := range cl {
+				v := lit
+				if v < 0 {
+					v = -v
+				}
+				if (lit > 0) == (soln[v-1] == 1) {
+					sat = true
+				}
+			}
+			if !sat {
+				unsat++
+			}
+		}
+		if e := res.Energies[i] + ofs; math.Abs(e-float64(unsat)) > 1e-9 {
+			t.Fatalf("Solution %v leaves %d clauses unsatisfied but has energy %v", soln, unsat, e)
+		}
+	}
+
+	// Ensure that a satisfiable NAE-3SAT instance reaches the expected
+	// ground-state energy.
+	nae := [][]int{{1, 2, 3}, {-1, 2, 4}, {1, -3, -4}}
+	prob, err = sapi.NAE3SATToIsing(nae)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = es.SolveIsing(prob, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Energies[0] != -3 {
+		t.Fatalf("Expected a ground-state energy of -3 but saw %v", res.Energies[0])
+	}
+}
+
+// TestOffsetProblem ensures that an OffsetProblem reports energies in the
+// frame of the original problem across conversions.
+func TestOffsetProblem(t *testing.T) {
+	// Solve a QUBO problem with an offset directly.
+	var pb sapi.ProblemBuilder
+	pb.AddLinear(0, 1.5)
+	pb.AddLinear(1, -2.0)
+	pb.AddQuadratic(0, 1, 0.5)
+	pb.SetOffset(10)
+	op := pb.BuildOffsetProblem()
+	var es sapi.ExactSolver
+	want, err := op.SolveQubo(es, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Energies[0] != 8 {
+		t.Fatalf("Expected a ground-state energy of 8 but saw %v", want.Energies[0])
+	}
+
+	// Solve the same problem via its Ising-model equivalent.
+	iop := op.ToIsing()
+	got, err := iop.SolveIsing(es, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range want.Energies {
+		if math.Abs(got.Energies[i]-e) > 1e-9 {
+			t.Fatalf("Expected energy %d to be %v but saw %v", i, e, got.Energies[i])
+		}
+		if ie := iop.IsingEnergy(got.Solutions[i]); math.Abs(ie-e) > 1e-9 {
+			t.Fatalf("Expected IsingEnergy to return %v but saw %v", e, ie)
+		}
+	}
+}
+
+// TestConversion ensures that a Conversion maps solutions and energies of a
+// converted problem back to those of the original problem, even for
+// variables that have no linear term.
+func TestConversion(t *testing.T) {
+	// Convert a QUBO problem in which variable 2 has no linear term.
+	qp := sapi.Problem{
+		{I: 0, J: 0, Value: 1.5},
+		{I: 1, J: 1, Value: -2.0},
+		{I: 0, J: 1, Value: 0.5},
+		{I: 1, J: 2, Value: -1.0},
+	}
+	conv := qp.ConvertToIsing()
+
+	// Solve the Ising-model problem and map the results back.
+	var es sapi.ExactSolver
+	ir, err := es.SolveIsing(conv.Problem, es.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := conv.ResultToOriginal(ir)
+	for i, soln := range res.Solutions {
+		if e := qp.QuboEnergy(soln); math.Abs(e-res.Energies[i]) > 1e-9 {
+			t.Fatalf("Expected an energy of %v for %v but saw %v", e, soln, res.Energies[i])
+		}
+		if s := conv.SolutionToConverted(soln); !reflect.DeepEqual(s, ir.Solutions[i]) {
+			t.Fatalf("Expected %v but saw %v", ir.Solutions[i], s)
+		}
+		if e := conv.EnergyToConverted(res.Energies[i]); math.Abs(e-ir.Energies[i]) > 1e-9 {
+			t.Fatalf("Expected an energy of %v but saw %v", ir.Energies[i], e)
+		}
+	}
+}
+
+// TestCanonicalizeInPlace ensures that in-place canonicalization matches
+// Canonicalize and can drop zero-valued entries.
+func TestCanonicalizeInPlace(t *testing.T) {
+	orig := sapi.Problem{
+		{I: 3, J: 1, Value: 1},
+		{I: 2, J: 2, Value: 0},
+		{I: 1, J: 3, Value: -1},
+		{I: 0, J: 4, Value: 2},
+		{I: 1, J: 1, Value: 0.5},
+	}
+	p := append(sapi.Problem(nil), orig...)
+	p.CanonicalizeInPlace(false)
+	if exp := orig.Canonicalize(); !reflect.DeepEqual(p, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, p)
+	}
+	p = append(sapi.Problem(nil), orig...)
+	p.CanonicalizeInPlace(true)
+	exp := sapi.Problem{{I: 0, J: 4, Value: 2}, {I: 1, J: 1, Value: 0.5}}
+	if !reflect.DeepEqual(p, exp) {
+		t.Fatalf("Expected %v but saw %v", exp, p)
+	}
+}
+
+// TestLowerBound ensures that the roof-duality lower bound never exceeds the
+// ground-state energy and is tight where expected.
+func TestLowerBound(t *testing.T) {
+	var es sapi.ExactSolver
+	sp := es.NewSolverParameters()
+
+	// The bound is tight for a QUBO problem with non-positive couplers.
+	qp := sapi.Problem{
+		{I: 0, J: 0, Value: 1},
+		{I: 1, J: 1, Value: -0.5},
+		{I: 2, J: 2, Value: 0.75},
+		{I: 0, J: 1, Value: -2},
+		{I: 1, J: 2, Value: -1},
+	}
+	res, err := es.SolveQubo(qp, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lb := qp.LowerBound(); math.Abs(lb-res.Energies[0]) > 1e-9 {
+		t.Fatalf("Expected a lower bound of %v but saw %v", res.Energies[0], lb)
+	}
+
+	// The bound is valid for a frustrated Ising-model problem.
+	ip := xorProblem()
+	res, err = es.SolveIsing(ip, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lb := ip.IsingLowerBound(); lb > res.Energies[0]+1e-9 {
+		t.Fatalf("Lower bound %v exceeds the ground-state energy %v", lb, res.Energies[0])
+	}
+}
+
+// TestSimplify ensures that solving a simplified problem and expanding the
+// result yields the original problem's ground state.
+func TestSimplify(t *testing.T) {
+	// Simplify the problem from TestFixVariables plus an isolated variable.
+	var pb sapi.ProblemBuilder
+	pb.AddLinear(1, 1)
+	pb.AddLinear(2, 1)
+	pb.AddLinear(3, 1)
+	pb.AddLinear(4, 3)
+	pb.AddQuadratic(1, 2, 1)
+	pb.AddQuadratic(1, 3, -2)
+	pb.AddQuadratic(2, 3, -2)
+	pb.AddQuadratic(1, 4, 4)
+	pb.AddLinear(6, -1)
+	prob := pb.Build()
+	simp, err := prob.Simplify(sapi.FixVariablesMethodOptimized)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Solve both problems and compare the ground states.
+	var es sapi.ExactSolver
+	sp := es.NewSolverParameters()
+	want, err := es.SolveQubo(prob, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := es.SolveQubo(simp.Problem, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = simp.ExpandResult(got)
+	if math.Abs(got.Energies[0]-want.Energies[0]) > 1e-9 {
+		t.Fatalf("Expected a ground-state energy of %v but saw %v", want.Energies[0], got.Energies[0])
+	}
+	if e := prob.QuboEnergy(got.Solutions[0]); math.Abs(e-want.Energies[0]) > 1e-9 {
+		t.Fatalf("Expanded solution %v has energy %v, not %v", got.Solutions[0], e, want.Energies[0])
+	}
+}
+
+// TestAutomorphisms ensures that the symmetries of a uniform ring are
+// detected and that a field breaks them as expected.
+func TestAutomorphisms(t *testing.T) {
+	// A uniform four-variable ring has the eight symmetries of a square.
+	var pb sapi.ProblemBuilder
+	for i := 0; i < 4; i++ {
+		pb.AddQuadratic(i, (i+1)%4, -1)
+	}
+	ring := pb.Build()
+	perms := ring.Automorphisms(0)
+	if len(perms) != 8 {
+		t.Fatalf("Expected 8 automorphisms but saw %d", len(perms))
+	}
+	for _, perm := range perms {
+		if !ring.IsAutomorphism(perm) {
+			t.Fatalf("%v is not an automorphism of %v", perm, ring)
+		}
+	}
+	if ring.IsAutomorphism(map[int]int{0: 1, 1: 0}) {
+		t.Fatal("Swapping adjacent variables was incorrectly reported as an automorphism")
+	}
+	if orbits := ring.SymmetryOrbits(0); len(orbits) != 1 {
+		t.Fatalf("Expected a single orbit but saw %v", orbits)
+	}
+	if !ring.HasFlipSymmetry() {
+		t.Fatal("Expected the ring to have flip symmetry")
+	}
+
+	// A field on variable 0 leaves only the reflection through 0 and 2.
+	pb.AddLinear(0, 0.5)
+	ring = pb.Build()
+	if perms = ring.Automorphisms(0); len(perms) != 2 {
+		t.Fatalf("Expected 2 automorphisms but saw %d", len(perms))
+	}
+	exp := [][]int{{0}, {1, 3}, {2}}
+	if orbits := ring.SymmetryOrbits(0); !reflect.DeepEqual(orbits, exp) {
+		t.Fatalf("Expected orbits %v but saw %v", exp, orbits)
+	}
+	if ring.HasFlipSymmetry() {
+		t.Fatal("Expected the ring not to have flip symmetry")
+	}
+}
+
+// TestComponentComposite ensures that a ComponentComposite lets an
+// ExactSolver solve a problem with more variables than it can handle at
+// once, provided that each component is small enough.
+func TestComponentComposite(t *testing.T) {
+	// Construct seven disjoint copies of the XOR problem.
+	var p sapi.Problem
+	const ncopies = 7
+	for c := 0; c < ncopies; c++ {
+		for _, pe := range xorProblem() {
+			p = append(p, sapi.ProblemEntry{I: pe.I + 4*c, J: pe.J + 4*c, Value: pe.Value})
+		}
+	}
+
+	// Solve all copies and verify each of them.
+	cc := &sapi.ComponentComposite{Sampler: sapi.ExactSolver{}}
+	res, err := cc.SolveIsing(p, cc.NewSolverParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for c := 0; c < ncopies; c++ {
+		solns := make([][]int8, len(res.Solutions))
+		for i, soln := range res.Solutions {
+			solns[i] = soln[4*c : 4*c+4]
+		}
+		verifyXor(t, solns[:1], res.Energies[:1])
+	}
+	if e := p.IsingEnergy(res.Solutions[0]); math.Abs(e-res.Energies[0]) > 1e-9 {
+		t.Fatalf("Expected an energy of %v but saw %v", e, res.Energies[0])
+	}
+}
+
+// TestGraphProblems ensures that max-cut, independent-set, and coloring
+// encodings of a five-variable ring have the expected ground states.
+func TestGraphProblems(t *testing.T) {
+	// Construct a five-variable ring.
+	var g sapi.Problem
+	for i := 0; i < 5; i++ {
+		g = append(g, sapi.ProblemEntry{I: i, J: (i + 1) % 5, Value: 1})
+	}
+	var es sapi.ExactSolver
+	sp := es.NewSolverParameters()
+
+	// The maximum cut of an odd ring omits exactly one edge.
+	mc, ofs := sapi.MaxCutToIsing(g)
+	res, err := es.SolveIsing(mc, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cut := sapi.CutSize(g, res.Solutions[0]); cut != 4 || res.Energies[0]+ofs != -4 {
+		t.Fatalf("Expected a cut of size 4 but saw %d (energy %v)", cut, res.Energies[0]+ofs)
+	}
+	if a, b := sapi.CutPartition(res.Solutions[0]); len(a)+len(b) != 5 {
+		t.Fatalf("Expected five partitioned vertices but saw %v and %v", a, b)
+	}
+
+	// A maximum independent set of the ring has two vertices.
+	res, err = es.SolveQubo(sapi.IndependentSetToQubo(g, 0), sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := sapi.DecodeIndependentSet(res.Solutions[0])
+	if len(set) != 2 || !sapi.IsIndependentSet(g, set) || res.Energies[0] != -2 {
+		t.Fatalf("Expected an independent set of size 2 but saw %v", set)
+	}
+
+	// The ring can be colored with three colors but not with two.
+	for k, proper := range map[int]bool{2: false, 3: true} {
+		ce, err := sapi.ColoringToQubo(g, k, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err = es.SolveQubo(ce.Problem, sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		colors, ok := ce.Decode(res.Solutions[0])
+		valid := ok && sapi.IsProperColoring(g, colors)
+		if valid != proper || (res.Energies[0]+ce.Offset == 0) != proper {
+			t.Fatalf("Expected proper=%v for %d colors but saw %v (energy %v)", proper, k, colors, res.Energies[0]+ce.Offset)
+		}
+	}
+}
+
+// TestPermutationProblems ensures that the ground states of small TSP and
+// QAP encodings are valid, optimal permutations.
+func TestPermutationProblems(t *testing.T) {
+	var es sapi.ExactSolver
+	sp := es.NewSolverParameters()
+
+	// The shortest tour of the corners of a unit square is its perimeter.
+	d := math.Sqrt2
+	dist := [][]float64{
+		{0, 1, d, 1},
+		{1, 0, 1, d},
+		{d, 1, 0, 1},
+		{1, d, 1, 0},
+	}
+	enc, err := sapi.TSPToQubo(dist, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := es.SolveQubo(enc.Problem, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tour, ok := enc.Order(res.Solutions[0])
+	if !ok {
+		t.Fatalf("Invalid tour %v", tour)
+	}
+	if l := sapi.TourLength(dist, tour); math.Abs(l-4) > 1e-9 || math.Abs(res.Energies[0]+enc.Offset-l) > 1e-9 {
+		t.Fatalf("Expected a tour of length 4 but saw %v with length %v (energy %v)", tour, l, res.Energies[0]+enc.Offset)
+	}
+
+	// Compare the QAP ground state to the best of all assignments.
+	flow := [][]float64{{0, 5, 1}, {5, 0, 2}, {1, 2, 0}}
+	locs := [][]float64{{0, 1, 3}, {1, 0, 2}, {3, 2, 0}}
+	enc, err = sapi.QAPToQubo(flow, locs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = es.SolveQubo(enc.Problem, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assign, ok := enc.Assignment(res.Solutions[0])
+	if !ok {
+		t.Fatalf("Invalid assignment %v", assign)
+	}
+	best := math.Inf(1)
+	for _, a := range [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+		best = math.Min(best, sapi.AssignmentCost(flow, locs, a))
+	}
+	if c := sapi.AssignmentCost(flow, locs, assign); c != best || math.Abs(res.Energies[0]+enc.Offset-c) > 1e-9 {
+		t.Fatalf("Expected an assignment cost of %v but saw %v (energy %v)", best, c, res.Energies[0]+enc.Offset)
+	}
+}
+
+// TestProblemHash ensures that equivalent problems hash identically and that
+// different problems do not.
+func TestProblemHash(t *testing.T) {
+	p1 := sapi.Problem{
+		{I: 0, J: 0, Value: 1},
+		{I: 1, J: 0, Value: -0.5},
+		{I: 0, J: 1, Value: -0.5},
+	}
+	p2 := sapi.Problem{
+		{I: 0, J: 1, Value: -1},
+		{I: 0, J: 0, Value: 1},
+	}
+	if h1, h2 := p1.Hash(), p2.Hash(); h1 != h2 {
+		t.Fatalf("Equivalent problems hashed to %s and %s", h1, h2)
+	}
+	p2[0].Value = -2
+	if p1.Hash() == p2.Hash() {
+		t.Fatal("Different problems hashed identically")
+	}
+
+	// Ensure that graph hashes ignore coefficients and linear terms on
+	// coupled variables but not the variable set.
+	p3 := sapi.Problem{{I: 1, J: 0, Value: 3}}
+	if h1, h3 := p1.GraphHash(), p3.GraphHash(); h1 != h3 {
+		t.Fatalf("Problems with the same graph hashed to %s and %s", h1, h3)
+	}
+	p3 = append(p3, sapi.ProblemEntry{I: 2, J: 2, Value: 1})
+	if p1.GraphHash() == p3.GraphHash() {
+		t.Fatal("Problems with different variables graph-hashed identically")
+	}
+}
+
+// chimeraAdjacency constructs the adjacency of a Chimera graph without
+// calling into SAPI.
+func chimeraAdjacency(ts sapi.TopologySpec) sapi.Problem {
+	var adj sapi.Problem
+	for r := 0; r < ts.M; r++ {
+		for c := 0; c < ts.N; c++ {
+			for i := 0; i < ts.L; i++ {
+				v := ts.Qubit(sapi.ChimeraCoord{Row: r, Col: c, Side: 0, Index: i})
+				h := ts.Qubit(sapi.ChimeraCoord{Row: r, Col: c, Side: 1, Index: i})
+				for j := 0; j < ts.L; j++ {
+					hj := ts.Qubit(sapi.ChimeraCoord{Row: r, Col: c, Side: 1, Index: j})
+					adj = append(adj, sapi.ProblemEntry{I: v, J: hj, Value: 1})
+				}
+				if r+1 < ts.M {
+					below := ts.Qubit(sapi.ChimeraCoord{Row: r + 1, Col: c, Side: 0, Index: i})
+					adj = append(adj, sapi.ProblemEntry{I: v, J: below, Value: 1})
+				}
+				if c+1 < ts.N {
+					right := ts.Qubit(sapi.ChimeraCoord{Row: r, Col: c + 1, Side: 1, Index: i})
+					adj = append(adj, sapi.ProblemEntry{I: h, J: right, Value: 1})
+				}
+			}
+		}
+	}
+	return adj
+}
+
+// TestFindCliqueEmbedding ensures that clique embeddings consist of
+// connected, mutually coupled chains, even in the presence of faults.
+func TestFindCliqueEmbedding(t *testing.T) {
+	ts := sapi.Chimera(4, 4, 4)
+	if n := ts.CliqueSize(); n != 16 {
+		t.Fatalf("Expected a clique size of 16 but saw %d", n)
+	}
+
+	// Ensure that unsupported topology families are rejected.
+	pegasus := sapi.TopologySpec{Family: "pegasus", M: 6, N: 6, L: 12}
+	if n := pegasus.CliqueSize(); n != 0 {
+		t.Fatalf("Expected a clique size of 0 for Pegasus but saw %d", n)
+	}
+	if _, err := sapi.FindCliqueEmbedding(4, pegasus, nil); err == nil {
+		t.Fatal("Expected a Pegasus clique embedding to be rejected")
+	}
+
+	// Remove a qubit to simulate a fault.
+	var adj sapi.Problem
+	for _, pe := range chimeraAdjacency(ts) {
+		if pe.I != 5 && pe.J != 5 {
+			adj = append(adj, pe)
+		}
+	}
+	for _, n := range []int{16, 12} {
+		var working sapi.Problem
+		if n == 12 {
+			working = adj
+		}
+		emb, err := sapi.FindCliqueEmbedding(n, ts, working)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if working == nil {
+			working = chimeraAdjacency(ts)
+		}
+		chains := emb.Chains()
+		if len(chains) != n {
+			t.Fatalf("Expected %d chains but saw %d", n, len(chains))
+		}
+		if m := emb.Metrics(); m.MaxChainLength > 5 {
+			t.Fatalf("Expected chains of at most 5 qubits but saw %d", m.MaxChainLength)
+		}
+		g := working.Graph()
+		for v, ch := range chains {
+			// Ensure that the chain is connected.
+			var sub sapi.Problem
+			for _, q := range ch {
+				for _, r := range ch {
+					if q < r && g.Adjacent(q, r) {
+						sub = append(sub, sapi.ProblemEntry{I: q, J: r})
+					}
+				}
+			}
+			if len(ch) > 1 && len(sub.ConnectedComponents()) != 1 {
+				t.Fatalf("Chain %d (%v) is not connected", v, ch)
+			}
+
+			// Ensure that the chain is coupled to every other chain.
+			for u := 0; u < v; u++ {
+				found := false
+				for _, q := range ch {
+					for _, r := range chains[u] {
+						found = found || g.Adjacent(q, r)
+					}
+				}
+				if !found {
+					t.Fatalf("Chains %d and %d are not coupled", u, v)
+				}
+			}
+		}
+	}
+}
+
+// TestFindEmbeddingNative ensures that the pure-Go embedder can embed the
+// XOR problem in a Chimera graph.
+func TestFindEmbeddingNative(t *testing.T) {
+	adj := chimeraAdjacency(sapi.Chimera(2, 2, 4))
+	p := xorProblem()
+	emb, err := sapi.FindEmbeddingNative(context.Background(), p, adj, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := embedder.Graph{}
+	for _, pe := range p {
+		source[pe.I] = append(source[pe.I], pe.J)
+	}
+	target := embedder.Graph{}
+	for _, pe := range adj {
+		target[pe.I] = append(target[pe.I], pe.J)
+	}
+	if err := embedder.Verify(source, target, emb.Chains()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEmbeddingDocument ensures that embeddings survive a round trip through
+// both the JSON and binary encodings.
+func TestEmbeddingDocument(t *testing.T) {
+	emb := sapi.Embeddings{0, 0, -1, 1, -1, 2, 2, -1}
+	p := xorProblem()[:3]
+	ts := sapi.Chimera(1, 1, 4)
+	adj := chimeraAdjacency(ts)
+	doc := sapi.NewEmbeddingDocument(emb, p, adj)
+	doc.Metadata = map[string]string{"topology": ts.String(), "attempts": "3"}
+	if !doc.Matches(p, adj) || doc.Matches(xorProblem(), adj) || doc.Matches(p, adj[1:]) {
+		t.Fatal("Matches returned an incorrect result")
+	}
+
+	// Round-trip the document through JSON.
+	data, err := sapi.MarshalEmbedding(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := sapi.UnmarshalEmbedding(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, doc) {
+		t.Fatalf("Expected %+v but saw %+v", doc, got)
+	}
+
+	// Round-trip the document through the binary format.
+	var buf bytes.Buffer
+	if err = sapi.WriteEmbedding(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() >= len(data) {
+		t.Fatalf("Binary encoding (%d bytes) is not smaller than JSON (%d bytes)", buf.Len(), len(data))
+	}
+	if got, err = sapi.ReadEmbedding(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, doc) {
+		t.Fatalf("Expected %+v but saw %+v", doc, got)
+	}
+}
+
+// TestEmbeddingDocumentMalformed ensures that corrupt or hostile embedding
+// documents are rejected with an error rather than a panic or an enormous
+// allocation.
+func TestEmbeddingDocumentMalformed(t *testing.T) {
+	// Reject malformed JSON documents.
+	for _, js := range []string{
+		`{"format":"sapi-embedding/1","num_qubits":-1,"chains":[]}`,
+		`{"format":"sapi-embedding/1","num_qubits":1099511627776,"chains":[]}`,
+		`{"format":"sapi-embedding/1","num_qubits":4,"chains":[[0,4]]}`,
+		`{"format":"sapi-embedding/1","num_qubits":4,"chains":[[0,1],[1]]}`,
+		`{"format":"sapi-embedding/2","num_qubits":4,"chains":[]}`,
+		`{"format":`,
+	} {
+		if _, err := sapi.UnmarshalEmbedding([]byte(js)); err == nil {
+			t.Fatalf("Expected %s to be rejected", js)
+		}
+	}
+
+	// Reject malformed binary documents.  "\x80\x80\x80\x80\x08" is the
+	// varint encoding of 2^31.
+	for _, bin := range []string{
+		"",
+		"SAPIEMB0\x00\x00\x00\x00\x00",
+		"SAPIEMB1\x80\x80\x80\x80\x08",
+		"SAPIEMB1\x00\x00\x80\x80\x80\x80\x08",
+		"SAPIEMB1\x00\x00\x00\x80\x80\x80\x80\x08",
+		"SAPIEMB1\x00\x00\x00\x04\x80\x80\x80\x80\x08",
+		"SAPIEMB1\x00\x00\x00\x04\x01\x01\x04",
+		"SAPIEMB1\x05abc",
+	} {
+		if _, err := sapi.ReadEmbedding(strings.NewReader(bin)); err == nil {
+			t.Fatalf("Expected %q to be rejected", bin)
+		}
+	}
+}
+
+// TestEmbeddingCache ensures that cached embeddings are found for problems
+// with the same structure and working graph and for no others.
+func TestEmbeddingCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sapi-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache, err := sapi.NewEmbeddingCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Store an embedding and look it up with different coefficients.
+	adj := chimeraAdjacency(sapi.Chimera(1, 1, 4))
+	p := sapi.Problem{{I: 0, J: 1, Value: 1}, {I: 1, J: 1, Value: -1}}
+	emb := sapi.Embeddings{0, -1, -1, -1, 1, -1, -1, -1}
+	if err = cache.Store(p, adj, emb); err != nil {
+		t.Fatal(err)
+	}
+	q := sapi.Problem{{I: 1, J: 0, Value: -3}, {I: 1, J: 1, Value: 2}}
+	got, ok, err := cache.Lookup(q, adj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || !reflect.DeepEqual(got, emb) {
+		t.Fatalf("Expected to find %v but saw %v (found = %v)", emb, got, ok)
+	}
+
+	// Ensure that a different structure or working graph misses.
+	q = append(q, sapi.ProblemEntry{I: 2, J: 2})
+	if _, ok, _ = cache.Lookup(q, adj); ok {
+		t.Fatal("Found an embedding for a problem with a different structure")
+	}
+	if _, ok, _ = cache.Lookup(p, adj[1:]); ok {
+		t.Fatal("Found an embedding for a different working graph")
+	}
 }
@@ -0,0 +1,106 @@
+// This file provides a one-call pipeline for shrinking a QUBO problem before
+// submitting it to a solver.
+
+package sapi
+
+// A Simplification records the reduction of a QUBO problem by Simplify and
+// can map solutions of the reduced problem back to the original problem.
+type Simplification struct {
+	Problem Problem      // Reduced problem, with variables numbered from 0
+	Offset  float64      // Energy of the original problem minus that of the reduced problem
+	Fixed   map[int]int8 // Map from each eliminated original variable to its value
+	Vars    []int        // Original variable number of each variable of the reduced problem
+	numVars int          // One more than the largest original variable number
+}
+
+// Simplify reduces a QUBO problem in three steps: it fixes variables using
+// FixVariables with a given method; it eliminates variables left with no
+// nonzero quadratic terms, setting each to whichever value minimizes its
+// linear term; and it renumbers the remaining variables densely from 0 with
+// Compact.  The result can map solutions of the reduced problem back to the
+// original problem.
+func (p Problem) Simplify(m FixVariablesMethod) (*Simplification, error) {
+	// Fix variables that have the same value in all optimal solutions.
+	fvr, err := p.FixVariables(m)
+	if err != nil {
+		return nil, err
+	}
+	s := &Simplification{
+		Offset: fvr.Offset,
+		Fixed:  make(map[int]int8, len(fvr.FixedVars)),
+	}
+	for v, b := range fvr.FixedVars {
+		s.Fixed[v] = b
+	}
+	for _, pe := range p {
+		for _, v := range [2]int{pe.I, pe.J} {
+			if v+1 > s.numVars {
+				s.numVars = v + 1
+			}
+		}
+	}
+
+	// Eliminate variables that no longer interact with any other variable.
+	np := fvr.NewProblem.Canonicalize()
+	np.CanonicalizeInPlace(true)
+	coupled := make(map[int]bool)
+	for _, pe := range np {
+		if pe.I != pe.J {
+			coupled[pe.I] = true
+			coupled[pe.J] = true
+		}
+	}
+	reduced := make(Problem, 0, len(np))
+	for _, pe := range np {
+		switch {
+		case pe.I != pe.J || coupled[pe.I]:
+			reduced = append(reduced, pe)
+		case pe.Value < 0:
+			s.Fixed[pe.I] = 1
+			s.Offset += pe.Value
+		default:
+			s.Fixed[pe.I] = 0
+		}
+	}
+
+	// Renumber the remaining variables densely.
+	var mapping map[int]int
+	s.Problem, mapping = reduced.Compact()
+	s.Vars = make([]int, len(mapping))
+	for o, n := range mapping {
+		s.Vars[n] = o
+	}
+	return s, nil
+}
+
+// Expand maps a solution of the reduced problem to a solution of the
+// original problem.  Original variables that appear neither in the reduced
+// problem nor among the fixed variables (i.e., variables with no nonzero
+// coefficients) are set to 0.
+func (s *Simplification) Expand(soln []int8) []int8 {
+	full := make([]int8, s.numVars)
+	for v, b := range s.Fixed {
+		full[v] = b
+	}
+	for n, o := range s.Vars {
+		if n < len(soln) {
+			full[o] = soln[n]
+		}
+	}
+	return full
+}
+
+// ExpandResult maps every solution and energy of a result for the reduced
+// problem to the original problem.  The argument is not modified.
+func (s *Simplification) ExpandResult(ir IsingResult) IsingResult {
+	res := ir
+	res.Solutions = make([][]int8, len(ir.Solutions))
+	for i, soln := range ir.Solutions {
+		res.Solutions[i] = s.Expand(soln)
+	}
+	res.Energies = make([]float64, len(ir.Energies))
+	for i, e := range ir.Energies {
+		res.Energies[i] = e + s.Offset
+	}
+	return res
+}
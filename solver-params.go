@@ -19,7 +19,13 @@ import (
 // to return solutions.
 type SolverParameterAnswerMode int
 
-// These are answer modes a solver can accept.
+// These are answer modes a solver can accept.  In histogram mode, each
+// distinct solution is returned once, in order of increasing energy, and
+// IsingResult.Occurrences tallies how many reads produced it; MaxAnswers caps
+// the number of distinct solutions.  In raw mode, the solution from every
+// read is returned in the order the reads were taken, and
+// IsingResult.Occurrences is nil; MaxAnswers caps the number of reads
+// returned and should not exceed NumReads.
 const (
 	AnswerModeHistogram SolverParameterAnswerMode = C.SAPI_ANSWER_MODE_HISTOGRAM
 	AnswerModeRaw                                 = C.SAPI_ANSWER_MODE_RAW
@@ -31,48 +37,104 @@ type Postprocessing int
 
 // These are the supported types of postprocessing a solver can perform.
 const (
-	PostprocessNode         Postprocessing = C.SAPI_POSTPROCESS_NONE
+	PostprocessNone         Postprocessing = C.SAPI_POSTPROCESS_NONE
 	PostprocessSampling                    = C.SAPI_POSTPROCESS_SAMPLING
 	PostprocessOptimization                = C.SAPI_POSTPROCESS_OPTIMIZATION
+
+	// PostprocessNode is a misspelled synonym for PostprocessNone that is
+	// retained for compatibility.
+	PostprocessNode = PostprocessNone
 )
 
 // SolverParameters is presented as an interface so the caller does not need to
 // use different data structures for the different solver types (quantum
-// hardware or the various software solvers).
+// hardware or the various software solvers).  Implementations are plain Go
+// structs that can be inspected, copied, and serialized; each call to
+// ToCSolverParameters produces a fresh C structure, so the same parameters can
+// be reused across submissions.  The Solver methods that submit problems free
+// that structure's C arrays once the submission call returns.
 type SolverParameters interface {
+	// ToCSolverParameters converts the parameters to a C structure.
 	ToCSolverParameters() *C.sapi_SolverParameters
+
+	// Clone returns a deep copy of the parameters, which can be modified
+	// without affecting the original.
+	Clone() SolverParameters
+
+	// Validate cross-checks the parameters' values against a solver's
+	// properties and reports all violations together as a
+	// ValidationError.
+	Validate(s *Solver) error
 }
 
-// NewSolverParameters returns an appropriate SolverParameters for the solver
-// type.
-func (s *Solver) NewSolverParameters() SolverParameters {
+// parametersTypeName returns the name (see the package-level
+// parametersTypeName) of the type of SolverParameters that
+// NewSolverParameters returns for a solver.  Unlike calling
+// NewSolverParameters, it consumes no random numbers.
+func (s *Solver) parametersTypeName() string {
 	switch {
 	case strings.HasSuffix(s.Name, "-sw_optimize"):
-		return newSwOptimizeSolverParameters()
+		return "sw_optimize"
 	case strings.HasSuffix(s.Name, "-sw_sample"):
-		return newSwSampleSolverParameters()
+		return "sw_sample"
 	case strings.HasSuffix(s.Name, "-heuristic"):
-		return newSwHeuristicSolverParameters()
+		return "heuristic"
 	default:
-		return newQuantumSolverParameters()
+		return "quantum"
 	}
 }
 
+// NewSolverParameters returns an appropriate SolverParameters for the solver
+// type.  Any defaults specified by the solver's Connection override SAPI's
+// defaults, and any defaults the Connection specifies for the solver's class
+// of parameters override those.  If a source of randomness was supplied
+// with SetRandSource, the software samplers' random seeds are drawn from it.
+func (s *Solver) NewSolverParameters() SolverParameters {
+	var sp SolverParameters
+	switch s.parametersTypeName() {
+	case "sw_optimize":
+		sp = newSwOptimizeSolverParameters()
+	case "sw_sample":
+		sp = newSwSampleSolverParameters()
+	case "heuristic":
+		sp = newSwHeuristicSolverParameters()
+	default:
+		qsp := newQuantumSolverParameters()
+		qsp.props = s.Properties()
+		sp = qsp
+	}
+	if s.Conn != nil {
+		s.Conn.Defaults.apply(sp)
+		if d, ok := s.Conn.ClassDefaults[parametersTypeName(sp)]; ok {
+			d.apply(sp)
+		}
+	}
+	if seed, ok := randomSeed(); ok {
+		switch sp := sp.(type) {
+		case *SwSampleSolverParameters:
+			sp.UseRandomSeed = true
+			sp.RandomSeed = seed
+		case *SwHeuristicSolverParameters:
+			sp.UseRandomSeed = true
+			sp.RandomSeed = seed
+		}
+	}
+	return sp
+}
+
 // A SwOptimizeSolverParameters represents the parameters that can be passed to
 // an optimizing software solver.  It implements the SolverParameters
 // interface.
 type SwOptimizeSolverParameters struct {
-	sosp       C.sapi_SwOptimizeSolverParameters // C version of the parameters
-	AnswerMode SolverParameterAnswerMode         // Whether to return individual answers or a histogram
-	MaxAnswers int                               // Maximum number of answers to return
-	NumReads   int                               // Number of samples to take
+	AnswerMode SolverParameterAnswerMode // Whether to return individual answers or a histogram
+	MaxAnswers int                       // Maximum number of answers to return
+	NumReads   int                       // Number of samples to take
 }
 
 // newSwOptimizeSolverParameters returns a new SwOptimizeSolverParameters.
 func newSwOptimizeSolverParameters() *SwOptimizeSolverParameters {
 	cSosp := C.SAPI_SW_OPTIMIZE_SOLVER_DEFAULT_PARAMETERS
 	return &SwOptimizeSolverParameters{
-		sosp:       cSosp,
 		AnswerMode: SolverParameterAnswerMode(cSosp.answer_mode),
 		MaxAnswers: int(cSosp.max_answers),
 		NumReads:   int(cSosp.num_reads),
@@ -82,22 +144,22 @@ func newSwOptimizeSolverParameters() *SwOptimizeSolverParameters {
 // ToCSolverParameters converts a SwOptimizeSolverParameters to a
 // sapi_SolverParameters.
 func (p *SwOptimizeSolverParameters) ToCSolverParameters() *C.sapi_SolverParameters {
-	p.sosp.answer_mode = C.sapi_SolverParameterAnswerMode(p.AnswerMode)
-	p.sosp.max_answers = C.int(p.MaxAnswers)
-	p.sosp.num_reads = C.int(p.NumReads)
-	return (*C.sapi_SolverParameters)(unsafe.Pointer(&p.sosp))
+	sosp := C.SAPI_SW_OPTIMIZE_SOLVER_DEFAULT_PARAMETERS
+	sosp.answer_mode = C.sapi_SolverParameterAnswerMode(p.AnswerMode)
+	sosp.max_answers = C.int(p.MaxAnswers)
+	sosp.num_reads = C.int(p.NumReads)
+	return (*C.sapi_SolverParameters)(unsafe.Pointer(&sosp))
 }
 
 // A SwSampleSolverParameters represents the parameters that can be passed to a
 // sampling software solver.  It implements the SolverParameters interface.
 type SwSampleSolverParameters struct {
-	sssp          C.sapi_SwSampleSolverParameters // C version of the parameters
-	AnswerMode    SolverParameterAnswerMode       // Answer mode
-	Beta          float64                         // Boltzmann distribution parameter
-	MaxAnswers    int                             // Maximum number of answers to return
-	NumReads      int                             // Number of samples to take
-	UseRandomSeed bool                            // true if RandomSeed is to be honored
-	RandomSeed    uint                            // Seed for the random-number generator
+	AnswerMode    SolverParameterAnswerMode // Answer mode
+	Beta          float64                   // Boltzmann distribution parameter
+	MaxAnswers    int                       // Maximum number of answers to return
+	NumReads      int                       // Number of samples to take
+	UseRandomSeed bool                      // true if RandomSeed is to be honored
+	RandomSeed    uint                      // Seed for the random-number generator
 }
 
 // newSwSampleSolverParameters returns a new SwSampleSolverParameters.
@@ -105,7 +167,6 @@ func newSwSampleSolverParameters() *SwSampleSolverParameters {
 	cSssp := C.SAPI_SW_SAMPLE_SOLVER_DEFAULT_PARAMETERS
 	cIntToBool := map[C.int]bool{0: false, 1: true}
 	return &SwSampleSolverParameters{
-		sssp:          cSssp,
 		AnswerMode:    SolverParameterAnswerMode(cSssp.answer_mode),
 		Beta:          float64(cSssp.beta),
 		MaxAnswers:    int(cSssp.max_answers),
@@ -118,34 +179,34 @@ func newSwSampleSolverParameters() *SwSampleSolverParameters {
 // ToCSolverParameters converts a SwSampleSolverParameters to a
 // sapi_SolverParameters.
 func (p *SwSampleSolverParameters) ToCSolverParameters() *C.sapi_SolverParameters {
-	p.sssp.answer_mode = C.sapi_SolverParameterAnswerMode(p.AnswerMode)
-	p.sssp.beta = C.double(p.Beta)
-	p.sssp.max_answers = C.int(p.MaxAnswers)
-	p.sssp.num_reads = C.int(p.NumReads)
+	sssp := C.SAPI_SW_SAMPLE_SOLVER_DEFAULT_PARAMETERS
+	sssp.answer_mode = C.sapi_SolverParameterAnswerMode(p.AnswerMode)
+	sssp.beta = C.double(p.Beta)
+	sssp.max_answers = C.int(p.MaxAnswers)
+	sssp.num_reads = C.int(p.NumReads)
 	if p.UseRandomSeed {
-		p.sssp.use_random_seed = 1
+		sssp.use_random_seed = 1
 	} else {
-		p.sssp.use_random_seed = 0
+		sssp.use_random_seed = 0
 	}
-	p.sssp.random_seed = C.uint(p.RandomSeed)
-	return (*C.sapi_SolverParameters)(unsafe.Pointer(&p.sssp))
+	sssp.random_seed = C.uint(p.RandomSeed)
+	return (*C.sapi_SolverParameters)(unsafe.Pointer(&sssp))
 }
 
 // A SwHeuristicSolverParameters represents the parameters that can be passed
 // to a heuristic software solver.  It implements the SolverParameters
 // interface.
 type SwHeuristicSolverParameters struct {
-	shsp               C.sapi_SwHeuristicSolverParameters // C version of the parameters
-	IterationLimit     int                                // Maximum number of solver iterations
-	MinBitFlipProb     float64                            // Minimum bit-flip probability
-	MaxBitFlipProb     float64                            // Maximum bit-flip probability
-	MaxLocalComplexity int                                // Maximum complexity of subgraphs used during local search
-	LocalStuckLimit    int                                // Maximum number of consecutive local search steps that do not improve solution quality
-	NumPerturbedCopies int                                // Number of perturbed solution copies created at each iteration
-	NumVariables       int                                // Lower bound on the number of variables
-	UseRandomSeed      bool                               // true if RandomSeed is to be honored
-	RandomSeed         uint                               // Seed for the random-number generator
-	TimeLimitSeconds   float64                            // Maximum wall-clock time in seconds
+	IterationLimit     int     // Maximum number of solver iterations
+	MinBitFlipProb     float64 // Minimum bit-flip probability
+	MaxBitFlipProb     float64 // Maximum bit-flip probability
+	MaxLocalComplexity int     // Maximum complexity of subgraphs used during local search
+	LocalStuckLimit    int     // Maximum number of consecutive local search steps that do not improve solution quality
+	NumPerturbedCopies int     // Number of perturbed solution copies created at each iteration
+	NumVariables       int     // Lower bound on the number of variables
+	UseRandomSeed      bool    // true if RandomSeed is to be honored
+	RandomSeed         uint    // Seed for the random-number generator
+	TimeLimitSeconds   float64 // Maximum wall-clock time in seconds
 }
 
 // newSwHeuristicSolverParameters returns a new SwHeuristicSolverParameters.
@@ -153,7 +214,6 @@ func newSwHeuristicSolverParameters() *SwHeuristicSolverParameters {
 	cShsp := C.SAPI_SW_HEURISTIC_SOLVER_DEFAULT_PARAMETERS
 	cIntToBool := map[C.int]bool{0: false, 1: true}
 	return &SwHeuristicSolverParameters{
-		shsp:               cShsp,
 		IterationLimit:     int(cShsp.iteration_limit),
 		MinBitFlipProb:     float64(cShsp.min_bit_flip_prob),
 		MaxBitFlipProb:     float64(cShsp.max_bit_flip_prob),
@@ -170,39 +230,43 @@ func newSwHeuristicSolverParameters() *SwHeuristicSolverParameters {
 // ToCSolverParameters converts a SwHeuristicSolverParameters to a
 // sapi_SolverParameters.
 func (p *SwHeuristicSolverParameters) ToCSolverParameters() *C.sapi_SolverParameters {
-	p.shsp.iteration_limit = C.int(p.IterationLimit)
-	p.shsp.min_bit_flip_prob = C.double(p.MinBitFlipProb)
-	p.shsp.max_bit_flip_prob = C.double(p.MaxBitFlipProb)
-	p.shsp.max_local_complexity = C.int(p.MaxLocalComplexity)
-	p.shsp.local_stuck_limit = C.int(p.LocalStuckLimit)
-	p.shsp.num_perturbed_copies = C.int(p.NumPerturbedCopies)
-	p.shsp.num_variables = C.int(p.NumVariables)
+	shsp := C.SAPI_SW_HEURISTIC_SOLVER_DEFAULT_PARAMETERS
+	shsp.iteration_limit = C.int(p.IterationLimit)
+	shsp.min_bit_flip_prob = C.double(p.MinBitFlipProb)
+	shsp.max_bit_flip_prob = C.double(p.MaxBitFlipProb)
+	shsp.max_local_complexity = C.int(p.MaxLocalComplexity)
+	shsp.local_stuck_limit = C.int(p.LocalStuckLimit)
+	shsp.num_perturbed_copies = C.int(p.NumPerturbedCopies)
+	shsp.num_variables = C.int(p.NumVariables)
 	if p.UseRandomSeed {
-		p.shsp.use_random_seed = 1
+		shsp.use_random_seed = 1
 	} else {
-		p.shsp.use_random_seed = 0
+		shsp.use_random_seed = 0
 	}
-	p.shsp.random_seed = C.uint(p.RandomSeed)
-	p.shsp.time_limit_seconds = C.double(p.TimeLimitSeconds)
-	return (*C.sapi_SolverParameters)(unsafe.Pointer(&p.shsp))
+	shsp.random_seed = C.uint(p.RandomSeed)
+	shsp.time_limit_seconds = C.double(p.TimeLimitSeconds)
+	return (*C.sapi_SolverParameters)(unsafe.Pointer(&shsp))
 }
 
 // A QuantumSolverParameters represents the parameters that can be passed to a
 // quantum solver.  It implements the SolverParameters interface.
 type QuantumSolverParameters struct {
-	qsp              C.sapi_QuantumSolverParameters // C version of the parameters
-	AnnealingTime    int                            // Annealing time in microseconds
-	AnswerMode       SolverParameterAnswerMode      // Whether to return individual answers or a histogram
-	AutoScale        bool                           // Scale coefficients to their maximum range
-	Beta             float64                        // Boltzmann distribution parameter
-	Chains           []int                          // Postprocessing chains
-	MaxAnswers       int                            // Maximum number of answers to return
-	NumReads         int                            // Number of samples to take
-	NumSpinReversals int                            // Number of spin-reversal transformations to perform
-	Postprocess      Postprocessing                 // Type of classical postprocessing to perform
-	ProgTherm        int                            // Post-programming thermalization time in microseconds
-	ReadoutTherm     int                            // Post-readout thermalization time in microseconds
-	AnnealOffsets    []float64                      // Per-qubit amount to offset annealing paths
+	AnnealingTime     int                       // Annealing time in microseconds
+	AnswerMode        SolverParameterAnswerMode // Whether to return individual answers or a histogram
+	AutoScale         bool                      // Scale coefficients to their maximum range
+	Beta              float64                   // Boltzmann distribution parameter
+	Chains            []int                     // Chain (logical variable or -1) of each qubit for postprocessing
+	MaxAnswers        int                       // Maximum number of answers to return
+	NumReads          int                       // Number of samples to take
+	NumSpinReversals  int                       // Number of spin-reversal transformations to perform
+	Postprocess       Postprocessing            // Type of classical postprocessing to perform
+	ProgTherm         int                       // Post-programming thermalization time in microseconds
+	ReadoutTherm      int                       // Post-readout thermalization time in microseconds
+	ReduceCorrelation bool                      // Add delays between samples to reduce intersample correlation
+	AnnealOffsets     []float64                 // Per-qubit amount to offset annealing paths
+	FluxBiases        []float64                 // Per-qubit flux-bias offsets in units of Φ0
+	AnnealSchedule    []SchedulePoint           // Piecewise-linear anneal schedule
+	props             *SolverProperties         // Properties of the solver for which the parameters were created, if known
 }
 
 // newQuantumSolverParameters returns a new QuantumSolverParameters.
@@ -210,41 +274,44 @@ func newQuantumSolverParameters() *QuantumSolverParameters {
 	cQsp := C.SAPI_QUANTUM_SOLVER_DEFAULT_PARAMETERS
 	cIntToBool := map[C.int]bool{0: false, 1: true}
 	return &QuantumSolverParameters{
-		qsp:              cQsp,
-		AnswerMode:       SolverParameterAnswerMode(cQsp.answer_mode),
-		AutoScale:        cIntToBool[cQsp.auto_scale],
-		Beta:             float64(cQsp.beta),
-		Chains:           nil,
-		MaxAnswers:       int(cQsp.max_answers),
-		NumReads:         int(cQsp.num_reads),
-		NumSpinReversals: int(cQsp.num_spin_reversal_transforms),
-		Postprocess:      Postprocessing(cQsp.postprocess),
-		ProgTherm:        int(cQsp.programming_thermalization),
-		ReadoutTherm:     int(cQsp.readout_thermalization),
-		AnnealOffsets:    nil,
+		AnnealingTime:     int(cQsp.annealing_time),
+		AnswerMode:        SolverParameterAnswerMode(cQsp.answer_mode),
+		AutoScale:         cIntToBool[cQsp.auto_scale],
+		Beta:              float64(cQsp.beta),
+		Chains:            nil,
+		MaxAnswers:        int(cQsp.max_answers),
+		NumReads:          int(cQsp.num_reads),
+		NumSpinReversals:  int(cQsp.num_spin_reversal_transforms),
+		Postprocess:       Postprocessing(cQsp.postprocess),
+		ProgTherm:         int(cQsp.programming_thermalization),
+		ReadoutTherm:      int(cQsp.readout_thermalization),
+		ReduceCorrelation: cIntToBool[cQsp.reduce_intersample_correlation],
+		AnnealOffsets:     nil,
+		FluxBiases:        nil,
+		AnnealSchedule:    nil,
 	}
 }
 
 // convertChainsToGo converts the list of chains from Go to C.
-func (p *QuantumSolverParameters) convertChainsToGo() {
+func (p *QuantumSolverParameters) convertChainsToGo(qsp *C.sapi_QuantumSolverParameters) {
 	cs := p.Chains
 	if len(cs) == 0 {
-		p.qsp.chains = nil
+		qsp.chains = nil
 		return
 	}
 	nc := C.size_t(len(cs))
 	chains := (*C.sapi_Chains)(C.malloc(C.sizeof_sapi_Chains))
 	chains.len = nc
 	chains.elements = goIntsToC(cs)
-	p.qsp.chains = chains
+	qsp.chains = chains
 }
 
 // convertAnnealOffsetsToGo converts the list of per-qubit anneal offsets from
 // C to Go.
-func (p *QuantumSolverParameters) convertAnnealOffsetsToGo() {
+func (p *QuantumSolverParameters) convertAnnealOffsetsToGo(qsp *C.sapi_QuantumSolverParameters) {
 	ao := p.AnnealOffsets
 	if len(ao) == 0 {
-		p.qsp.anneal_offsets = nil
+		qsp.anneal_offsets = nil
 		return
 	}
 	na := C.size_t(len(ao))
@@ -256,26 +323,108 @@ func (p *QuantumSolverParameters) convertAnnealOffsetsToGo() {
 		ePtr[i] = C.double(o)
 	}
 	ofs.elements = (*C.double)(elts)
-	p.qsp.anneal_offsets = ofs
+	qsp.anneal_offsets = ofs
+}
+
+// convertFluxBiasesToGo converts the list of per-qubit flux-bias offsets from
+// Go to C.
+func (p *QuantumSolverParameters) convertFluxBiasesToGo(qsp *C.sapi_QuantumSolverParameters) {
+	fb := p.FluxBiases
+	if len(fb) == 0 {
+		qsp.flux_biases = nil
+		return
+	}
+	nf := C.size_t(len(fb))
+	fbs := (*C.sapi_FluxBiases)(C.malloc(C.sizeof_sapi_FluxBiases))
+	fbs.len = nf
+	elts := C.malloc(C.sizeof_double * nf)
+	ePtr := (*[1 << 30]C.double)(elts)[:nf:nf]
+	for i, f := range fb {
+		ePtr[i] = C.double(f)
+	}
+	fbs.elements = (*C.double)(elts)
+	qsp.flux_biases = fbs
+}
+
+// convertAnnealScheduleToGo converts the piecewise-linear anneal schedule
+// from Go to C.
+func (p *QuantumSolverParameters) convertAnnealScheduleToGo(qsp *C.sapi_QuantumSolverParameters) {
+	as := p.AnnealSchedule
+	if len(as) == 0 {
+		qsp.anneal_schedule = nil
+		return
+	}
+	np := C.size_t(len(as))
+	sched := (*C.sapi_AnnealSchedule)(C.malloc(C.sizeof_sapi_AnnealSchedule))
+	sched.len = np
+	elts := C.malloc(C.sizeof_sapi_AnnealSchedulePoint * np)
+	ePtr := (*[1 << 26]C.sapi_AnnealSchedulePoint)(elts)[:np:np]
+	for i, pt := range as {
+		ePtr[i].time = C.double(pt.Time)
+		ePtr[i].relative_current = C.double(pt.S)
+	}
+	sched.elements = (*C.sapi_AnnealSchedulePoint)(elts)
+	qsp.anneal_schedule = sched
 }
 
 // ToCSolverParameters converts a QuantumSolverParameters to a
 // sapi_SolverParameters.
 func (p *QuantumSolverParameters) ToCSolverParameters() *C.sapi_SolverParameters {
-	p.qsp.answer_mode = C.sapi_SolverParameterAnswerMode(p.AnswerMode)
+	qsp := C.SAPI_QUANTUM_SOLVER_DEFAULT_PARAMETERS
+	qsp.annealing_time = C.int(p.AnnealingTime)
+	qsp.answer_mode = C.sapi_SolverParameterAnswerMode(p.AnswerMode)
 	if p.AutoScale {
-		p.qsp.auto_scale = 1
+		qsp.auto_scale = 1
+	} else {
+		qsp.auto_scale = 0
+	}
+	qsp.beta = C.double(p.Beta)
+	p.convertChainsToGo(&qsp)
+	qsp.max_answers = C.int(p.MaxAnswers)
+	qsp.num_reads = C.int(p.NumReads)
+	qsp.num_spin_reversal_transforms = C.int(p.NumSpinReversals)
+	qsp.postprocess = C.sapi_Postprocess(p.Postprocess)
+	qsp.programming_thermalization = C.int(p.ProgTherm)
+	qsp.readout_thermalization = C.int(p.ReadoutTherm)
+	if p.ReduceCorrelation {
+		qsp.reduce_intersample_correlation = 1
 	} else {
-		p.qsp.auto_scale = 0
+		qsp.reduce_intersample_correlation = 0
+	}
+	p.convertAnnealOffsetsToGo(&qsp)
+	p.convertFluxBiasesToGo(&qsp)
+	p.convertAnnealScheduleToGo(&qsp)
+	return (*C.sapi_SolverParameters)(unsafe.Pointer(&qsp))
+}
+
+// freeCSolverParameters frees the C memory that sp.ToCSolverParameters
+// allocated in producing params.  It must not be called until SAPI is
+// finished with params, i.e., after the call that submits the problem
+// returns.
+func freeCSolverParameters(sp SolverParameters, params *C.sapi_SolverParameters) {
+	// Only quantum solver parameters contain separately allocated arrays.
+	if _, ok := sp.(*QuantumSolverParameters); !ok {
+		return
+	}
+	qsp := (*C.sapi_QuantumSolverParameters)(unsafe.Pointer(params))
+	if qsp.chains != nil {
+		C.free(unsafe.Pointer(qsp.chains.elements))
+		C.free(unsafe.Pointer(qsp.chains))
+		qsp.chains = nil
+	}
+	if qsp.anneal_offsets != nil {
+		C.free(unsafe.Pointer(qsp.anneal_offsets.elements))
+		C.free(unsafe.Pointer(qsp.anneal_offsets))
+		qsp.anneal_offsets = nil
+	}
+	if qsp.flux_biases != nil {
+		C.free(unsafe.Pointer(qsp.flux_biases.elements))
+		C.free(unsafe.Pointer(qsp.flux_biases))
+		qsp.flux_biases = nil
+	}
+	if qsp.anneal_schedule != nil {
+		C.free(unsafe.Pointer(qsp.anneal_schedule.elements))
+		C.free(unsafe.Pointer(qsp.anneal_schedule))
+		qsp.anneal_schedule = nil
 	}
-	p.qsp.beta = C.double(p.Beta)
-	p.convertChainsToGo()
-	p.qsp.max_answers = C.int(p.MaxAnswers)
-	p.qsp.num_reads = C.int(p.NumReads)
-	p.qsp.num_spin_reversal_transforms = C.int(p.NumSpinReversals)
-	p.qsp.postprocess = C.sapi_Postprocess(p.Postprocess)
-	p.qsp.programming_thermalization = C.int(p.ProgTherm)
-	p.qsp.readout_thermalization = C.int(p.ReadoutTherm)
-	p.convertAnnealOffsetsToGo()
-	return (*C.sapi_SolverParameters)(unsafe.Pointer(&p.qsp))
 }
@@ -12,15 +12,22 @@ import "C"
 
 import (
 	"runtime"
+	"sync"
 	"time"
 	"unsafe"
 )
 
 // A Solver represents a SAPI solver.
 type Solver struct {
-	solver *C.sapi_Solver // SAPI solver object
-	Name   string         // Solver name
-	Conn   *Connection    // Connection with which this solver is associated
+	solver    *C.sapi_Solver    // SAPI solver object
+	Name      string            // Solver name
+	Conn      *Connection       // Connection with which this solver is associated
+	Timeout   time.Duration     // Maximum time from submission to result or 0 for no limit (see SetClientTimeout)
+	Precision *PrecisionPolicy  // Rounding to apply to coefficients at submission time (nil = none)
+	props     *SolverProperties // Cached solver properties
+	propsMu   sync.Mutex        // Protects props
+	timing    *Timing           // Timing breakdown of the most recent QPU result
+	timingMu  sync.Mutex        // Protects timing
 }
 
 // Solver returns a solver associated with a given connection.
@@ -48,13 +55,25 @@ func (c *Connection) Solver(name string) (*Solver, error) {
 	return solverObj, nil
 }
 
+// Close immediately frees the C memory associated with a solver, including
+// its properties, rather than waiting for the garbage collector to do so.
+// The solver must not be used after it is closed.  SolverProperties returned
+// by Properties remain valid.
+func (s *Solver) Close() {
+	runtime.SetFinalizer(s, nil)
+	if s.solver != nil {
+		C.sapi_freeSolver(s.solver)
+		s.solver = nil
+	}
+}
+
 // An IsingRangeProperties indicates the acceptable ranges of h and J
 // coefficients.
 type IsingRangeProperties struct {
-	HMin float64
-	HMax float64
-	JMin float64
-	JMax float64
+	HMin float64 `json:"h_min"`
+	HMax float64 `json:"h_max"`
+	JMin float64 `json:"j_min"`
+	JMax float64 `json:"j_max"`
 }
 
 // toC converts an IsingRangeProperties to a C sapi_IsingRangeProperties.
@@ -69,9 +88,9 @@ func (irp *IsingRangeProperties) toC() *C.sapi_IsingRangeProperties {
 
 // A QuantumSolverProperties records the available qubits and couplers.
 type QuantumSolverProperties struct {
-	NumQubits int      // Total number of qubits, both working and non-working, in the processor
-	Qubits    []int    // Working qubit indices
-	Couplers  [][2]int // Working couplers in the processor
+	NumQubits int      `json:"num_qubits"` // Total number of qubits, both working and non-working, in the processor
+	Qubits    []int    `json:"qubits"`     // Working qubit indices
+	Couplers  [][2]int `json:"couplers"`   // Working couplers in the processor
 }
 
 // An AnnealOffsetRange indicates the minimum and maximum values a specific
@@ -81,19 +100,58 @@ type AnnealOffsetRange [2]float64
 // An AnnealOffsetProperties encapsulates properties of per-qubit annealing
 // offsets.
 type AnnealOffsetProperties struct {
-	Ranges   []AnnealOffsetRange // Ranges of valid anneal offset values, in normalized offset units, for each qubit
-	Step     float64             // Quantization step size of anneal offset values in normalized units
-	StepPhi0 float64             // Quantization step size in physical units (annealing flux bias units)
+	Ranges   []AnnealOffsetRange `json:"ranges"`    // Ranges of valid anneal offset values, in normalized offset units, for each qubit
+	Step     float64             `json:"step"`      // Quantization step size of anneal offset values in normalized units
+	StepPhi0 float64             `json:"step_phi0"` // Quantization step size in physical units (annealing flux bias units)
+}
+
+// An AnnealScheduleProperties encapsulates limits on the annealing time and
+// on custom anneal schedules.
+type AnnealScheduleProperties struct {
+	MaxPoints            int     `json:"max_points"`             // Maximum number of points in a piecewise-linear anneal schedule
+	MinAnnealingTime     float64 `json:"min_annealing_time"`     // Minimum annealing time in microseconds
+	MaxAnnealingTime     float64 `json:"max_annealing_time"`     // Maximum annealing time in microseconds
+	DefaultAnnealingTime float64 `json:"default_annealing_time"` // Annealing time in microseconds used when none is specified
+}
+
+// An HGainScheduleProperties encapsulates limits on time-dependent gain
+// applied to the h coefficients.
+type HGainScheduleProperties struct {
+	MaxPoints int     `json:"max_points"` // Maximum number of points in an h-gain schedule
+	HGainMin  float64 `json:"h_gain_min"` // Minimum h-gain value
+	HGainMax  float64 `json:"h_gain_max"` // Maximum h-gain value
 }
 
-// SolverProperties represents a SAPI solver's properties.
+// A VirtualGraphProperties encapsulates the extended coupling ranges that are
+// available to chains of qubits.
+type VirtualGraphProperties struct {
+	ExtendedJMin        float64 `json:"extended_j_min"`         // Minimum J value when using the extended J range
+	ExtendedJMax        float64 `json:"extended_j_max"`         // Maximum J value when using the extended J range
+	PerQubitCouplingMin float64 `json:"per_qubit_coupling_min"` // Minimum sum of the J values incident to a single qubit
+	PerQubitCouplingMax float64 `json:"per_qubit_coupling_max"` // Maximum sum of the J values incident to a single qubit
+}
+
+// A ProblemTimingProperties encapsulates the fixed costs of using a QPU.
+type ProblemTimingProperties struct {
+	ProgrammingTime      float64 `json:"programming_time"`        // Typical time in microseconds to program the QPU
+	ReadoutTimePerSample float64 `json:"readout_time_per_sample"` // Typical time in microseconds to read out one sample
+	DelayTimePerSample   float64 `json:"delay_time_per_sample"`   // Typical additional per-sample delay in microseconds
+}
+
+// SolverProperties represents a SAPI solver's properties.  It resides
+// entirely in Go memory.
 type SolverProperties struct {
-	props                 *C.sapi_SolverProperties // SAPI solver properties object
-	SupportedProblemTypes []string                 // "qubo" and/or "ising"
-	IsingRanges           *IsingRangeProperties    // Range of h and J coefficients
-	QuantumProps          *QuantumSolverProperties // Properties of the quantum solver
-	AnnealOffsets         *AnnealOffsetProperties  // Properties of the per-qubit annealing offsets
-	Parameters            []string                 // Valid solver parameter names, sorted in ascending order
+	SupportedProblemTypes   []string                  `json:"supported_problem_types"`            // "qubo" and/or "ising"
+	IsingRanges             *IsingRangeProperties     `json:"ising_ranges,omitempty"`             // Range of h and J coefficients
+	QuantumProps            *QuantumSolverProperties  `json:"quantum_solver,omitempty"`           // Properties of the quantum solver
+	AnnealOffsets           *AnnealOffsetProperties   `json:"anneal_offset,omitempty"`            // Properties of the per-qubit annealing offsets
+	AnnealSchedule          *AnnealScheduleProperties `json:"anneal_schedule,omitempty"`          // Limits on annealing times and schedules
+	HGainSchedule           *HGainScheduleProperties  `json:"h_gain_schedule,omitempty"`          // Limits on h-gain schedules
+	VirtualGraph            *VirtualGraphProperties   `json:"virtual_graph,omitempty"`            // Extended coupling ranges for chains
+	SupportedPostprocessing []Postprocessing          `json:"supported_postprocessing,omitempty"` // Types of server-side postprocessing the solver accepts
+	NumReadsRange           *[2]int                   `json:"num_reads_range,omitempty"`          // Minimum and maximum number of reads per submission, if advertised (libdwave_sapi advertises none)
+	ProblemTiming           *ProblemTimingProperties  `json:"problem_timing,omitempty"`           // Fixed costs of using the QPU, if advertised (libdwave_sapi advertises none)
+	Parameters              []string                  `json:"parameters"`                         // Valid solver parameter names, sorted in ascending order
 }
 
 // convertQSPs converts quantum solver properties from C to Go.
@@ -153,9 +211,76 @@ func convertAOPs(p *C.sapi_SolverProperties) *AnnealOffsetProperties {
 	}
 }
 
-// Properties returns the properties associated with a SAPI solver.
+// convertASPs converts anneal schedule properties from C to Go.
+func convertASPs(p *C.sapi_SolverProperties) *AnnealScheduleProperties {
+	as := p.anneal_schedule
+	if as == nil {
+		return nil
+	}
+	return &AnnealScheduleProperties{
+		MaxPoints:            int(as.max_points),
+		MinAnnealingTime:     float64(as.min_annealing_time),
+		MaxAnnealingTime:     float64(as.max_annealing_time),
+		DefaultAnnealingTime: float64(as.default_annealing_time),
+	}
+}
+
+// convertHGSPs converts h-gain schedule properties from C to Go.
+func convertHGSPs(p *C.sapi_SolverProperties) *HGainScheduleProperties {
+	hg := p.h_gain_schedule
+	if hg == nil {
+		return nil
+	}
+	return &HGainScheduleProperties{
+		MaxPoints: int(hg.max_points),
+		HGainMin:  float64(hg.h_gain_min),
+		HGainMax:  float64(hg.h_gain_max),
+	}
+}
+
+// convertVGPs converts virtual graph properties from C to Go.
+func convertVGPs(p *C.sapi_SolverProperties) *VirtualGraphProperties {
+	vg := p.virtual_graph
+	if vg == nil {
+		return nil
+	}
+	return &VirtualGraphProperties{
+		ExtendedJMin:        float64(vg.extended_j_min),
+		ExtendedJMax:        float64(vg.extended_j_max),
+		PerQubitCouplingMin: float64(vg.per_qubit_coupling_min),
+		PerQubitCouplingMax: float64(vg.per_qubit_coupling_max),
+	}
+}
+
+// supportedPostprocessing infers from a list of valid parameter names the
+// types of postprocessing a solver supports.
+func supportedPostprocessing(params []string) []Postprocessing {
+	for _, nm := range params {
+		if nm == "postprocess" {
+			return []Postprocessing{PostprocessNone, PostprocessSampling, PostprocessOptimization}
+		}
+	}
+	return nil
+}
+
+// Properties returns the properties associated with a SAPI solver.  The
+// properties are deep-copied from SAPI's memory into Go memory so they remain
+// valid even after the solver is closed or garbage-collected.  They are read
+// once and cached; use RefreshProperties to re-read them.  Callers must not
+// modify the returned properties.
 func (s *Solver) Properties() *SolverProperties {
-	// Acquire the solver's properties.
+	s.propsMu.Lock()
+	defer s.propsMu.Unlock()
+	if s.props == nil {
+		s.props = s.readProperties()
+	}
+	return s.props
+}
+
+// readProperties converts a solver's properties from C to Go.
+func (s *Solver) readProperties() *SolverProperties {
+	// Acquire the solver's properties.  These are owned by the solver
+	// and freed along with it.
 	p := C.sapi_getSolverProperties(s.solver)
 
 	// Convert the supported problem types from C to Go.
@@ -183,12 +308,15 @@ func (s *Solver) Properties() *SolverProperties {
 
 	// Create and initialize a Go solvers properties object and return it.
 	propObj := &SolverProperties{
-		props:                 p,
-		SupportedProblemTypes: spts,
-		IsingRanges:           ranges,
-		QuantumProps:          convertQSPs(p),
-		AnnealOffsets:         convertAOPs(p),
-		Parameters:            params,
+		SupportedProblemTypes:   spts,
+		IsingRanges:             ranges,
+		QuantumProps:            convertQSPs(p),
+		AnnealOffsets:           convertAOPs(p),
+		AnnealSchedule:          convertASPs(p),
+		HGainSchedule:           convertHGSPs(p),
+		VirtualGraph:            convertVGPs(p),
+		SupportedPostprocessing: supportedPostprocessing(params),
+		Parameters:              params,
 	}
 	return propObj
 }
@@ -221,8 +349,9 @@ type Timing struct {
 type IsingResult struct {
 	Solutions   [][]int8  // Solutions found (±1 or 3 for "unused")
 	Energies    []float64 // Energy of each solution
-	Occurrences []int     // Tally of occurrences of each solution
+	Occurrences []int     // Tally of occurrences of each solution (nil in raw answer mode)
 	Timing      Timing    // Solver timing breakdown
+	ScaleFactor float64   // Factor by which an AutoScaleComposite multiplied the coefficients (1 if not scaled)
 }
 
 // convertIsingResultToGo is a helper function for SolveIsing and SolveQubo
@@ -270,30 +399,143 @@ func convertIsingResultToGo(result *C.sapi_IsingResult) (IsingResult, error) {
 		Energies:    energies,
 		Occurrences: occurs,
 		Timing:      times,
+		ScaleFactor: 1,
 	}
 	return ir, nil
 }
 
-// SolveIsing solves an Ising-model problem.
+// SetClientTimeout limits the total time, measured on the client from
+// submission, that a problem may take to produce a result.  A problem that
+// exceeds the limit is canceled and reported as an error.  The limit applies
+// to SolveIsing, SolveQubo, their Context variants, SubmittedProblem.Result
+// (which waits for completion until the limit is reached), and SolveMany.  A
+// limit of 0 imposes no limit.  SetClientTimeout is equivalent to assigning
+// to the Timeout field.
+func (s *Solver) SetClientTimeout(d time.Duration) {
+	s.Timeout = d
+}
+
+// timeoutError returns the error reported when a problem exceeds the
+// solver's timeout.
+func (s *Solver) timeoutError() error {
+	return newErrorf(C.SAPI_ERR_PROBLEM_CANCELLED, "Problem canceled after exceeding the %v timeout", s.Timeout)
+}
+
+// solveWithTimeout is a helper function for SolveIsing and SolveQubo that
+// submits a problem asynchronously and waits for its result.  The solver's
+// timeout is enforced by SubmittedProblem.Result, which cancels the problem
+// if it fails to complete in time.
+func (s *Solver) solveWithTimeout(submit func(Problem, SolverParameters) (*SubmittedProblem, error),
+	p Problem, sp SolverParameters) (IsingResult, error) {
+	sub, err := submit(p, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	return sub.Result()
+}
+
+// SolveIsing solves an Ising-model problem.  If the solver's Timeout field is
+// nonzero, the problem is canceled if it fails to complete in time.  If the
+// solver's Precision field is non-nil, coefficients are rounded accordingly.
 func (s *Solver) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
-	prob := p.toC()
+	if s.Timeout > 0 {
+		return s.solveWithTimeout(s.AsyncSolveIsing, p, sp)
+	}
+	prob := s.Precision.Apply(p).toC()
 	params := sp.ToCSolverParameters()
+	defer freeCSolverParameters(sp, params)
 	var result *C.sapi_IsingResult
 	cErr := make([]C.char, C.SAPI_ERROR_MESSAGE_MAX_SIZE)
+	s.emit(EventSubmitted, nil, nil)
 	if ret := C.sapi_solveIsing(s.solver, prob, params, &result, &cErr[0]); ret != C.SAPI_OK {
-		return IsingResult{}, newErrorf(ret, "%s", C.GoString(&cErr[0]))
+		err := newErrorf(ret, "%s", C.GoString(&cErr[0]))
+		s.emit(EventCompleted, nil, err)
+		return IsingResult{}, err
 	}
-	return convertIsingResultToGo(result)
+	s.emit(EventCompleted, nil, nil)
+	return s.recordTiming(convertIsingResultToGo(result))
 }
 
-// SolveQubo solves a QUBO problem.
+// SolveQubo solves a QUBO problem.  If the solver's Timeout field is nonzero,
+// the problem is canceled if it fails to complete in time.  If the solver's
+// Precision field is non-nil, coefficients are rounded accordingly.
 func (s *Solver) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
-	prob := p.toC()
+	if s.Timeout > 0 {
+		return s.solveWithTimeout(s.AsyncSolveQubo, p, sp)
+	}
+	prob := s.Precision.Apply(p).toC()
 	params := sp.ToCSolverParameters()
+	defer freeCSolverParameters(sp, params)
 	var result *C.sapi_IsingResult
 	cErr := make([]C.char, C.SAPI_ERROR_MESSAGE_MAX_SIZE)
+	s.emit(EventSubmitted, nil, nil)
 	if ret := C.sapi_solveQubo(s.solver, prob, params, &result, &cErr[0]); ret != C.SAPI_OK {
-		return IsingResult{}, newErrorf(ret, "%s", C.GoString(&cErr[0]))
+		err := newErrorf(ret, "%s", C.GoString(&cErr[0]))
+		s.emit(EventCompleted, nil, err)
+		return IsingResult{}, err
+	}
+	s.emit(EventCompleted, nil, nil)
+	return s.recordTiming(convertIsingResultToGo(result))
+}
+
+// RefreshProperties re-reads a solver's properties from its connection and
+// replaces the cached copy returned by Properties.  If the solver's working
+// graph (its working qubits or couplers) has changed, as happens when a
+// quantum solver is recalibrated, RefreshProperties reports an
+// EventGraphChanged to the connection's event handlers so that callers can
+// invalidate any cached embeddings.
+func (s *Solver) RefreshProperties() (*SolverProperties, error) {
+	// Read the properties through a new solver handle, as SAPI reads a
+	// solver's properties only once.
+	fresh, err := s.Conn.Solver(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	props := fresh.readProperties()
+	fresh.Close()
+
+	// Replace the cached properties.
+	s.propsMu.Lock()
+	old := s.props
+	s.props = props
+	s.propsMu.Unlock()
+
+	// Report a change in the working graph.
+	if old != nil && workingGraphChanged(old.QuantumProps, props.QuantumProps) {
+		s.Conn.emit(Event{Kind: EventGraphChanged, Solver: s})
+	}
+	return props, nil
+}
+
+// workingGraphChanged says whether two sets of quantum solver properties
+// describe different working graphs.
+func workingGraphChanged(a, b *QuantumSolverProperties) bool {
+	switch {
+	case a == nil && b == nil:
+		return false
+	case a == nil || b == nil:
+		return true
+	}
+	if len(a.Qubits) != len(b.Qubits) || len(a.Couplers) != len(b.Couplers) {
+		return true
+	}
+	qs := make(map[int]bool, len(a.Qubits))
+	for _, q := range a.Qubits {
+		qs[q] = true
+	}
+	for _, q := range b.Qubits {
+		if !qs[q] {
+			return true
+		}
+	}
+	cs := make(map[[2]int]bool, len(a.Couplers))
+	for _, c := range a.Couplers {
+		cs[c] = true
+	}
+	for _, c := range b.Couplers {
+		if !cs[c] {
+			return true
+		}
 	}
-	return convertIsingResultToGo(result)
+	return false
 }
@@ -0,0 +1,210 @@
+// This file provides a solver wrapper that applies spin-reversal (gauge)
+// transformations on the client side.
+
+package sapi
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// A SpinReversalComposite wraps a sampler so that each problem is solved
+// under several random spin-reversal transformations (gauges).  Each gauge
+// negates a random subset of the variables, which leaves the problem's energy
+// landscape unchanged but averages out biases in the underlying hardware.
+// This is useful for solvers that lack a native num_spin_reversal_transforms
+// parameter.
+type SpinReversalComposite struct {
+	Sampler   Sampler    // Underlying sampler
+	NumGauges int        // Number of gauges across which to split the reads
+	Rand      *rand.Rand // Random-number generator used to select gauges
+}
+
+// NewSpinReversalComposite wraps a sampler with a SpinReversalComposite that
+// uses a given number of gauges.
+func NewSpinReversalComposite(s Sampler, numGauges int) *SpinReversalComposite {
+	return &SpinReversalComposite{
+		Sampler:   s,
+		NumGauges: numGauges,
+		Rand:      newRand(),
+	}
+}
+
+// GaugeTransform applies a spin-reversal transformation (gauge) to an
+// Ising-model problem.  flips[i] indicates whether variable i is negated;
+// variables beyond the end of flips are not negated.  The transformed problem
+// has the same energy landscape as the original, and ApplyGaugeToSolutions
+// maps its solutions back to solutions of the original problem.
+func (p Problem) GaugeTransform(flips []bool) Problem {
+	flipped := func(v int) bool { return v < len(flips) && flips[v] }
+	gp := make(Problem, len(p))
+	for i, pe := range p {
+		gp[i] = pe
+		if flipped(pe.I) != (pe.I != pe.J && flipped(pe.J)) {
+			gp[i].Value = -gp[i].Value
+		}
+	}
+	return gp
+}
+
+// ApplyGaugeToSolutions negates, in place, each variable i of each solution
+// for which flips[i] is true.  Applied to solutions of a problem produced by
+// GaugeTransform, it yields solutions to the original problem.  Values other
+// than ±1 (e.g., 3 for "unused") are left untouched.
+func ApplyGaugeToSolutions(solns [][]int8, flips []bool) {
+	for _, soln := range solns {
+		for i := 0; i < len(soln) && i < len(flips); i++ {
+			if flips[i] && (soln[i] == 1 || soln[i] == -1) {
+				soln[i] = -soln[i]
+			}
+		}
+	}
+}
+
+// withNumReads returns a copy of a set of solver parameters with the number
+// of reads replaced.  Solver parameters without a number of reads are
+// returned unmodified.
+func withNumReads(sp SolverParameters, n int) SolverParameters {
+	switch c := sp.Clone().(type) {
+	case *SwOptimizeSolverParameters:
+		c.NumReads = n
+		return c
+	case *SwSampleSolverParameters:
+		c.NumReads = n
+		return c
+	case *QuantumSolverParameters:
+		c.NumReads = n
+		return c
+	default:
+		return sp
+	}
+}
+
+// numReads returns the number of reads specified by a set of solver
+// parameters or 0 if the parameters do not specify a number of reads.
+func numReads(sp SolverParameters) int {
+	switch sp := sp.(type) {
+	case *SwOptimizeSolverParameters:
+		return sp.NumReads
+	case *SwSampleSolverParameters:
+		return sp.NumReads
+	case *QuantumSolverParameters:
+		return sp.NumReads
+	default:
+		return 0
+	}
+}
+
+// SolveIsing solves an Ising-model problem under NumGauges random gauges,
+// dividing the requested number of reads among them, and merges the
+// un-transformed solutions in order of increasing energy.
+func (sr *SpinReversalComposite) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	// Determine the number of reads to take in each gauge.
+	ng := sr.NumGauges
+	if ng < 1 {
+		ng = 1
+	}
+	nr := numReads(sp)
+	if nr > 0 && nr < ng {
+		ng = nr
+	}
+	nv := 0
+	for _, pe := range p {
+		if pe.I+1 > nv {
+			nv = pe.I + 1
+		}
+		if pe.J+1 > nv {
+			nv = pe.J + 1
+		}
+	}
+
+	// Solve the problem in each gauge.
+	var merged IsingResult
+	keepOccurs := true
+	for g := 0; g < ng; g++ {
+		// Transform the problem.
+		flips := make([]bool, nv)
+		for i := range flips {
+			flips[i] = sr.Rand.Intn(2) == 1
+		}
+		gp := p.GaugeTransform(flips)
+
+		// Solve the transformed problem.
+		gsp := sp
+		if nr > 0 {
+			n := nr / ng
+			if g < nr%ng {
+				n++
+			}
+			gsp = withNumReads(sp, n)
+		}
+		res, err := sr.Sampler.SolveIsing(gp, gsp)
+		if err != nil {
+			return IsingResult{}, err
+		}
+
+		// Un-transform the solutions.
+		ApplyGaugeToSolutions(res.Solutions, flips)
+		merged.Solutions = append(merged.Solutions, res.Solutions...)
+		merged.Energies = append(merged.Energies, res.Energies...)
+		if res.Occurrences == nil {
+			keepOccurs = false
+		}
+		merged.Occurrences = append(merged.Occurrences, res.Occurrences...)
+		merged.Timing = res.Timing
+		merged.ScaleFactor = res.ScaleFactor
+	}
+	if !keepOccurs {
+		merged.Occurrences = nil
+	}
+
+	// Sort the merged solutions by increasing energy.
+	order := make([]int, len(merged.Solutions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return merged.Energies[order[i]] < merged.Energies[order[j]]
+	})
+	sorted := IsingResult{
+		Solutions:   make([][]int8, len(order)),
+		Energies:    make([]float64, len(order)),
+		Timing:      merged.Timing,
+		ScaleFactor: merged.ScaleFactor,
+	}
+	if merged.Occurrences != nil {
+		sorted.Occurrences = make([]int, len(order))
+	}
+	for i, o := range order {
+		sorted.Solutions[i] = merged.Solutions[o]
+		sorted.Energies[i] = merged.Energies[o]
+		if sorted.Occurrences != nil {
+			sorted.Occurrences[i] = merged.Occurrences[o]
+		}
+	}
+	return sorted, nil
+}
+
+// SolveQubo converts a QUBO problem to an Ising-model problem, solves it with
+// SolveIsing, and converts the solutions back.
+func (sr *SpinReversalComposite) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	ip, ofs := p.ToIsing()
+	res, err := sr.SolveIsing(ip, sp)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	spinsToBits(res.Solutions)
+	res.AddOffset(ofs)
+	return res, nil
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying sampler.
+func (sr *SpinReversalComposite) NewSolverParameters() SolverParameters {
+	return sr.Sampler.NewSolverParameters()
+}
+
+// Properties returns the underlying sampler's properties.
+func (sr *SpinReversalComposite) Properties() *SolverProperties {
+	return sr.Sampler.Properties()
+}
@@ -0,0 +1,273 @@
+// This file provides detection of a problem's variable-permutation
+// symmetries.
+
+package sapi
+
+import (
+	"sort"
+	"strconv"
+)
+
+// symmetryIndex is a precomputed view of a problem used when searching for
+// automorphisms.
+type symmetryIndex struct {
+	vars  []int              // All variables, in search order
+	h     map[int]float64    // Linear coefficient of each variable
+	J     map[[2]int]float64 // Nonzero quadratic coefficient of each {I, J} with I < J
+	color map[int]string     // Invariant that any automorphism must preserve
+	adj   map[int][]int      // Neighbors of each variable via nonzero couplers
+	cands map[string][]int   // Variables sharing each color, in increasing order
+}
+
+// newSymmetryIndex prepares a problem for automorphism search.
+func newSymmetryIndex(p Problem) *symmetryIndex {
+	// Merge duplicate entries and discard zeros, but remember every variable.
+	si := &symmetryIndex{
+		h:     make(map[int]float64),
+		J:     make(map[[2]int]float64),
+		color: make(map[int]string),
+		adj:   make(map[int][]int),
+		cands: make(map[string][]int),
+	}
+	seen := make(map[int]bool)
+	for _, pe := range p {
+		seen[pe.I] = true
+		seen[pe.J] = true
+	}
+	cp := p.Canonicalize()
+	cp.CanonicalizeInPlace(true)
+	for _, pe := range cp {
+		if pe.I == pe.J {
+			si.h[pe.I] = pe.Value
+			continue
+		}
+		si.J[[2]int{pe.I, pe.J}] = pe.Value
+		si.adj[pe.I] = append(si.adj[pe.I], pe.J)
+		si.adj[pe.J] = append(si.adj[pe.J], pe.I)
+	}
+
+	// Color each variable by its linear coefficient and the sorted multiset
+	// of its coupler values.
+	all := make([]int, 0, len(seen))
+	for v := range seen {
+		all = append(all, v)
+	}
+	sort.Ints(all)
+	for _, v := range all {
+		js := make([]float64, 0, len(si.adj[v]))
+		for _, u := range si.adj[v] {
+			js = append(js, si.coupling(u, v))
+		}
+		sort.Float64s(js)
+		c := formatFloats(append([]float64{si.h[v]}, js...))
+		si.color[v] = c
+		si.cands[c] = append(si.cands[c], v)
+	}
+
+	// Order the search breadth-first within each connected component so
+	// that each variable after the first in a component is constrained by
+	// an already mapped neighbor.
+	placed := make(map[int]bool, len(all))
+	for _, v := range all {
+		if placed[v] {
+			continue
+		}
+		placed[v] = true
+		queue := []int{v}
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			si.vars = append(si.vars, u)
+			ns := append([]int(nil), si.adj[u]...)
+			sort.Ints(ns)
+			for _, n := range ns {
+				if !placed[n] {
+					placed[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+	}
+	return si
+}
+
+// formatFloats renders a list of floats as a string suitable for use as a
+// map key.
+func formatFloats(fs []float64) string {
+	b := make([]byte, 0, len(fs)*8)
+	for _, f := range fs {
+		b = strconv.AppendFloat(b, f, 'g', -1, 64)
+		b = append(b, ',')
+	}
+	return string(b)
+}
+
+// coupling returns the quadratic coefficient between variables u and v, or
+// zero if they are not coupled.
+func (si *symmetryIndex) coupling(u, v int) float64 {
+	if u > v {
+		u, v = v, u
+	}
+	return si.J[[2]int{u, v}]
+}
+
+// search invokes yield on each automorphism found by backtracking.  It stops
+// when yield returns false.
+func (si *symmetryIndex) search(yield func(map[int]int) bool) {
+	perm := make(map[int]int, len(si.vars))
+	used := make(map[int]bool, len(si.vars))
+	var extend func(k int) bool
+	extend = func(k int) bool {
+		if k == len(si.vars) {
+			cp := make(map[int]int, len(perm))
+			for v, w := range perm {
+				cp[v] = w
+			}
+			return yield(cp)
+		}
+		v := si.vars[k]
+		for _, w := range si.cands[si.color[v]] {
+			if used[w] || !si.consistent(perm, v, w) {
+				continue
+			}
+			perm[v] = w
+			used[w] = true
+			if !extend(k + 1) {
+				return false
+			}
+			delete(perm, v)
+			used[w] = false
+		}
+		return true
+	}
+	extend(0)
+}
+
+// consistent reports whether mapping v to w preserves every coupling between
+// v and an already mapped variable.
+func (si *symmetryIndex) consistent(perm map[int]int, v, w int) bool {
+	for u, x := range perm {
+		if si.coupling(u, v) != si.coupling(x, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Automorphisms returns permutations of a problem's variables that leave
+// every linear and quadratic coefficient unchanged.  Each permutation maps
+// every variable in the problem to its image.  The identity permutation is
+// always returned first.  At most limit permutations are returned; a limit
+// of zero or less means no limit, but note that highly symmetric problems can
+// have a factorial number of automorphisms.  Zero-valued entries and the
+// order of duplicate entries do not affect the result.
+func (p Problem) Automorphisms(limit int) []map[int]int {
+	var perms []map[int]int
+	newSymmetryIndex(p).search(func(perm map[int]int) bool {
+		perms = append(perms, perm)
+		return limit <= 0 || len(perms) < limit
+	})
+	return perms
+}
+
+// IsAutomorphism reports whether a permutation of a problem's variables
+// leaves every coefficient unchanged.  Variables that do not appear in perm
+// are mapped to themselves.
+func (p Problem) IsAutomorphism(perm map[int]int) bool {
+	// Ensure that perm is a bijection.
+	images := make(map[int]bool, len(perm))
+	for _, w := range perm {
+		if images[w] {
+			return false
+		}
+		images[w] = true
+	}
+	for v := range images {
+		if _, ok := perm[v]; !ok {
+			return false
+		}
+	}
+
+	// Compare the canonical forms of the original and permuted problems.
+	a := p.Canonicalize()
+	a.CanonicalizeInPlace(true)
+	b := p.Relabel(perm)
+	b.CanonicalizeInPlace(true)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SymmetryOrbits partitions a problem's variables into orbits, sets of
+// variables that some automorphism maps onto each other.  Variables in the
+// same orbit are interchangeable, so, for example, embeddings that differ
+// only by a permutation within orbits are equivalent.  Each orbit is sorted,
+// and orbits are returned in order of their smallest variable.  As in
+// Automorphisms, limit bounds the number of automorphisms examined; if the
+// bound is reached, some orbits may be reported as split.
+func (p Problem) SymmetryOrbits(limit int) [][]int {
+	// Union each variable with its images.
+	si := newSymmetryIndex(p)
+	parent := make(map[int]int, len(si.vars))
+	var find func(v int) int
+	find = func(v int) int {
+		if _, ok := parent[v]; !ok {
+			parent[v] = v
+		}
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+	n := 0
+	si.search(func(perm map[int]int) bool {
+		for v, w := range perm {
+			rv, rw := find(v), find(w)
+			if rv < rw {
+				parent[rw] = rv
+			} else if rw < rv {
+				parent[rv] = rw
+			}
+		}
+		n++
+		return limit <= 0 || n < limit
+	})
+
+	// Group variables by their root.
+	groups := make(map[int][]int)
+	for _, v := range si.vars {
+		r := find(v)
+		groups[r] = append(groups[r], v)
+	}
+	roots := make([]int, 0, len(groups))
+	for r := range groups {
+		roots = append(roots, r)
+	}
+	sort.Ints(roots)
+	orbits := make([][]int, len(roots))
+	for i, r := range roots {
+		sort.Ints(groups[r])
+		orbits[i] = groups[r]
+	}
+	return orbits
+}
+
+// HasFlipSymmetry reports whether an Ising-model problem's energy is
+// unchanged by negating every spin, which is the case when all linear
+// coefficients are zero.  The solutions of such a problem come in pairs of
+// equal energy, which should be taken into account when reporting ground-state
+// degeneracy.
+func (p Problem) HasFlipSymmetry() bool {
+	for _, pe := range p.Canonicalize() {
+		if pe.I == pe.J && pe.Value != 0.0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+// This file provides a solver wrapper that replicates a small problem across
+// disjoint regions of the hardware graph.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"sort"
+)
+
+// A TilingComposite wraps a solver so that a single submission solves many
+// copies of a small logical problem, each embedded in a disjoint region of
+// the hardware graph.  The solutions from every copy are merged, yielding
+// many more samples per solver call than submitting the problem alone.
+// Variables must be numbered from 0 to N-1.
+type TilingComposite struct {
+	Solver        *Solver                  // Underlying solver
+	FindParams    *FindEmbeddingParameters // Parameters for FindEmbedding
+	MaxTiles      int                      // Maximum number of copies to embed (0 = as many as fit)
+	ChainStrength float64                  // J value applied to couplers within a chain
+	BrokenChains  BrokenChains             // How to resolve chains whose qubits disagree
+	adj           Problem                  // Hardware adjacency
+	ranges        IsingRangeProperties     // Coefficient ranges for the embedded problem
+}
+
+// NewTilingComposite wraps a solver with a TilingComposite.  The chain
+// strength defaults to the most negative J value the solver accepts, and
+// broken chains default to BrokenChainsMinimizeEnergy.
+func NewTilingComposite(s *Solver) (*TilingComposite, error) {
+	adj, err := s.HardwareAdjacency()
+	if err != nil {
+		return nil, err
+	}
+	ranges := IsingRangeProperties{HMin: -1, HMax: 1, JMin: -1, JMax: 1}
+	if ir := s.Properties().IsingRanges; ir != nil {
+		ranges = *ir
+	}
+	fep := NewFindEmbeddingParameters()
+	fep.Verbose = false
+	return &TilingComposite{
+		Solver:        s,
+		FindParams:    fep,
+		ChainStrength: ranges.JMin,
+		BrokenChains:  BrokenChainsMinimizeEnergy,
+		adj:           adj,
+		ranges:        ranges,
+	}, nil
+}
+
+// Tile finds embeddings of a logical problem in pairwise disjoint sets of
+// qubits.  Each embedding is found with FindEmbedding after removing from the
+// hardware graph all qubits used by previous embeddings.  Tile fails only if
+// not even one embedding can be found.
+func (tc *TilingComposite) Tile(p Problem) ([]Embeddings, error) {
+	used := make(map[int]bool)
+	var tiles []Embeddings
+	for tc.MaxTiles == 0 || len(tiles) < tc.MaxTiles {
+		// Remove all used qubits from the hardware graph.
+		adj := make(Problem, 0, len(tc.adj))
+		for _, pe := range tc.adj {
+			if !used[pe.I] && !used[pe.J] {
+				adj = append(adj, pe)
+			}
+		}
+		if len(adj) == 0 {
+			break
+		}
+
+		// Embed another copy of the problem.
+		emb, err := FindEmbedding(p, adj, tc.FindParams)
+		if err != nil {
+			if len(tiles) == 0 {
+				return nil, err
+			}
+			break
+		}
+		for q, v := range emb {
+			if v >= 0 {
+				used[q] = true
+			}
+		}
+		tiles = append(tiles, emb)
+	}
+	if len(tiles) == 0 {
+		return nil, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Failed to find an embedding")
+	}
+	return tiles, nil
+}
+
+// solve is the common code for SolveIsing and SolveQubo.
+func (tc *TilingComposite) solve(p Problem, sp SolverParameters, qubo bool) (IsingResult, error) {
+	// Tile the problem, and combine all tiles into a single problem and
+	// embedding, offsetting the variable numbers of each tile.
+	tiles, err := tc.Tile(p)
+	if err != nil {
+		return IsingResult{}, err
+	}
+	nv := p.countQubits()
+	nq := 0
+	for _, emb := range tiles {
+		if len(emb) > nq {
+			nq = len(emb)
+		}
+	}
+	allEmb := make(Embeddings, nq)
+	for q := range allEmb {
+		allEmb[q] = -1
+	}
+	allProb := make(Problem, 0, len(p)*len(tiles))
+	for t, emb := range tiles {
+		for q, v := range emb {
+			if v >= 0 {
+				allEmb[q] = v + t*nv
+			}
+		}
+		for _, pe := range p {
+			allProb = append(allProb, ProblemEntry{I: pe.I + t*nv, J: pe.J + t*nv, Value: pe.Value})
+		}
+	}
+
+	// Solve the combined problem.
+	var res IsingResult
+	if qubo {
+		res, err = solveEmbeddedQubo(tc.Solver, allProb, allEmb, tc.adj, tc.ranges,
+			tc.ChainStrength, tc.BrokenChains, sp)
+	} else {
+		res, err = solveEmbeddedIsing(tc.Solver, allProb, allEmb, tc.adj, tc.ranges,
+			tc.ChainStrength, tc.BrokenChains, sp)
+	}
+	if err != nil {
+		return IsingResult{}, err
+	}
+
+	// Split each combined solution into one solution per tile, and sort
+	// the merged solutions by increasing energy.
+	type sample struct {
+		soln   []int8
+		energy float64
+		occurs int
+	}
+	samples := make([]sample, 0, len(res.Solutions)*len(tiles))
+	for i, soln := range res.Solutions {
+		for t := range tiles {
+			s := sample{soln: soln[t*nv : (t+1)*nv : (t+1)*nv]}
+			s.energy = isingEnergy(p, s.soln)
+			if res.Occurrences != nil {
+				s.occurs = res.Occurrences[i]
+			}
+			samples = append(samples, s)
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].energy < samples[j].energy })
+	merged := IsingResult{
+		Solutions:   make([][]int8, len(samples)),
+		Energies:    make([]float64, len(samples)),
+		Timing:      res.Timing,
+		ScaleFactor: res.ScaleFactor,
+	}
+	if res.Occurrences != nil {
+		merged.Occurrences = make([]int, len(samples))
+	}
+	for i, s := range samples {
+		merged.Solutions[i] = s.soln
+		merged.Energies[i] = s.energy
+		if merged.Occurrences != nil {
+			merged.Occurrences[i] = s.occurs
+		}
+	}
+	return merged, nil
+}
+
+// SolveIsing solves many copies of an Ising-model problem in a single
+// submission.  Note that if BrokenChains is BrokenChainsDiscard, a broken
+// chain in any one copy discards the corresponding solutions of all copies.
+func (tc *TilingComposite) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	return tc.solve(p, sp, false)
+}
+
+// SolveQubo solves many copies of a QUBO problem in a single submission.
+func (tc *TilingComposite) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	return tc.solve(p, sp, true)
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying solver.
+func (tc *TilingComposite) NewSolverParameters() SolverParameters {
+	return tc.Solver.NewSolverParameters()
+}
+
+// Properties returns the underlying solver's properties but with no
+// QuantumProps, as a TilingComposite accepts problems of any structure that
+// can be embedded.
+func (tc *TilingComposite) Properties() *SolverProperties {
+	props := *tc.Solver.Properties()
+	props.QuantumProps = nil
+	return &props
+}
@@ -0,0 +1,105 @@
+// This file provides a typed description of a hardware topology that can be
+// passed consistently to the functions that need one.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+// A TopologyFamily names a family of hardware graphs.
+type TopologyFamily string
+
+// These are the supported topology families.
+const (
+	ChimeraFamily TopologyFamily = "chimera" // M×N grid of complete bipartite K_{L,L} unit cells
+)
+
+// A TopologySpec fully specifies a hardware graph.
+type TopologySpec struct {
+	Family TopologyFamily `json:"family"` // Family of graphs
+	M      int            `json:"m"`      // Number of unit-cell rows
+	N      int            `json:"n"`      // Number of unit-cell columns
+	L      int            `json:"l"`      // Number of qubits per side of a unit cell
+}
+
+// Chimera returns a TopologySpec for an M×N×L Chimera graph.
+func Chimera(m, n, l int) TopologySpec {
+	return TopologySpec{Family: ChimeraFamily, M: m, N: n, L: l}
+}
+
+// Validate ensures that a TopologySpec describes a supported, nonempty graph.
+func (ts TopologySpec) Validate() error {
+	if ts.Family != ChimeraFamily {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Unsupported topology family %q", ts.Family)
+	}
+	if ts.M < 1 || ts.N < 1 || ts.L < 1 {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Invalid %s dimensions {%d, %d, %d}", ts.Family, ts.M, ts.N, ts.L)
+	}
+	return nil
+}
+
+// NumQubits returns the number of qubits in a topology.
+func (ts TopologySpec) NumQubits() int {
+	return 2 * ts.M * ts.N * ts.L
+}
+
+// Adjacency constructs the adjacency matrix for a topology.
+func (ts TopologySpec) Adjacency() (Problem, error) {
+	if err := ts.Validate(); err != nil {
+		return nil, err
+	}
+	return ChimeraAdjacency(ts.M, ts.N, ts.L)
+}
+
+// A ChimeraCoord locates a qubit within a Chimera graph.
+type ChimeraCoord struct {
+	Row   int // Unit-cell row
+	Col   int // Unit-cell column
+	Side  int // 0 for a vertically oriented qubit, 1 for a horizontally oriented qubit
+	Index int // Index of the qubit within its side of the unit cell
+}
+
+// Coord returns the coordinates of a qubit in a topology.  It fails if the
+// qubit does not exist.
+func (ts TopologySpec) Coord(q int) (ChimeraCoord, error) {
+	if err := ts.Validate(); err != nil {
+		return ChimeraCoord{}, err
+	}
+	if q < 0 || q >= ts.NumQubits() {
+		return ChimeraCoord{}, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Qubit %d does not exist in a {%d, %d, %d} %s graph", q, ts.M, ts.N, ts.L, ts.Family)
+	}
+	return ChimeraCoord{
+		Row:   q / (2 * ts.N * ts.L),
+		Col:   (q / (2 * ts.L)) % ts.N,
+		Side:  (q / ts.L) % 2,
+		Index: q % ts.L,
+	}, nil
+}
+
+// Qubit is the inverse of Coord: it returns the qubit at a given set of
+// coordinates.
+func (ts TopologySpec) Qubit(c ChimeraCoord) int {
+	return ((c.Row*ts.N+c.Col)*2+c.Side)*ts.L + c.Index
+}
+
+// A Point is a location on the chip, measured in unit cells from the
+// chip's top-left corner.
+type Point struct {
+	X float64 // Horizontal position
+	Y float64 // Vertical position
+}
+
+// Point returns a representative location for a qubit at a given set of
+// coordinates.  Vertically oriented qubits are spread across the left half
+// of the cell and horizontally oriented qubits across the top half, so that
+// no two qubits share a location.
+func (ts TopologySpec) Point(c ChimeraCoord) Point {
+	offset := (float64(c.Index) + 0.5) / float64(2*ts.L)
+	if c.Side == 0 {
+		return Point{X: float64(c.Col) + offset, Y: float64(c.Row) + 0.5}
+	}
+	return Point{X: float64(c.Col) + 0.5, Y: float64(c.Row) + offset}
+}
@@ -0,0 +1,76 @@
+// This file provides a means of unembedding answers that arrive in chunks.
+
+package sapi
+
+// An IncrementalUnembedder maps chunks of raw physical answers back to logical
+// variables as they arrive and maintains running aggregates over all chunks
+// seen so far.  Only one chunk needs to be held in memory at a time.
+type IncrementalUnembedder struct {
+	emb     Embeddings   // Mapping from physical qubits to logical variables
+	broken  BrokenChains // How to resolve chains whose qubits disagree
+	prob    Problem      // Logical Ising-model problem
+	stats   UnembedStats // Running aggregates
+	spinSum []float64    // Per-variable sum of spins
+}
+
+// UnembedStats are running aggregates maintained by an IncrementalUnembedder.
+type UnembedStats struct {
+	PhysicalSamples int       // Number of physical answers processed
+	LogicalSamples  int       // Number of logical solutions produced (fewer if chains were discarded)
+	BestEnergy      float64   // Lowest logical energy seen
+	Best            []int8    // A logical solution with the lowest energy seen
+	Magnetizations  []float64 // Mean value of each logical variable
+}
+
+// NewIncrementalUnembedder prepares to unembed answers to an embedded
+// Ising-model problem.  The arguments are as for UnembedAnswer.
+func NewIncrementalUnembedder(emb Embeddings, broken BrokenChains, prob Problem) *IncrementalUnembedder {
+	return &IncrementalUnembedder{
+		emb:    emb,
+		broken: broken,
+		prob:   prob,
+	}
+}
+
+// Add unembeds one chunk of raw physical answers, updates the running
+// aggregates, and returns the chunk's logical solutions.
+func (iu *IncrementalUnembedder) Add(chunk [][]int8) ([][]int8, error) {
+	if len(chunk) == 0 {
+		return nil, nil
+	}
+	solns, err := UnembedAnswer(chunk, iu.emb, iu.broken, iu.prob)
+	if err != nil {
+		return nil, err
+	}
+	st := &iu.stats
+	st.PhysicalSamples += len(chunk)
+	for _, soln := range solns {
+		e := isingEnergy(iu.prob, soln)
+		if st.LogicalSamples == 0 || e < st.BestEnergy {
+			st.BestEnergy = e
+			st.Best = soln
+		}
+		st.LogicalSamples++
+		if iu.spinSum == nil {
+			iu.spinSum = make([]float64, len(soln))
+		}
+		for v, s := range soln {
+			if v < len(iu.spinSum) && (s == 1 || s == -1) {
+				iu.spinSum[v] += float64(s)
+			}
+		}
+	}
+	return solns, nil
+}
+
+// Stats returns the running aggregates over all chunks added so far.
+func (iu *IncrementalUnembedder) Stats() UnembedStats {
+	st := iu.stats
+	if st.LogicalSamples > 0 {
+		st.Magnetizations = make([]float64, len(iu.spinSum))
+		for v, s := range iu.spinSum {
+			st.Magnetizations[v] = s / float64(st.LogicalSamples)
+		}
+	}
+	return st
+}
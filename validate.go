@@ -0,0 +1,377 @@
+// This file provides a means of checking a problem against a solver's
+// capabilities before submitting it.
+
+package sapi
+
+// #cgo LDFLAGS: -ldwave_sapi
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <dwave_sapi.h>
+import "C"
+
+import (
+	"strings"
+)
+
+// A ValidationError lists all of the reasons a problem was found to be
+// invalid for a given solver.
+type ValidationError []error
+
+// Error returns all of a ValidationError's reasons, one per line.
+func (ve ValidationError) Error() string {
+	msgs := make([]string, len(ve))
+	for i, e := range ve {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// setParameterNames returns the SAPI names of the parameters in a
+// SolverParameters whose values differ from SAPI's defaults.
+func setParameterNames(sp SolverParameters) []string {
+	var names []string
+	check := func(differs bool, name string) {
+		if differs {
+			names = append(names, name)
+		}
+	}
+	switch sp := sp.(type) {
+	case *SwOptimizeSolverParameters:
+		def := newSwOptimizeSolverParameters()
+		check(sp.AnswerMode != def.AnswerMode, "answer_mode")
+		check(sp.MaxAnswers != def.MaxAnswers, "max_answers")
+		check(sp.NumReads != def.NumReads, "num_reads")
+	case *SwSampleSolverParameters:
+		def := newSwSampleSolverParameters()
+		check(sp.AnswerMode != def.AnswerMode, "answer_mode")
+		check(sp.Beta != def.Beta, "beta")
+		check(sp.MaxAnswers != def.MaxAnswers, "max_answers")
+		check(sp.NumReads != def.NumReads, "num_reads")
+		check(sp.UseRandomSeed, "random_seed")
+	case *SwHeuristicSolverParameters:
+		def := newSwHeuristicSolverParameters()
+		check(sp.IterationLimit != def.IterationLimit, "iteration_limit")
+		check(sp.MinBitFlipProb != def.MinBitFlipProb, "min_bit_flip_prob")
+		check(sp.MaxBitFlipProb != def.MaxBitFlipProb, "max_bit_flip_prob")
+		check(sp.MaxLocalComplexity != def.MaxLocalComplexity, "max_local_complexity")
+		check(sp.LocalStuckLimit != def.LocalStuckLimit, "local_stuck_limit")
+		check(sp.NumPerturbedCopies != def.NumPerturbedCopies, "num_perturbed_copies")
+		check(sp.NumVariables != def.NumVariables, "num_variables")
+		check(sp.UseRandomSeed, "random_seed")
+		check(sp.TimeLimitSeconds != def.TimeLimitSeconds, "time_limit_seconds")
+	case *QuantumSolverParameters:
+		def := newQuantumSolverParameters()
+		check(sp.AnnealingTime != def.AnnealingTime, "annealing_time")
+		check(sp.AnswerMode != def.AnswerMode, "answer_mode")
+		check(sp.AutoScale != def.AutoScale, "auto_scale")
+		check(sp.Beta != def.Beta, "beta")
+		check(len(sp.Chains) > 0, "chains")
+		check(sp.MaxAnswers != def.MaxAnswers, "max_answers")
+		check(sp.NumReads != def.NumReads, "num_reads")
+		check(sp.NumSpinReversals != def.NumSpinReversals, "num_spin_reversal_transforms")
+		check(sp.Postprocess != def.Postprocess, "postprocess")
+		check(sp.ProgTherm != def.ProgTherm, "programming_thermalization")
+		check(sp.ReadoutTherm != def.ReadoutTherm, "readout_thermalization")
+		check(sp.ReduceCorrelation != def.ReduceCorrelation, "reduce_intersample_correlation")
+		check(len(sp.AnnealOffsets) > 0, "anneal_offsets")
+		check(len(sp.FluxBiases) > 0, "flux_biases")
+		check(len(sp.AnnealSchedule) > 0, "anneal_schedule")
+	}
+	return names
+}
+
+// CheckParameters ensures that a set of solver parameters is of the type a
+// solver expects, that every parameter changed from its default is one the
+// solver advertises in its properties, and that the number of reads lies
+// within the range the solver advertises, if any.  Without this check, unsupported settings may be
+// silently ignored by the server.  All failures are reported together as a
+// ValidationError.
+func (s *Solver) CheckParameters(sp SolverParameters) error {
+	errs := s.checkParameters(sp, s.Properties())
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkParameters is the common code for CheckParameters and
+// ValidateProblem.
+func (s *Solver) checkParameters(sp SolverParameters, props *SolverProperties) ValidationError {
+	var errs ValidationError
+	want := s.parametersTypeName()
+	if got := parametersTypeName(sp); got != want {
+		errs = append(errs, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Solver %s expects %s parameters but was given %s parameters", s.Name, want, got))
+		return errs
+	}
+	if nr, lim := numReads(sp), props.Limits().MaxReads; lim > 0 && nr > lim {
+		errs = append(errs, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "%d reads exceeds the limit of %d", nr, lim))
+	}
+	if len(props.Parameters) > 0 {
+		accepted := make(map[string]bool, len(props.Parameters))
+		for _, nm := range props.Parameters {
+			accepted[nm] = true
+		}
+		for _, nm := range setParameterNames(sp) {
+			if !accepted[nm] {
+				errs = append(errs, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Solver %s does not accept the %q parameter", s.Name, nm))
+			}
+		}
+	}
+	return errs
+}
+
+// validateParameters is the common code for each SolverParameters type's
+// Validate method.  It performs the checks of CheckParameters followed by
+// any type-specific checks supplied by the caller.
+func validateParameters(s *Solver, sp SolverParameters, check func(props *SolverProperties, addErr func(string, ...interface{}))) error {
+	props := s.Properties()
+	errs := s.checkParameters(sp, props)
+	if parametersTypeName(sp) == s.parametersTypeName() {
+		check(props, func(format string, a ...interface{}) {
+			errs = append(errs, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, format, a...))
+		})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkCommon checks the values of the parameters common to the quantum and
+// software samplers.
+func checkCommon(addErr func(string, ...interface{}), am SolverParameterAnswerMode, maxAnswers, numReads int) {
+	if am != AnswerModeHistogram && am != AnswerModeRaw {
+		addErr("Answer mode %d is neither AnswerModeHistogram nor AnswerModeRaw", am)
+	}
+	if numReads < 0 {
+		addErr("The number of reads must not be negative (%d)", numReads)
+	}
+	if maxAnswers < 0 {
+		addErr("The maximum number of answers must not be negative (%d)", maxAnswers)
+	}
+}
+
+// Validate checks that a SwOptimizeSolverParameters is acceptable to a
+// solver.  In addition to the checks performed by CheckParameters, it checks
+// the answer mode and that the numbers of reads and answers are not
+// negative.
+func (p *SwOptimizeSolverParameters) Validate(s *Solver) error {
+	return validateParameters(s, p, func(props *SolverProperties, addErr func(string, ...interface{})) {
+		checkCommon(addErr, p.AnswerMode, p.MaxAnswers, p.NumReads)
+	})
+}
+
+// Validate checks that a SwSampleSolverParameters is acceptable to a solver.
+// In addition to the checks performed by CheckParameters, it checks the
+// answer mode and that the numbers of reads and answers are not negative.
+func (p *SwSampleSolverParameters) Validate(s *Solver) error {
+	return validateParameters(s, p, func(props *SolverProperties, addErr func(string, ...interface{})) {
+		checkCommon(addErr, p.AnswerMode, p.MaxAnswers, p.NumReads)
+	})
+}
+
+// Validate checks that a SwHeuristicSolverParameters is acceptable to a
+// solver.  In addition to the checks performed by CheckParameters, it checks
+// that the bit-flip probabilities form a valid range.
+func (p *SwHeuristicSolverParameters) Validate(s *Solver) error {
+	return validateParameters(s, p, func(props *SolverProperties, addErr func(string, ...interface{})) {
+		if p.MinBitFlipProb < 0 || p.MaxBitFlipProb > 1 || p.MinBitFlipProb > p.MaxBitFlipProb {
+			addErr("Bit-flip probabilities [%v, %v] do not form a range within [0, 1]", p.MinBitFlipProb, p.MaxBitFlipProb)
+		}
+	})
+}
+
+// Validate checks that a QuantumSolverParameters is acceptable to a solver.
+// In addition to the checks performed by CheckParameters, it checks the
+// answer mode, that the numbers of reads and answers are not negative, that
+// the annealing time lies within the solver's range, that the solver supports
+// the requested postprocessing, and that any anneal offsets, anneal schedule,
+// and flux-bias offsets pass the checks of their respective setters.
+func (p *QuantumSolverParameters) Validate(s *Solver) error {
+	return validateParameters(s, p, func(props *SolverProperties, addErr func(string, ...interface{})) {
+		checkCommon(addErr, p.AnswerMode, p.MaxAnswers, p.NumReads)
+
+		// Check the annealing time.
+		if asp := props.AnnealSchedule; asp != nil {
+			at := float64(p.AnnealingTime)
+			if (asp.MinAnnealingTime > 0 && at < asp.MinAnnealingTime) || (asp.MaxAnnealingTime > 0 && at > asp.MaxAnnealingTime) {
+				addErr("Annealing time %d µs lies outside the range [%v, %v]", p.AnnealingTime, asp.MinAnnealingTime, asp.MaxAnnealingTime)
+			}
+		}
+
+		// Check the postprocessing type.
+		if p.Postprocess != PostprocessNone && len(props.SupportedPostprocessing) > 0 {
+			found := false
+			for _, pp := range props.SupportedPostprocessing {
+				found = found || pp == p.Postprocess
+			}
+			if !found {
+				addErr("Solver %s does not support %s postprocessing", s.Name, p.Postprocess)
+			}
+		}
+
+		// Re-apply the setters that validate against solver properties.
+		c := p.Clone().(*QuantumSolverParameters)
+		c.props = props
+		for _, err := range []error{
+			c.SetAnnealOffsets(p.AnnealOffsets),
+			c.SetAnnealSchedule(p.AnnealSchedule),
+			c.SetFluxBiases(p.FluxBiases),
+		} {
+			if err != nil {
+				addErr("%s", err.Error())
+			}
+		}
+	})
+}
+
+// adjacencySets returns the set of qubits and the set of couplers that
+// appear in an adjacency list.  Each coupler is included in both directions.
+func adjacencySets(adj Problem) (map[int]bool, map[[2]int]bool) {
+	qubits := make(map[int]bool)
+	couplers := make(map[[2]int]bool, 2*len(adj))
+	for _, pe := range adj {
+		qubits[pe.I] = true
+		qubits[pe.J] = true
+		if pe.I != pe.J {
+			couplers[[2]int{pe.I, pe.J}] = true
+			couplers[[2]int{pe.J, pe.I}] = true
+		}
+	}
+	return qubits, couplers
+}
+
+// ValidFor checks that a problem can be submitted as is to hardware with a
+// given adjacency, such as that returned by HardwareAdjacency.  The working
+// qubits are taken to be those that appear in adj; include a linear entry in
+// adj for each working qubit that has no working couplers.  ValidFor reports
+// every variable that is not a working qubit and every quadratic term that
+// does not correspond to a coupler, all together as a ValidationError.
+func (p Problem) ValidFor(adj Problem) error {
+	var errs ValidationError
+	qubits, couplers := adjacencySets(adj)
+	reported := make(map[int]bool)
+	checkQubit := func(q int) {
+		if !qubits[q] && !reported[q] {
+			errs = append(errs, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Variable %d is not a working qubit", q))
+			reported[q] = true
+		}
+	}
+	for _, pe := range p {
+		checkQubit(pe.I)
+		checkQubit(pe.J)
+		if pe.I != pe.J && !couplers[[2]int{pe.I, pe.J}] {
+			errs = append(errs, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "%s does not correspond to a working coupler", pe))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateProblem checks that a problem and set of solver parameters are
+// acceptable to a solver without submitting them.  Specifically, it checks
+// that every qubit and coupler in the problem exists in the solver's
+// hardware graph, that all coefficients lie within the solver's Ising ranges
+// (unless the parameters enable auto-scaling), and that the parameters pass
+// CheckParameters.  All failures are reported together as a ValidationError.
+func (s *Solver) ValidateProblem(p Problem, sp SolverParameters) error {
+	var errs ValidationError
+	addErr := func(format string, a ...interface{}) {
+		errs = append(errs, newErrorf(C.SAPI_ERR_INVALID_PARAMETER, format, a...))
+	}
+
+	// Acquire the solver's topology and properties.
+	adj, err := s.HardwareAdjacency()
+	if err != nil {
+		return err
+	}
+	props := s.Properties()
+	qubits, couplers := adjacencySets(adj)
+	if props.QuantumProps != nil && len(props.QuantumProps.Qubits) > 0 {
+		qubits = make(map[int]bool, len(props.QuantumProps.Qubits))
+		for _, q := range props.QuantumProps.Qubits {
+			qubits[q] = true
+		}
+	}
+
+	// Check the problem's topology and coefficients.
+	qsp, isQuantum := sp.(*QuantumSolverParameters)
+	ranges := props.IsingRanges
+	if isQuantum && qsp.AutoScale {
+		ranges = nil
+	}
+	for _, pe := range p {
+		if pe.I == pe.J {
+			if !qubits[pe.I] {
+				addErr("Qubit %d does not exist on solver %s", pe.I, s.Name)
+			}
+			if ranges != nil && (pe.Value < ranges.HMin || pe.Value > ranges.HMax) {
+				addErr("%s lies outside the range [%v, %v]", pe, ranges.HMin, ranges.HMax)
+			}
+		} else {
+			if !couplers[[2]int{pe.I, pe.J}] {
+				addErr("Coupler (%d, %d) does not exist on solver %s", pe.I, pe.J, s.Name)
+			}
+			if ranges != nil && (pe.Value < ranges.JMin || pe.Value > ranges.JMax) {
+				addErr("%s lies outside the range [%v, %v]", pe, ranges.JMin, ranges.JMax)
+			}
+		}
+	}
+
+	// Check the solver parameters.
+	errs = append(errs, s.checkParameters(sp, props)...)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SolverLimits indicate the largest problem a solver can accept.  A zero
+// value indicates that the limit is unknown.
+type SolverLimits struct {
+	MaxVariables int // Maximum number of distinct variables
+	MaxCouplers  int // Maximum number of distinct couplers
+	MaxReads     int // Maximum number of reads per submission
+}
+
+// Limits derives a solver's size limits from its properties.  Variable and
+// coupler limits are known only for solvers with quantum properties, and the
+// reads limit is known only for solvers that advertise a NumReadsRange.
+func (props *SolverProperties) Limits() SolverLimits {
+	var lim SolverLimits
+	if nr := props.NumReadsRange; nr != nil {
+		lim.MaxReads = nr[1]
+	}
+	if qp := props.QuantumProps; qp != nil {
+		lim.MaxVariables = len(qp.Qubits)
+		lim.MaxCouplers = len(qp.Couplers)
+	}
+	return lim
+}
+
+// FitsOn ensures that a problem has no more variables or couplers than a
+// solver can accept.  It lets oversized problems be rejected locally with a
+// clear message rather than by the solver.
+func (p Problem) FitsOn(s *Solver) error {
+	lim := s.Properties().Limits()
+	vars := make(map[int]bool, len(p))
+	couplers := make(map[[2]int]bool, len(p))
+	for _, pe := range p {
+		vars[pe.I] = true
+		vars[pe.J] = true
+		if pe.I < pe.J {
+			couplers[[2]int{pe.I, pe.J}] = true
+		} else if pe.I > pe.J {
+			couplers[[2]int{pe.J, pe.I}] = true
+		}
+	}
+	if lim.MaxVariables > 0 && len(vars) > lim.MaxVariables {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Problem has %d variables but solver %s accepts at most %d", len(vars), s.Name, lim.MaxVariables)
+	}
+	if lim.MaxCouplers > 0 && len(couplers) > lim.MaxCouplers {
+		return newErrorf(C.SAPI_ERR_INVALID_PARAMETER, "Problem has %d couplers but solver %s accepts at most %d", len(couplers), s.Name, lim.MaxCouplers)
+	}
+	return nil
+}
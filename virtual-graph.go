@@ -0,0 +1,188 @@
+// This file provides a solver wrapper that presents an embedded logical graph
+// as if it were the hardware graph.
+
+package sapi
+
+import (
+	"sort"
+)
+
+// A VirtualGraphSolver wraps a quantum solver with a fixed embedding so that
+// callers can submit problems expressed in terms of logical variables as
+// though the logical graph were the hardware graph.  Each chain of physical
+// qubits can additionally be calibrated, using Calibrate, with flux-bias
+// offsets that cancel the chain's measured bias.
+type VirtualGraphSolver struct {
+	Solver        *Solver              // Underlying quantum solver
+	Embedding     Embeddings           // Mapping from physical qubits to logical variables
+	ChainStrength float64              // J value applied to couplers within a chain
+	BrokenChains  BrokenChains         // How to resolve chains whose qubits disagree
+	FluxBiases    map[int]float64      // Flux-bias offset to add to each physical qubit (see Calibrate)
+	adj           Problem              // Hardware adjacency
+	ranges        IsingRangeProperties // Coefficient ranges for the embedded problem
+}
+
+// NewVirtualGraphSolver wraps a solver with a fixed embedding.  The chain
+// strength defaults to the most negative J value the solver accepts, using
+// the extended J range if the solver provides one.
+func NewVirtualGraphSolver(s *Solver, emb Embeddings) (*VirtualGraphSolver, error) {
+	// Acquire the solver's topology and coefficient ranges.
+	adj, err := s.HardwareAdjacency()
+	if err != nil {
+		return nil, err
+	}
+	props := s.Properties()
+	ranges := IsingRangeProperties{HMin: -1, HMax: 1, JMin: -1, JMax: 1}
+	if props.IsingRanges != nil {
+		ranges = *props.IsingRanges
+	}
+	chain := ranges.JMin
+	if props.VirtualGraph != nil {
+		chain = props.VirtualGraph.ExtendedJMin
+		ranges.JMin = props.VirtualGraph.ExtendedJMin
+		ranges.JMax = props.VirtualGraph.ExtendedJMax
+	}
+
+	// Ensure that every chain is connected in the hardware graph.
+	vg := &VirtualGraphSolver{
+		Solver:        s,
+		Embedding:     emb,
+		ChainStrength: chain,
+		BrokenChains:  BrokenChainsMinimizeEnergy,
+		adj:           adj,
+		ranges:        ranges,
+	}
+	if err = checkChains(emb, adj); err != nil {
+		return nil, err
+	}
+	return vg, nil
+}
+
+// Adjacency returns the logical graph presented by a VirtualGraphSolver in
+// the same format as Solver.HardwareAdjacency: two logical variables are
+// adjacent if any qubit in one's chain is coupled to any qubit in the other's.
+func (vg *VirtualGraphSolver) Adjacency() Problem {
+	emb := vg.Embedding
+	seen := make(map[[2]int]bool)
+	adj := make(Problem, 0, len(vg.adj))
+	for _, pe := range vg.adj {
+		if pe.I >= len(emb) || pe.J >= len(emb) {
+			continue
+		}
+		u, v := emb[pe.I], emb[pe.J]
+		if u < 0 || v < 0 || u == v || seen[[2]int{u, v}] {
+			continue
+		}
+		seen[[2]int{u, v}] = true
+		seen[[2]int{v, u}] = true
+		adj = append(adj, ProblemEntry{I: u, J: v, Value: 1})
+		adj = append(adj, ProblemEntry{I: v, J: u, Value: 1})
+	}
+	return adj
+}
+
+// withFluxBiases returns a copy of a set of solver parameters with the
+// VirtualGraphSolver's flux-bias offsets added to any the parameters already
+// specify.
+func (vg *VirtualGraphSolver) withFluxBiases(sp SolverParameters) SolverParameters {
+	qsp, ok := sp.(*QuantumSolverParameters)
+	if !ok || len(vg.FluxBiases) == 0 {
+		return sp
+	}
+	nq := len(qsp.FluxBiases)
+	if qp := vg.Solver.Properties().QuantumProps; qp != nil && qp.NumQubits > nq {
+		nq = qp.NumQubits
+	}
+	fb := make([]float64, nq)
+	copy(fb, qsp.FluxBiases)
+	for q, f := range vg.FluxBiases {
+		if q >= 0 && q < nq {
+			fb[q] += f
+		}
+	}
+	q := *qsp
+	q.FluxBiases = fb
+	return &q
+}
+
+// Calibrate measures the residual bias of each chain and adjusts FluxBiases
+// to cancel it.  It characterizes every qubit of every chain in a single
+// pair of submissions (see Characterize), with the current flux-bias offsets
+// applied, and averages the estimated biases of each chain's qubits.  It
+// then adds to every qubit in the chain the flux-bias offset that cancels
+// that average; scale converts from units of h to units of Φ0, as in
+// CorrectionProfile.FluxBiases.  Because each measurement includes the
+// offsets already in place, calling Calibrate repeatedly refines them.
+// Calibrate returns the profile it measured.
+func (vg *VirtualGraphSolver) Calibrate(probeH, scale float64, sp SolverParameters) (*CorrectionProfile, error) {
+	// Characterize every qubit that belongs to a chain.
+	chains := vg.Embedding.Chains()
+	var qubits []int
+	for _, ch := range chains {
+		qubits = append(qubits, ch...)
+	}
+	sort.Ints(qubits)
+	cp, err := Characterize(vg.Solver, qubits, probeH, vg.withFluxBiases(sp))
+	if err != nil {
+		return nil, err
+	}
+
+	// Offset every qubit in each chain by the same amount, chosen to cancel
+	// the chain's mean bias.
+	fb := make(map[int]float64, len(qubits))
+	for q, f := range vg.FluxBiases {
+		fb[q] = f
+	}
+	for _, ch := range chains {
+		mean := 0.0
+		for _, q := range ch {
+			mean += cp.Bias[q]
+		}
+		mean /= float64(len(ch))
+		for _, q := range ch {
+			fb[q] -= mean * scale
+		}
+	}
+	vg.FluxBiases = fb
+	return cp, nil
+}
+
+// SolveIsing solves an Ising-model problem expressed in terms of logical
+// variables.
+func (vg *VirtualGraphSolver) SolveIsing(p Problem, sp SolverParameters) (IsingResult, error) {
+	return solveEmbeddedIsing(vg.Solver, p, vg.Embedding, vg.adj, vg.ranges,
+		vg.ChainStrength, vg.BrokenChains, vg.withFluxBiases(sp))
+}
+
+// SolveQubo solves a QUBO problem expressed in terms of logical variables.
+func (vg *VirtualGraphSolver) SolveQubo(p Problem, sp SolverParameters) (IsingResult, error) {
+	return solveEmbeddedQubo(vg.Solver, p, vg.Embedding, vg.adj, vg.ranges,
+		vg.ChainStrength, vg.BrokenChains, vg.withFluxBiases(sp))
+}
+
+// NewSolverParameters returns a set of parameters appropriate for the
+// underlying solver.
+func (vg *VirtualGraphSolver) NewSolverParameters() SolverParameters {
+	return vg.Solver.NewSolverParameters()
+}
+
+// Properties returns the underlying solver's properties but with
+// QuantumProps describing the logical graph rather than the hardware graph.
+func (vg *VirtualGraphSolver) Properties() *SolverProperties {
+	props := *vg.Solver.Properties()
+	qp := &QuantumSolverProperties{}
+	for v := range vg.Embedding.Chains() {
+		qp.Qubits = append(qp.Qubits, v)
+		if v+1 > qp.NumQubits {
+			qp.NumQubits = v + 1
+		}
+	}
+	sort.Ints(qp.Qubits)
+	for _, pe := range vg.Adjacency() {
+		if pe.I < pe.J {
+			qp.Couplers = append(qp.Couplers, [2]int{pe.I, pe.J})
+		}
+	}
+	props.QuantumProps = qp
+	return &props
+}
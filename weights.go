@@ -0,0 +1,92 @@
+// This file provides functions for weighting and reweighting the samples in
+// an IsingResult.
+
+package sapi
+
+import (
+	"math"
+)
+
+// Weights returns the empirical probability of each solution in an
+// IsingResult, based on its Occurrences.  If Occurrences is nil (as in raw
+// answer mode), each solution is weighted equally.
+func (ir IsingResult) Weights() []float64 {
+	n := len(ir.Solutions)
+	w := make([]float64, n)
+	if n == 0 {
+		return w
+	}
+	if ir.Occurrences == nil {
+		for i := range w {
+			w[i] = 1.0 / float64(n)
+		}
+		return w
+	}
+	tot := 0
+	for _, o := range ir.Occurrences {
+		tot += o
+	}
+	for i, o := range ir.Occurrences {
+		w[i] = float64(o) / float64(tot)
+	}
+	return w
+}
+
+// ReweightBoltzmann computes importance weights that transform the samples in
+// an IsingResult, assumed to be drawn from a Boltzmann distribution at inverse
+// temperature betaSource, into samples from a Boltzmann distribution at
+// inverse temperature betaTarget.  Each solution's weight is proportional to
+// its number of occurrences times exp(-(betaTarget - betaSource)·E).  The
+// returned weights sum to 1.  Pass betaSource = 0 to treat the samples as
+// having been drawn uniformly.
+func (ir IsingResult) ReweightBoltzmann(betaSource, betaTarget float64) []float64 {
+	// Compute log weights, retaining the largest for numerical stability.
+	emp := ir.Weights()
+	logW := make([]float64, len(emp))
+	maxLogW := math.Inf(-1)
+	dBeta := betaTarget - betaSource
+	for i, e := range ir.Energies {
+		logW[i] = math.Log(emp[i]) - dBeta*e
+		if logW[i] > maxLogW {
+			maxLogW = logW[i]
+		}
+	}
+
+	// Exponentiate and normalize the weights.
+	w := make([]float64, len(logW))
+	tot := 0.0
+	for i, lw := range logW {
+		w[i] = math.Exp(lw - maxLogW)
+		tot += w[i]
+	}
+	for i := range w {
+		w[i] /= tot
+	}
+	return w
+}
+
+// EffectiveSampleSize returns the number of independent, equally weighted
+// samples to which a set of normalized importance weights is equivalent.
+// Values much smaller than the number of samples indicate that a reweighting
+// is dominated by a few samples and hence is unreliable.
+func EffectiveSampleSize(w []float64) float64 {
+	ss := 0.0
+	for _, x := range w {
+		ss += x * x
+	}
+	if ss == 0 {
+		return 0
+	}
+	return 1 / ss
+}
+
+// WeightedMean returns the weighted average of a per-solution quantity, such
+// as an energy or a magnetization, given weights as returned by Weights or
+// ReweightBoltzmann.
+func WeightedMean(w, x []float64) float64 {
+	m := 0.0
+	for i, wi := range w {
+		m += wi * x[i]
+	}
+	return m
+}